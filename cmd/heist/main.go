@@ -6,22 +6,43 @@ import (
 	"syscall"
 
 	"github.com/joho/godotenv"
+	"github.com/rbrabson/heist/pkg/config"
 	"github.com/rbrabson/heist/pkg/discord"
+	"github.com/rbrabson/heist/pkg/logging"
+	"github.com/rbrabson/heist/pkg/store"
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	// Version and Revision are set when the bot is built.
+	Version  = "dev"
+	Revision = "unknown"
+)
+
 func main() {
 	godotenv.Load()
-	log.SetLevel(log.DebugLevel)
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatal(err)
+	}
+	err = logging.Apply(&cfg.Logging)
+	if err != nil {
+		log.Fatal("Unable to configure logging, error:", err)
+	}
+	err = store.Init(cfg.Store)
+	if err != nil {
+		log.Fatal("Unable to initialize the store, error:", err)
+	}
 
-	bot := discord.NewBot()
-	err := bot.Session.Open()
+	discord.Version = Version
+	discord.Revision = Revision
+	bot := discord.NewBot(cfg)
+	err = bot.Open()
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer bot.Session.Close()
+	defer bot.Close()
 
-	// log.SetLevel(log.DebugLevel)
 	sc := make(chan os.Signal, 1)
 	signal.Notify(sc, syscall.SIGINT, syscall.SIGTERM, os.Interrupt, os.Kill)
 	log.Info("Press Ctrl+C to exit")
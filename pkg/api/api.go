@@ -0,0 +1,277 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/cogs/heist"
+	"github.com/rbrabson/heist/pkg/cogs/payday"
+	"github.com/rbrabson/heist/pkg/cogs/race"
+	"github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/shard"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultLimit = 10
+	maxLimit     = 100
+
+	readTimeout     = 10 * time.Second
+	writeTimeout    = 10 * time.Second
+	shutdownTimeout = 10 * time.Second
+)
+
+var (
+	server *http.Server
+	routes = make(map[string]http.Handler)
+)
+
+// Handle registers a handler that is served by the API's web server, alongside the API. Handlers must be
+// registered before the API is started.
+func Handle(pattern string, handler http.Handler) {
+	routes[pattern] = handler
+}
+
+// Start starts serving the API, if it is enabled in the configuration. The API is served in the
+// background until Stop is called.
+func Start(cfg config.API) {
+	log.Trace("--> api.Start")
+	defer log.Trace("<-- api.Start")
+
+	if !cfg.Enabled {
+		return
+	}
+	loadTokens()
+
+	server = &http.Server{
+		Addr:         cfg.Address,
+		Handler:      newHandler(),
+		ReadTimeout:  readTimeout,
+		WriteTimeout: writeTimeout,
+	}
+	go func() {
+		err := server.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Error("The API server failed, error:", err)
+		}
+	}()
+	log.WithField("Address", cfg.Address).Info("API server started")
+}
+
+// Stop stops serving the API, waiting for requests in progress to finish.
+func Stop() {
+	log.Trace("--> api.Stop")
+	defer log.Trace("<-- api.Stop")
+
+	if server == nil {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if err != nil {
+		log.Warning("Unable to stop the API server, error:", err)
+		return
+	}
+	log.Info("API server stopped")
+}
+
+// newHandler returns the handler that serves the API routes.
+func newHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/bank/leaderboard/{period}", authorized(bankLeaderboardHandler))
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/heist/players/{memberID}", authorized(heistStatsHandler))
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/heist/targets", authorized(heistTargetsHandler))
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/heist/history", authorized(heistHistoryHandler))
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/heist/leaderboard/{board}", authorized(heistLeaderboardHandler))
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/race/players/{memberID}", authorized(raceStatsHandler))
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/race/leaderboard", authorized(raceLeaderboardHandler))
+	mux.HandleFunc("GET /api/v1/guilds/{guildID}/config", authorized(guildConfigHandler))
+	for pattern, handler := range routes {
+		mux.Handle(pattern, handler)
+	}
+	return mux
+}
+
+// authorized returns a handler that only calls the given handler if the request has a valid bearer token
+// for the guild in the request path, and the guild is handled by this instance of the bot.
+func authorized(handler func(w http.ResponseWriter, r *http.Request, guildID string)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildID := r.PathValue("guildID")
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || !validToken(guildID, strings.TrimSpace(token)) {
+			writeError(w, http.StatusUnauthorized, ErrUnauthorized)
+			return
+		}
+		if !shard.Owns(guildID) {
+			writeError(w, http.StatusNotFound, ErrNotFound)
+			return
+		}
+		log.WithFields(log.Fields{"Guild": guildID, "Path": r.URL.Path}).Debug("API request")
+		handler(w, r, guildID)
+	}
+}
+
+// bankLeaderboardHandler returns the monthly, current or lifetime bank leaderboard for the guild.
+func bankLeaderboardHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	limit, err := getLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	period := r.PathValue("period")
+	var accounts []*economy.LeaderboardAccount
+	switch period {
+	case "monthly":
+		accounts = economy.GetMonthlyLeaderboard(guildID, limit)
+	case "current":
+		accounts = economy.GetCurrentLeaderboard(guildID, limit)
+	case "lifetime":
+		accounts = economy.GetLifetimeLeaderboard(guildID, limit)
+	default:
+		writeError(w, http.StatusBadRequest, ErrInvalidPeriod)
+		return
+	}
+
+	writeJSON(w, &bankLeaderboard{
+		GuildID:  guildID,
+		Period:   period,
+		Accounts: accounts,
+	})
+}
+
+// heistStatsHandler returns the heist stats for a member of the guild.
+func heistStatsHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	player, ok := heist.GetPlayerStats(guildID, r.PathValue("memberID"))
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	writeJSON(w, newHeistStats(guildID, player))
+}
+
+// heistHistoryHandler returns the most recent heists for the guild, optionally only those a member took part in.
+func heistHistoryHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	limit, err := getLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, &heistHistory{
+		GuildID: guildID,
+		Heists:  heist.GetHistory(guildID, r.URL.Query().Get("member"), limit),
+	})
+}
+
+// heistLeaderboardHandler returns a monthly or lifetime heist leaderboard for the guild.
+func heistLeaderboardHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	limit, err := getLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	period := r.URL.Query().Get("period")
+	if period == "" {
+		period = heist.PeriodMonthly
+	}
+	board := r.PathValue("board")
+	players, err := heist.GetLeaderboard(guildID, board, period, limit)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, &heistLeaderboard{
+		GuildID: guildID,
+		Board:   board,
+		Period:  period,
+		Players: players,
+	})
+}
+
+// heistTargetsHandler returns the heist targets for the guild, including how much is in each vault.
+func heistTargetsHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	targets := heist.GetServerTargets(guildID)
+	vaults := make([]*vault, 0, len(targets))
+	for _, target := range targets {
+		vaults = append(vaults, &vault{
+			Target:   target.ID,
+			CrewSize: target.CrewSize,
+			Success:  target.Success,
+			Vault:    target.Vault,
+			VaultMax: target.VaultMax,
+		})
+	}
+	writeJSON(w, &heistTargets{
+		GuildID: guildID,
+		Targets: vaults,
+	})
+}
+
+// raceStatsHandler returns the race stats for a member of the guild.
+func raceStatsHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	player, ok := race.GetPlayerStats(guildID, r.PathValue("memberID"))
+	if !ok {
+		writeError(w, http.StatusNotFound, ErrNotFound)
+		return
+	}
+	writeJSON(w, newRaceStats(guildID, player))
+}
+
+// raceLeaderboardHandler returns the race leaderboard for the guild.
+func raceLeaderboardHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	limit, err := getLimit(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	writeJSON(w, &raceLeaderboard{
+		GuildID: guildID,
+		Players: race.GetLeaderboard(guildID, limit),
+	})
+}
+
+// guildConfigHandler returns the game configuration for the guild.
+func guildConfigHandler(w http.ResponseWriter, r *http.Request, guildID string) {
+	writeJSON(w, &guildConfig{
+		GuildID: guildID,
+		Heist:   newHeistConfig(heist.GetServerConfig(guildID)),
+		Race:    newRaceConfig(race.GetServerConfig(guildID)),
+		Payday:  payday.GetPaydayAmount(guildID),
+	})
+}
+
+// getLimit returns the number of entries requested using the `limit` query parameter.
+func getLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxLimit {
+		return 0, ErrInvalidLimit
+	}
+	return limit, nil
+}
+
+// writeJSON writes the data as the JSON body of the response.
+func writeJSON(w http.ResponseWriter, data interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	err := json.NewEncoder(w).Encode(data)
+	if err != nil {
+		log.Warning("Unable to write the API response, error:", err)
+	}
+}
+
+// writeError writes the error as the JSON body of the response, with the given status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(&errorResponse{Error: err.Error()})
+}
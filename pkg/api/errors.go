@@ -0,0 +1,11 @@
+package api
+
+import "errors"
+
+var (
+	ErrTokenNotFound = errors.New("no API token has been created for the server")
+	ErrUnauthorized  = errors.New("missing or invalid API token")
+	ErrNotFound      = errors.New("not found")
+	ErrInvalidLimit  = errors.New("limit must be a number from 1 to 100")
+	ErrInvalidPeriod = errors.New("period must be one of monthly, current or lifetime")
+)
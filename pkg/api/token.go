@@ -0,0 +1,114 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"sync"
+	"time"
+
+	"github.com/rbrabson/heist/pkg/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	API = "api"
+
+	tokenLength = 32
+)
+
+var (
+	tokens     = make(map[string]*guildToken)
+	tokenMutex sync.Mutex
+)
+
+// guildToken is the token that grants access to the data for a guild. Only a hash of the token is saved,
+// so the token itself is only ever shown to the admin who created it.
+type guildToken struct {
+	ID        string    `json:"_id" bson:"_id"`               // Guild ID
+	Hash      string    `json:"hash" bson:"hash"`             // SHA-256 hash of the token
+	CreatedAt time.Time `json:"created_at" bson:"created_at"` // Time the token was created
+	CreatedBy string    `json:"created_by" bson:"created_by"` // ID of the member who created the token
+}
+
+// NewToken creates a new API token for the guild, replacing any token that was created before. The
+// token is returned so it may be shown to the member who created it.
+func NewToken(guildID string, memberID string) (string, error) {
+	log.Trace("--> api.NewToken")
+	defer log.Trace("<-- api.NewToken")
+
+	b := make([]byte, tokenLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	token := base64.RawURLEncoding.EncodeToString(b)
+
+	t := &guildToken{
+		ID:        guildID,
+		Hash:      hashToken(token),
+		CreatedAt: time.Now(),
+		CreatedBy: memberID,
+	}
+	tokenMutex.Lock()
+	tokens[guildID] = t
+	tokenMutex.Unlock()
+	store.Store.Save(API, t.ID, t)
+	log.WithFields(log.Fields{"Guild": guildID, "Member": memberID}).Info("Created API token")
+
+	return token, nil
+}
+
+// RevokeToken removes the API token for the guild, so the guild's data can no longer be read
+// using the API.
+func RevokeToken(guildID string) error {
+	log.Trace("--> api.RevokeToken")
+	defer log.Trace("<-- api.RevokeToken")
+
+	tokenMutex.Lock()
+	t, ok := tokens[guildID]
+	if !ok || t.Hash == "" {
+		tokenMutex.Unlock()
+		return ErrTokenNotFound
+	}
+	t.Hash = ""
+	tokenMutex.Unlock()
+	store.Store.Save(API, t.ID, t)
+	log.WithField("Guild", guildID).Info("Revoked API token")
+
+	return nil
+}
+
+// validToken returns `true` if the token grants access to the data for the guild.
+func validToken(guildID string, token string) bool {
+	tokenMutex.Lock()
+	t, ok := tokens[guildID]
+	tokenMutex.Unlock()
+	if !ok || t.Hash == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(t.Hash), []byte(hashToken(token))) == 1
+}
+
+// hashToken returns the hash of the token that is saved in the store.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// loadTokens loads the API tokens from the store.
+func loadTokens() {
+	log.Trace("--> api.loadTokens")
+	defer log.Trace("<-- api.loadTokens")
+
+	tokenMutex.Lock()
+	defer tokenMutex.Unlock()
+
+	for _, guildID := range store.Store.ListDocuments(API) {
+		var t guildToken
+		store.Store.Load(API, guildID, &t)
+		t.ID = guildID
+		tokens[t.ID] = &t
+	}
+}
@@ -0,0 +1,199 @@
+package api
+
+import (
+	"time"
+
+	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/cogs/heist"
+	"github.com/rbrabson/heist/pkg/cogs/race"
+)
+
+// errorResponse is returned when a request fails.
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// bankLeaderboard is a bank leaderboard for a guild.
+type bankLeaderboard struct {
+	GuildID  string                        `json:"guild_id"`
+	Period   string                        `json:"period"`
+	Accounts []*economy.LeaderboardAccount `json:"accounts"`
+}
+
+// heistLeaderboard is a heist leaderboard for a guild.
+type heistLeaderboard struct {
+	GuildID string                    `json:"guild_id"`
+	Board   string                    `json:"board"`
+	Period  string                    `json:"period"`
+	Players []*heist.LeaderboardEntry `json:"players"`
+}
+
+// heistHistory is the most recent heists for a guild.
+type heistHistory struct {
+	GuildID string               `json:"guild_id"`
+	Heists  []*heist.HeistRecord `json:"heists"`
+}
+
+// raceLeaderboard is the race leaderboard for a guild.
+type raceLeaderboard struct {
+	GuildID string                    `json:"guild_id"`
+	Players []race.LeaderboardAccount `json:"players"`
+}
+
+// heistStats are the heist stats for a member of a guild.
+type heistStats struct {
+	GuildID       string     `json:"guild_id"`
+	MemberID      string     `json:"member_id"`
+	Name          string     `json:"name"`
+	Status        string     `json:"status"`
+	CriminalLevel string     `json:"criminal_level"`
+	Level         int        `json:"level"`
+	XP            int64      `json:"xp"`
+	Spree         int64      `json:"spree"`
+	Deaths        int64      `json:"deaths"`
+	JailCounter   int64      `json:"jail_counter"`
+	TotalJail     int64      `json:"total_jail"`
+	BailCost      int64      `json:"bail_cost"`
+	JailedUntil   *time.Time `json:"jailed_until,omitempty"`
+	DeadUntil     *time.Time `json:"dead_until,omitempty"`
+}
+
+// newHeistStats returns the heist stats for the player.
+func newHeistStats(guildID string, player *heist.Player) *heistStats {
+	stats := &heistStats{
+		GuildID:       guildID,
+		MemberID:      player.ID,
+		Name:          player.Name,
+		Status:        player.Status,
+		CriminalLevel: player.CriminalLevel.String(),
+		Level:         int(player.CriminalLevel),
+		XP:            player.XP,
+		Spree:         player.Spree,
+		Deaths:        player.Deaths,
+		JailCounter:   player.JailCounter,
+		TotalJail:     player.TotalJail,
+		BailCost:      player.BailCost,
+	}
+	if player.Status == heist.APPREHENDED {
+		stats.JailedUntil = &player.JailTimer
+	}
+	if player.Status == heist.DEAD {
+		stats.DeadUntil = &player.DeathTimer
+	}
+	return stats
+}
+
+// raceStats are the race stats for a member of a guild.
+type raceStats struct {
+	GuildID     string `json:"guild_id"`
+	MemberID    string `json:"member_id"`
+	Name        string `json:"name"`
+	Races       int    `json:"races"`
+	Wins        int    `json:"wins"`
+	Places      int    `json:"places"`
+	Shows       int    `json:"shows"`
+	Losses      int    `json:"losses"`
+	Earnings    int    `json:"earnings"`
+	BetsPlaced  int    `json:"bets_placed"`
+	BetsWon     int    `json:"bets_won"`
+	BetEarnings int    `json:"bet_earnings"`
+}
+
+// newRaceStats returns the race stats for the player.
+func newRaceStats(guildID string, player *race.Player) *raceStats {
+	return &raceStats{
+		GuildID:     guildID,
+		MemberID:    player.ID,
+		Name:        player.Name,
+		Races:       player.NumRaces,
+		Wins:        player.Results.Win,
+		Places:      player.Results.Place,
+		Shows:       player.Results.Show,
+		Losses:      player.Results.Losses,
+		Earnings:    player.Results.Earnings,
+		BetsPlaced:  player.Results.BetsPlaced,
+		BetsWon:     player.Results.BetsWon,
+		BetEarnings: player.Results.BetEarnings,
+	}
+}
+
+// vault is the current state of the vault for a heist target.
+type vault struct {
+	Target   string  `json:"target"`
+	CrewSize int64   `json:"crew_size"`
+	Success  float64 `json:"success"`
+	Vault    int64   `json:"vault"`
+	VaultMax int64   `json:"vault_max"`
+}
+
+// heistTargets are the heist targets for a guild.
+type heistTargets struct {
+	GuildID string   `json:"guild_id"`
+	Targets []*vault `json:"targets"`
+}
+
+// guildConfig is the configuration of the games for a guild.
+type guildConfig struct {
+	GuildID string       `json:"guild_id"`
+	Heist   *heistConfig `json:"heist"`
+	Race    *raceConfig  `json:"race"`
+	Payday  int64        `json:"payday"`
+}
+
+// heistConfig is the heist configuration for a guild.
+type heistConfig struct {
+	Theme        string `json:"theme"`
+	Targets      string `json:"targets"`
+	Cost         int64  `json:"cost"`
+	BailBase     int64  `json:"bail_base"`
+	SentenceBase string `json:"sentence_base"`
+	DeathTimer   string `json:"death_timer"`
+	PoliceAlert  string `json:"police_alert"`
+	WaitTime     string `json:"wait_time"`
+	Hardcore     bool   `json:"hardcore"`
+}
+
+// newHeistConfig returns the heist configuration for a guild.
+func newHeistConfig(cfg *heist.Config) *heistConfig {
+	return &heistConfig{
+		Theme:        cfg.Theme,
+		Targets:      cfg.Targets,
+		Cost:         cfg.HeistCost,
+		BailBase:     cfg.BailBase,
+		SentenceBase: cfg.SentenceBase.String(),
+		DeathTimer:   cfg.DeathTimer.String(),
+		PoliceAlert:  cfg.PoliceAlert.String(),
+		WaitTime:     cfg.WaitTime.String(),
+		Hardcore:     cfg.Hardcore,
+	}
+}
+
+// raceConfig is the race configuration for a guild.
+type raceConfig struct {
+	Mode             string `json:"mode"`
+	Currency         string `json:"currency"`
+	BetAmount        int    `json:"bet_amount"`
+	PrizeMin         int    `json:"prize_min"`
+	PrizeMax         int    `json:"prize_max"`
+	MinRacers        int    `json:"min_racers"`
+	MaxRacers        int    `json:"max_racers"`
+	WaitForJoin      string `json:"wait_for_join"`
+	WaitForBetting   string `json:"wait_for_betting"`
+	WaitBetweenRaces string `json:"wait_between_races"`
+}
+
+// newRaceConfig returns the race configuration for a guild.
+func newRaceConfig(cfg *race.Config) *raceConfig {
+	return &raceConfig{
+		Mode:             cfg.Mode,
+		Currency:         cfg.Currency,
+		BetAmount:        cfg.BetAmount,
+		PrizeMin:         cfg.PrizeMin,
+		PrizeMax:         cfg.PrizeMax,
+		MinRacers:        cfg.MinRacers,
+		MaxRacers:        cfg.MaxRacers,
+		WaitForJoin:      cfg.WaitForJoin.String(),
+		WaitForBetting:   cfg.WaitForBetting.String(),
+		WaitBetweenRaces: cfg.WaitBetweenRaces.String(),
+	}
+}
@@ -0,0 +1,271 @@
+package audit
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/help"
+	"github.com/rbrabson/heist/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	AUDIT = "audit"
+
+	maxEntries = 1000
+)
+
+var (
+	servers map[string]*server
+	mutex   sync.Mutex
+)
+
+// server is the audit log for a server/guild.
+type server struct {
+	ID        string   `json:"_id" bson:"_id"`               // Guild ID
+	ChannelID string   `json:"channel_id" bson:"channel_id"` // Channel where audit entries are published
+	Entries   []*Entry `json:"entries" bson:"entries"`       // Most recent audit entries, oldest first
+}
+
+// Entry is a single administrative action taken on a server/guild.
+type Entry struct {
+	Time     time.Time `json:"time" bson:"time"`           // Time the action was taken
+	UserID   string    `json:"user_id" bson:"user_id"`     // ID of the member who took the action
+	UserName string    `json:"user_name" bson:"user_name"` // Name of the member who took the action
+	Action   string    `json:"action" bson:"action"`       // The command used, such as `/bank set`
+	Target   string    `json:"target" bson:"target"`       // What the action was taken on, such as a member or setting
+	OldValue string    `json:"old_value" bson:"old_value"` // The value before the action was taken
+	NewValue string    `json:"new_value" bson:"new_value"` // The value after the action was taken
+}
+
+// init initializes the set of audit logs.
+func init() {
+	servers = make(map[string]*server)
+}
+
+// getServer returns the audit log for the server/guild, creating a new one if necessary.
+func getServer(serverID string) *server {
+	s, ok := servers[serverID]
+	if !ok {
+		s = &server{
+			ID:      serverID,
+			Entries: make([]*Entry, 0, 1),
+		}
+		servers[s.ID] = s
+	}
+	return s
+}
+
+// GetServerCount returns the number of audit logs that are cached.
+func GetServerCount() int {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	return len(servers)
+}
+
+// Record adds an entry to the audit log for the server/guild on which the interaction occurred, and
+// publishes the entry to the audit channel if one has been set.
+func Record(s *discordgo.Session, i *discordgo.InteractionCreate, action string, target string, oldValue interface{}, newValue interface{}) {
+	log.Trace("--> Record")
+	defer log.Trace("<-- Record")
+
+	RecordUser(s, i.GuildID, i.Member.User.ID, getMemberName(i.Member.User.Username, i.Member.Nick), action, target, oldValue, newValue)
+}
+
+// RecordUser adds an entry to the audit log for an action taken by the user on the server/guild outside
+// of Discord, such as from the web dashboard, and publishes the entry to the audit channel if one has been set.
+func RecordUser(s *discordgo.Session, guildID string, userID string, userName string, action string, target string, oldValue interface{}, newValue interface{}) {
+	log.Trace("--> RecordUser")
+	defer log.Trace("<-- RecordUser")
+
+	entry := &Entry{
+		Time:     time.Now(),
+		UserID:   userID,
+		UserName: userName,
+		Action:   action,
+		Target:   target,
+		OldValue: formatValue(oldValue),
+		NewValue: formatValue(newValue),
+	}
+
+	mutex.Lock()
+	server := getServer(guildID)
+	server.Entries = append(server.Entries, entry)
+	if len(server.Entries) > maxEntries {
+		server.Entries = server.Entries[len(server.Entries)-maxEntries:]
+	}
+	channelID := server.ChannelID
+	saveServer(server)
+	mutex.Unlock()
+
+	log.WithFields(logrus.Fields{
+		"Guild":  guildID,
+		"User":   entry.UserName,
+		"Action": entry.Action,
+		"Target": entry.Target,
+		"Old":    entry.OldValue,
+		"New":    entry.NewValue,
+	}).Info("Audit")
+
+	if channelID != "" && s != nil {
+		go publishEntry(s, channelID, entry)
+	}
+}
+
+// publishEntry sends the audit entry to the audit channel. It is run in its own goroutine, so a slow or
+// rate-limited channel doesn't delay the response to the command that was recorded.
+func publishEntry(s *discordgo.Session, channelID string, entry *Entry) {
+	_, err := s.ChannelMessageSendEmbed(channelID, formatEntry(entry))
+	if err != nil {
+		log.WithFields(logrus.Fields{"Channel": channelID, "Error": err.Error()}).Error("Failed to publish audit entry")
+	}
+}
+
+// search returns the most recent audit entries for the server/guild, newest first. The entries may
+// be filtered by the member who took the action and by a case-insensitive substring of the action.
+func search(serverID string, userID string, action string, limit int) []*Entry {
+	log.Trace("--> search")
+	defer log.Trace("<-- search")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	server := getServer(serverID)
+	action = strings.ToLower(action)
+	results := make([]*Entry, 0, limit)
+	for index := len(server.Entries) - 1; index >= 0 && len(results) < limit; index-- {
+		entry := server.Entries[index]
+		if userID != "" && entry.UserID != userID {
+			continue
+		}
+		if action != "" && !strings.Contains(strings.ToLower(entry.Action), action) {
+			continue
+		}
+		results = append(results, entry)
+	}
+	return results
+}
+
+// setChannel sets the channel to which audit entries are published, and returns the previous channel.
+func setChannel(serverID string, channelID string) string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	server := getServer(serverID)
+	oldChannelID := server.ChannelID
+	server.ChannelID = channelID
+	saveServer(server)
+
+	return oldChannelID
+}
+
+// formatEntry formats an audit entry to be sent to a Discord channel.
+func formatEntry(entry *Entry) *discordgo.MessageEmbed {
+	return &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       entry.Action,
+		Description: fmt.Sprintf("%s (`%s`)", entry.UserName, entry.UserID),
+		Timestamp:   entry.Time.Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   "Target",
+				Value:  valueOrNone(entry.Target),
+				Inline: false,
+			},
+			{
+				Name:   "Old Value",
+				Value:  valueOrNone(entry.OldValue),
+				Inline: true,
+			},
+			{
+				Name:   "New Value",
+				Value:  valueOrNone(entry.NewValue),
+				Inline: true,
+			},
+		},
+	}
+}
+
+// formatValue converts a value recorded in the audit log to a string.
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case fmt.Stringer:
+		return v.String()
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+// valueOrNone returns the value, or "None" if the value is empty.
+func valueOrNone(value string) string {
+	if value == "" {
+		return "None"
+	}
+	return value
+}
+
+// getMemberName returns the member's nickname, if there is one, or the username otherwise.
+func getMemberName(username string, nickname string) string {
+	if nickname != "" {
+		return nickname
+	}
+	return username
+}
+
+// loadServers loads the audit logs for all servers from the store.
+func loadServers() {
+	log.Trace("--> loadServers")
+	defer log.Trace("<-- loadServers")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	servers = make(map[string]*server)
+	serverIDs := store.Store.ListDocuments(AUDIT)
+	for _, serverID := range serverIDs {
+		var server server
+		store.Store.Load(AUDIT, serverID, &server)
+		servers[server.ID] = &server
+	}
+}
+
+// saveServer saves the audit log for the server into the store.
+func saveServer(server *server) {
+	log.Trace("--> saveServer")
+	defer log.Trace("<-- saveServer")
+
+	store.Store.Save(AUDIT, server.ID, server)
+}
+
+// GetHelp returns help information about the audit commands.
+func GetHelp() *help.Topic {
+	return &help.Topic{
+		Name:        "Audit",
+		Description: "Review the administrative actions taken on the server.",
+		Commands:    commands,
+		Details: map[string]*help.Detail{
+			"audit channel": {
+				Examples: []string{"/audit channel id:123456789012345678"},
+			},
+			"audit search": {
+				Defaults: map[string]string{"id": "all members", "action": "all actions"},
+				Examples: []string{"/audit search", "/audit search action:bank set limit:25"},
+			},
+		},
+	}
+}
+
+// String returns a string representation of the audit entry.
+func (e *Entry) String() string {
+	out, _ := json.Marshal(e)
+	return string(out)
+}
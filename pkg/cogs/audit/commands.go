@@ -0,0 +1,178 @@
+package audit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/logging"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+)
+
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 25
+)
+
+var (
+	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"audit": auditRouter,
+	}
+
+	commands = []*discordgo.ApplicationCommand{
+		{
+			Name:                     "audit",
+			Description:              "Commands used to review administrative actions on this server.",
+			DefaultMemberPermissions: &permission.AdminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "channel",
+					Description: "Sets the channel ID where audit entries are published.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "The channel ID.",
+							Required:    true,
+						},
+					},
+				},
+				{
+					Name:        "search",
+					Description: "Searches the recent audit entries.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of the member who took the action.",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "action",
+							Description: "Part of the command used, such as `bank set`.",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "limit",
+							Description: "The number of entries to return. Defaults to 10.",
+							Required:    false,
+						},
+					},
+				},
+			},
+		},
+	}
+)
+
+// auditRouter routes the audit commands to the proper handlers.
+func auditRouter(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> auditRouter")
+	defer log.Trace("<-- auditRouter")
+
+	options := i.ApplicationCommandData().Options
+	switch options[0].Name {
+	case "channel":
+		setAuditChannel(s, i)
+	case "search":
+		searchAudit(s, i)
+	}
+}
+
+// setAuditChannel sets the channel where audit entries are published.
+func setAuditChannel(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> setAuditChannel")
+	defer log.Trace("<-- setAuditChannel")
+
+	channelID := strings.TrimSpace(i.ApplicationCommandData().Options[0].Options[0].StringValue())
+	oldChannelID := setChannel(i.GuildID, channelID)
+	Record(s, i, "/audit channel", "Audit channel", oldChannelID, channelID)
+
+	msg.SendResponse(s, i, "Channel ID for audit entries set to "+channelID+".")
+}
+
+// searchAudit returns the recent audit entries that match the search criteria.
+func searchAudit(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> searchAudit")
+	defer log.Trace("<-- searchAudit")
+
+	var userID, action string
+	limit := defaultSearchLimit
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "id":
+			userID = strings.TrimSpace(option.StringValue())
+		case "action":
+			action = strings.TrimSpace(option.StringValue())
+		case "limit":
+			limit = int(option.IntValue())
+		}
+	}
+	if limit < 1 || limit > maxSearchLimit {
+		msg.SendEphemeralResponse(s, i, fmt.Sprintf("The limit must be between 1 and %d.", maxSearchLimit))
+		return
+	}
+
+	entries := search(i.GuildID, userID, action, limit)
+	if len(entries) == 0 {
+		msg.SendEphemeralResponse(s, i, "No audit entries were found.")
+		return
+	}
+
+	fields := make([]*discordgo.MessageEmbedField, 0, len(entries))
+	for _, entry := range entries {
+		var value strings.Builder
+		value.WriteString(fmt.Sprintf("<t:%d:f> by %s\n", entry.Time.Unix(), entry.UserName))
+		if entry.Target != "" {
+			value.WriteString(fmt.Sprintf("**Target**: %s\n", entry.Target))
+		}
+		if entry.OldValue != "" || entry.NewValue != "" {
+			value.WriteString(fmt.Sprintf("`%s` → `%s`", valueOrNone(entry.OldValue), valueOrNone(entry.NewValue)))
+		}
+		fields = append(fields, &discordgo.MessageEmbedField{
+			Name:  entry.Action,
+			Value: truncate(value.String(), 1024),
+		})
+	}
+	embeds := []*discordgo.MessageEmbed{
+		{
+			Type:   discordgo.EmbedTypeRich,
+			Title:  "Audit Log",
+			Fields: fields,
+		},
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: embeds,
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logging.WithInteraction(log, i).Error("Unable to send the audit entries to Discord, error:", err)
+	}
+}
+
+// truncate shortens the string to at most `n` characters.
+func truncate(str string, n int) string {
+	r := []rune(str)
+	if len(r) <= n {
+		return str
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// Start loads the audit logs for all servers.
+func Start(s *discordgo.Session) {
+	loadServers()
+}
+
+// GetCommands returns the component handlers, command handlers, and commands for the audit log.
+func GetCommands() (map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), []*discordgo.ApplicationCommand) {
+	return nil, commandHandlers, commands
+}
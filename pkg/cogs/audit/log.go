@@ -0,0 +1,6 @@
+package audit
+
+import "github.com/rbrabson/heist/pkg/logging"
+
+// log is the logger used by the audit cog.
+var log = logging.Logger("audit")
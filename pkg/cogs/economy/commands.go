@@ -4,28 +4,35 @@ import (
 	"strings"
 
 	"github.com/bwmarrin/discordgo"
-	"github.com/joho/godotenv"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
 	"github.com/rbrabson/heist/pkg/msg"
-	log "github.com/sirupsen/logrus"
-)
-
-var (
-	session *discordgo.Session
+	"github.com/rbrabson/heist/pkg/permission"
+	"github.com/rbrabson/heist/pkg/scheduler"
+	"github.com/sirupsen/logrus"
 )
 
 var (
 	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"account":     bankAccount,
-		"balance":     getAccountInfo,
-		"bank":        bank,
-		"leaderboard": leaderboard,
-		"lifetime":    lifetime,
+		"account":      bankAccount,
+		"balance":      getAccountInfo,
+		"bank":         bank,
+		"leaderboard":  leaderboard,
+		"lifetime":     lifetime,
+		"Bank balance": bankAccountForMember,
+	}
+
+	userCommands = []*discordgo.ApplicationCommand{
+		{
+			Name: "Bank balance",
+			Type: discordgo.UserApplicationCommand,
+		},
 	}
 
 	adminCommands = []*discordgo.ApplicationCommand{
 		{
-			Name:        "bank",
-			Description: "Commands used to interact with the economy for this server.",
+			Name:                     "bank",
+			Description:              "Commands used to interact with the economy for this server.",
+			DefaultMemberPermissions: &permission.AdminPermissions,
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Name:        "account",
@@ -134,10 +141,27 @@ func bankAccount(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> bankAccount")
 	defer log.Trace("<-- bankAccount")
 
+	accountID := i.ApplicationCommandData().Options[0].Options[0].StringValue()
+	sendAccount(s, i, accountID)
+}
+
+// bankAccountForMember returns information about the bank account for the member selected from the
+// user context menu.
+func bankAccountForMember(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> bankAccountForMember")
+	defer log.Trace("<-- bankAccountForMember")
+
+	sendAccount(s, i, i.ApplicationCommandData().TargetID)
+}
+
+// sendAccount sends information about the bank account with the given ID.
+func sendAccount(s *discordgo.Session, i *discordgo.InteractionCreate, accountID string) {
+	log.Trace("--> sendAccount")
+	defer log.Trace("<-- sendAccount")
+
 	p := getPrinter(i)
 
 	bank := GetBank(i.GuildID)
-	accountID := i.ApplicationCommandData().Options[0].Options[0].StringValue()
 	account, ok := bank.Accounts[accountID]
 	if !ok {
 		resp := p.Sprintf("The bank account for member %s could not be found.", accountID)
@@ -158,9 +182,11 @@ func setLeaderboardChannel(s *discordgo.Session, i *discordgo.InteractionCreate)
 
 	bank := GetBank(i.GuildID)
 	channelID := i.ApplicationCommandData().Options[0].Options[0].StringValue()
+	oldChannelID := bank.ChannelID
 	bank.ChannelID = channelID
 
 	SaveBank(bank)
+	audit.Record(s, i, "/bank channel", "Leaderboard channel", oldChannelID, channelID)
 
 	resp := p.Sprintf("Channel ID for the monthly leaderboard set to %s.", bank.ChannelID)
 	msg.SendResponse(s, i, resp)
@@ -205,20 +231,21 @@ func setAccount(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		return
 	}
 
-	bank := GetBank(i.GuildID)
-	account := bank.GetAccount(id, getMemberName(member.User.ID, member.Nick))
-	account.MonthlyBalance = amount
-	account.CurrentBalance = amount
-	account.LifetimeBalance = amount
+	name := getMemberName(member.User.ID, member.Nick)
+	oldBalance, err := SetBalance(i.GuildID, id, name, amount)
+	if err != nil {
+		msg.SendEphemeralResponse(s, i, "Unable to set the account: "+err.Error())
+		return
+	}
 
-	log.WithFields(log.Fields{
-		"Account": account.Name,
+	log.WithFields(logrus.Fields{
+		"Account": name,
 		"Amount":  amount,
 	}).Debug("/bank set")
 
-	SaveBank(bank)
+	audit.Record(s, i, "/bank set", name+" ("+id+")", oldBalance, amount)
 
-	resp := p.Sprintf("Account for %s was set to %d credits.", account.Name, account.CurrentBalance)
+	resp := p.Sprintf("Account for %s was set to %d credits.", name, amount)
 	msg.SendResponse(s, i, resp)
 }
 
@@ -257,21 +284,27 @@ func transferAccount(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 
 	toAccount := bank.GetAccount(toID, getMemberName(member.User.Username, member.Nick))
+	oldBalances := p.Sprintf("%s: %d, %s: %d", fromAccount.Name, fromAccount.CurrentBalance, toAccount.Name, toAccount.CurrentBalance)
 
+	oldToBalance, oldFromBalance := toAccount.CurrentBalance, fromAccount.CurrentBalance
 	toAccount.MonthlyBalance = fromAccount.MonthlyBalance
 	toAccount.CurrentBalance = fromAccount.CurrentBalance
 	toAccount.LifetimeBalance = fromAccount.LifetimeBalance
 	fromAccount.MonthlyBalance = 0
 	fromAccount.CurrentBalance = 0
 	fromAccount.LifetimeBalance = 0
+	fromAccount.balanceChanged(-oldFromBalance, 0)
+	toAccount.balanceChanged(toAccount.CurrentBalance-oldToBalance, toAccount.CurrentBalance)
 
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"From":    fromAccount.Name,
 		"To":      toAccount.Name,
 		"Balance": toAccount.CurrentBalance,
 	}).Debug("/bank transfer")
 
 	SaveBank(bank)
+	newBalances := p.Sprintf("%s: %d, %s: %d", fromAccount.Name, fromAccount.CurrentBalance, toAccount.Name, toAccount.CurrentBalance)
+	audit.Record(s, i, "/bank transfer", fromAccount.ID+" → "+toAccount.ID, oldBalances, newBalances)
 
 	resp := p.Sprintf("Transferred balance of %d from %s to %s.", toAccount.CurrentBalance, fromAccount.Name, toAccount.Name)
 	msg.SendResponse(s, i, resp)
@@ -279,7 +312,7 @@ func transferAccount(s *discordgo.Session, i *discordgo.InteractionCreate) {
 }
 
 // sendLeaderboard is a utility function that sends an economy leaderboard to Discord.
-func sendLeaderboard(s *discordgo.Session, i *discordgo.InteractionCreate, title string, accounts []*leaderboardAccount) {
+func sendLeaderboard(s *discordgo.Session, i *discordgo.InteractionCreate, title string, accounts []*LeaderboardAccount) {
 	log.Trace("--> sendLeaderboard")
 	defer log.Trace("<-- sendLeaderboard")
 
@@ -315,16 +348,24 @@ func lifetime(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 // Start intializes the economy.
 func Start(s *discordgo.Session) {
-	godotenv.Load()
-	session = s
 	LoadBanks()
-	go resetMonthlyLeaderboard()
+
+	err := scheduler.Add(scheduler.Job{
+		Name:       "economy-monthly-reset",
+		Schedule:   scheduler.MustCron("0 0 1 * *"),
+		RunOnStart: true,
+		Run:        resetMonthlyLeaderboard,
+	})
+	if err != nil {
+		log.Error("Unable to schedule the monthly leaderboard reset, error:", err)
+	}
 }
 
 // GetCommands returns the component handlers, command handlers, and commands for the payday bot.
 func GetCommands() (map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), []*discordgo.ApplicationCommand) {
-	commands := make([]*discordgo.ApplicationCommand, 0, len(memberCommands)+len(adminCommands))
+	commands := make([]*discordgo.ApplicationCommand, 0, len(memberCommands)+len(adminCommands)+len(userCommands))
 	commands = append(commands, memberCommands...)
 	commands = append(commands, adminCommands...)
+	commands = append(commands, userCommands...)
 	return nil, commandHandlers, commands
 }
@@ -1,14 +1,15 @@
 package economy
 
 import (
-	"fmt"
-	"sort"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/event"
+	"github.com/rbrabson/heist/pkg/help"
+	"github.com/rbrabson/heist/pkg/logging"
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
@@ -47,6 +48,7 @@ type Account struct {
 	LifetimeBalance int        `json:"lifetime_balance" bson:"lifetime_balance"`
 	CreatedAt       time.Time  `json:"created_at" bson:"created_at"`
 	Name            string     `json:"name" bson:"name"`
+	bankID          string     `json:"-" bson:"-"`
 	mutex           sync.Mutex `json:"-" bson:"-"`
 }
 
@@ -59,11 +61,12 @@ func newBank(serverID string) *Bank {
 	month := now.Month()
 	year := now.Year()
 	lastMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	defaults := config.Get().Economy
 	bank := Bank{
 		ID:             serverID,
-		DefaultBalance: 20000,
-		BankName:       "Treasury",
-		Currency:       "Coins",
+		DefaultBalance: defaults.DefaultBalance,
+		BankName:       defaults.BankName,
+		Currency:       defaults.Currency,
 		LastSeason:     lastMonth,
 	}
 	bank.Accounts = make(map[string]*Account)
@@ -84,6 +87,11 @@ func GetBank(serverID string) *Bank {
 	return bank
 }
 
+// GetBankCount returns the number of banks cached by the economy.
+func GetBankCount() int {
+	return len(banks)
+}
+
 // newAccount creates a new bank account for the player.
 func newAccount(b *Bank, playerID string, playerName string) *Account {
 	log.Trace("--> NewAccount")
@@ -96,6 +104,7 @@ func newAccount(b *Bank, playerID string, playerName string) *Account {
 		LifetimeBalance: b.DefaultBalance,
 		CreatedAt:       time.Now(),
 		Name:            playerName,
+		bankID:          b.ID,
 	}
 	return &account
 }
@@ -126,11 +135,13 @@ func (a *Account) DepositCredits(amount int) {
 	defer log.Trace("<-- DepositCredits")
 
 	a.mutex.Lock()
-	defer a.mutex.Unlock()
-
 	a.MonthlyBalance += amount
 	a.CurrentBalance += amount
 	a.LifetimeBalance += amount
+	balance := a.CurrentBalance
+	a.mutex.Unlock()
+
+	a.balanceChanged(amount, balance)
 }
 
 // WithDrawCredits deducts the amount of credits from the account at the given bank
@@ -139,18 +150,31 @@ func (a *Account) WithdrawCredits(amount int) error {
 	defer log.Trace("<-- WithdrawCredits")
 
 	a.mutex.Lock()
-	defer a.mutex.Unlock()
-
 	if a.CurrentBalance < amount {
+		a.mutex.Unlock()
 		return ErrInsufficintBalance
 	}
 	a.MonthlyBalance -= amount
 	a.CurrentBalance -= amount
 	a.LifetimeBalance -= amount
+	balance := a.CurrentBalance
+	a.mutex.Unlock()
+
+	a.balanceChanged(-amount, balance)
 
 	return nil
 }
 
+// balanceChanged publishes the change to the balance of the account.
+func (a *Account) balanceChanged(amount int, balance int) {
+	event.Publish(event.BalanceChanged{
+		GuildID:  a.bankID,
+		MemberID: a.ID,
+		Amount:   amount,
+		Balance:  balance,
+	})
+}
+
 // LoadBanks returns all the banks for the given guilds.
 func LoadBanks() {
 	log.Trace("--> LoadBanks")
@@ -161,6 +185,9 @@ func LoadBanks() {
 	for _, bankID := range bankIDs {
 		var bank Bank
 		store.Store.Load(ECONOMY, bankID, &bank)
+		for _, account := range bank.Accounts {
+			account.bankID = bank.ID
+		}
 		banks[bank.ID] = &bank
 	}
 }
@@ -185,40 +212,45 @@ func getMemberName(username string, nickname string) string {
 func getPrinter(i *discordgo.InteractionCreate) *message.Printer {
 	tag, err := language.Parse(string(i.Locale))
 	if err != nil {
-		log.Error("Unable to parse locale, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to parse locale, error:", err)
 		tag = language.English
 	}
 	return message.NewPrinter(tag)
 }
 
-// GetHelp returns help information about the heist bot commands
-func GetMemberHelp() []string {
-	help := make([]string, 0, len(memberCommands))
-
-	for _, command := range memberCommands {
-		commandDescription := fmt.Sprintf("- **/%s**:  %s\n", command.Name, command.Description)
-		help = append(help, commandDescription)
+// GetHelp returns help information about the economy commands.
+func GetHelp() *help.Topic {
+	commands := make([]*discordgo.ApplicationCommand, 0, len(memberCommands)+len(userCommands)+len(adminCommands))
+	commands = append(commands, memberCommands...)
+	commands = append(commands, userCommands...)
+	commands = append(commands, adminCommands...)
+
+	return &help.Topic{
+		Name:        "Economy",
+		Description: "Check your bank account and see who has the most credits on the server.",
+		Commands:    commands,
+		Details: map[string]*help.Detail{
+			"balance": {
+				Examples: []string{"/balance"},
+			},
+			"leaderboard": {
+				Examples: []string{"/leaderboard"},
+			},
+			"lifetime": {
+				Examples: []string{"/lifetime"},
+			},
+			"bank account": {
+				Examples: []string{"/bank account id:123456789012345678"},
+			},
+			"bank set": {
+				Examples: []string{"/bank set id:123456789012345678 amount:20000"},
+			},
+			"bank transfer": {
+				Examples: []string{"/bank transfer from:123456789012345678 to:876543210987654321"},
+			},
+			"bank channel": {
+				Examples: []string{"/bank channel id:123456789012345678"},
+			},
+		},
 	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Economy**\n"}, help...)
-
-	return help
-}
-
-// GetAdminHelp returns help information about the heist bot commands
-func GetAdminHelp() []string {
-	help := make([]string, 0, len(adminCommands))
-
-	for _, command := range adminCommands {
-		commandDescription := fmt.Sprintf("- **/%s**:  %s\n", command.Name, command.Description)
-		help = append(help, commandDescription)
-	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Economy**\n"}, help...)
-
-	return help
 }
@@ -1,6 +1,7 @@
 package economy
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strconv"
@@ -10,18 +11,20 @@ import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/olekukonko/tablewriter"
 	"github.com/rbrabson/heist/pkg/math"
-	log "github.com/sirupsen/logrus"
+	"github.com/rbrabson/heist/pkg/shard"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
 
-type leaderboardAccount struct {
-	name    string
-	balance int
+// LeaderboardAccount is a single entry in a leaderboard.
+type LeaderboardAccount struct {
+	Name    string `json:"name"`
+	Balance int    `json:"balance"`
 }
 
 // formatAccounts formats the leaderboard to be sent to a Discord server
-func formatAccounts(p *message.Printer, title string, accounts []*leaderboardAccount) []*discordgo.MessageEmbed {
+func formatAccounts(p *message.Printer, title string, accounts []*LeaderboardAccount) []*discordgo.MessageEmbed {
 	log.Trace("--> formatAccounts")
 	defer log.Trace("<-- formatAccounts")
 
@@ -40,7 +43,7 @@ func formatAccounts(p *message.Printer, title string, accounts []*leaderboardAcc
 	table.SetNoWhiteSpace(true)
 	table.SetHeader([]string{"#", "Name", "Balance"})
 	for i, account := range accounts {
-		data := []string{strconv.Itoa(i + 1), account.name, p.Sprintf("%d", account.balance)}
+		data := []string{strconv.Itoa(i + 1), account.Name, p.Sprintf("%d", account.Balance)}
 		table.Append(data)
 	}
 	table.Render()
@@ -64,6 +67,9 @@ func getAccounts(bank *Bank) []*Account {
 	log.Trace("--> getAccounts")
 	defer log.Trace("<-- getAccounts")
 
+	if bank == nil {
+		return nil
+	}
 	accounts := make([]*Account, 0, len(bank.Accounts))
 	for _, account := range bank.Accounts {
 		accounts = append(accounts, account)
@@ -137,7 +143,7 @@ func GetLifetimeRanking(serverID string, memberID string) int {
 }
 
 // GetMonthlyLeaderboard returns the top `limit` accounts for the server.
-func GetMonthlyLeaderboard(serverID string, limit int) []*leaderboardAccount {
+func GetMonthlyLeaderboard(serverID string, limit int) []*LeaderboardAccount {
 	log.Trace("--> GetMonthlyLeaderboard")
 	defer log.Trace("<-- GetMonthlyLeaderboard")
 
@@ -147,11 +153,11 @@ func GetMonthlyLeaderboard(serverID string, limit int) []*leaderboardAccount {
 		return accounts[i].MonthlyBalance > accounts[j].MonthlyBalance
 	})
 	num := math.Min(limit, len(accounts))
-	leaderboard := make([]*leaderboardAccount, 0, num)
+	leaderboard := make([]*LeaderboardAccount, 0, num)
 	for _, account := range accounts[:num] {
-		a := leaderboardAccount{
-			name:    account.Name,
-			balance: account.MonthlyBalance,
+		a := LeaderboardAccount{
+			Name:    account.Name,
+			Balance: account.MonthlyBalance,
 		}
 		leaderboard = append(leaderboard, &a)
 	}
@@ -159,7 +165,7 @@ func GetMonthlyLeaderboard(serverID string, limit int) []*leaderboardAccount {
 }
 
 // GetCurrentLeaderboard returns the top `limit` accounts for the server.
-func GetCurrentLeaderboard(serverID string, limit int) []*leaderboardAccount {
+func GetCurrentLeaderboard(serverID string, limit int) []*LeaderboardAccount {
 	log.Trace("--> GetCurrentLeaderboard")
 	defer log.Trace("<-- GetCurrentLeaderboard")
 
@@ -169,11 +175,11 @@ func GetCurrentLeaderboard(serverID string, limit int) []*leaderboardAccount {
 		return accounts[i].CurrentBalance > accounts[j].CurrentBalance
 	})
 	num := math.Min(limit, len(accounts))
-	leaderboard := make([]*leaderboardAccount, 0, num)
+	leaderboard := make([]*LeaderboardAccount, 0, num)
 	for _, account := range accounts[:num] {
-		a := leaderboardAccount{
-			name:    account.Name,
-			balance: account.CurrentBalance,
+		a := LeaderboardAccount{
+			Name:    account.Name,
+			Balance: account.CurrentBalance,
 		}
 		leaderboard = append(leaderboard, &a)
 	}
@@ -181,7 +187,7 @@ func GetCurrentLeaderboard(serverID string, limit int) []*leaderboardAccount {
 }
 
 // GetLifetimeLeaderboard returns the top `limit` accounts for the server.
-func GetLifetimeLeaderboard(serverID string, limit int) []*leaderboardAccount {
+func GetLifetimeLeaderboard(serverID string, limit int) []*LeaderboardAccount {
 	log.Trace("--> GetLifetimeLeaderboard")
 	defer log.Trace("<-- GetLifetimeLeaderboard")
 
@@ -191,77 +197,76 @@ func GetLifetimeLeaderboard(serverID string, limit int) []*leaderboardAccount {
 		return accounts[i].LifetimeBalance > accounts[j].LifetimeBalance
 	})
 	num := math.Min(limit, len(accounts))
-	leaderboard := make([]*leaderboardAccount, 0, num)
+	leaderboard := make([]*LeaderboardAccount, 0, num)
 	for _, account := range accounts[:num] {
-		a := leaderboardAccount{
-			name:    account.Name,
-			balance: account.LifetimeBalance,
+		a := LeaderboardAccount{
+			Name:    account.Name,
+			Balance: account.LifetimeBalance,
 		}
 		leaderboard = append(leaderboard, &a)
 	}
 	return leaderboard
 }
 
-// resetMonthlyLeaderboard resets the MonthlyBalance for all accounts to zero.
-func resetMonthlyLeaderboard() {
+// resetMonthlyLeaderboard resets the MonthlyBalance for all accounts to zero at the start of each month, and
+// publishes the leaderboard for the month that just ended. A bank whose last season started before the current
+// month is reset, so a reset that was missed while the bot was down is done once the bot is back up. Only the
+// banks for servers handled by the shards run by this process are reset.
+func resetMonthlyLeaderboard(ctx context.Context) error {
 	log.Trace("--> resetMonthlyLeaderboard")
 	defer log.Trace("<-- resetMonthlyLeaderboard")
 
-	var lastSeason time.Time
+	now := time.Now().UTC()
+	season := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	lastSeason := season.AddDate(0, -1, 0)
+
 	for _, bank := range banks {
-		if lastSeason.Before(bank.LastSeason) {
-			lastSeason = bank.LastSeason
+		if ctx.Err() != nil {
+			return ctx.Err()
 		}
-		break
-	}
-	if lastSeason.Year() == 1 {
-		lastSeason = time.Now()
-	}
-	month := lastSeason.Month()
-	year := lastSeason.Year()
-
-	for {
-		month++
-		if month > time.December {
-			month = time.January
-			year++
+		if !shard.Owns(bank.ID) {
+			continue
+		}
+		if bank.LastSeason.IsZero() {
+			bank.LastSeason = season
+			SaveBank(bank)
+			continue
+		}
+		if !bank.LastSeason.Before(season) {
+			continue
 		}
-		log.WithFields(log.Fields{"Month": month, "Year": year}).Debug("Reset Economy On Date")
-
-		nextMonth := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
-		time.Sleep(time.Until(nextMonth))
 
-		for _, bank := range banks {
-			// Trace last season's leaderboard
-			accounts := GetMonthlyLeaderboard(bank.ID, 10)
-			for i, account := range accounts {
-				log.WithFields(log.Fields{
-					"Rank":    i + 1,
-					"Server":  bank.ID,
-					"Account": account.name,
-					"Balance": account.balance}).Info("Monthly Leaderboard Reset")
-			}
+		// Trace last season's leaderboard
+		accounts := GetMonthlyLeaderboard(bank.ID, 10)
+		for i, account := range accounts {
+			log.WithFields(logrus.Fields{
+				"Rank":    i + 1,
+				"Server":  bank.ID,
+				"Account": account.Name,
+				"Balance": account.Balance}).Info("Monthly Leaderboard Reset")
+		}
 
-			if bank.ChannelID != "" {
-				p := message.NewPrinter(language.English)
-				embeds := formatAccounts(p, fmt.Sprintf("%s %d Top 10", bank.LastSeason.Month().String(), bank.LastSeason.Year()), accounts)
-				_, err := session.ChannelMessageSendComplex(bank.ChannelID, &discordgo.MessageSend{
-					Embeds: embeds,
-				})
-				if err != nil {
-					log.Error("Unable to send montly leaderboard, err:", err)
-				}
-			} else {
-				log.WithField("guildID", bank.ChannelID).Warning("No leaderboard channel set for server")
+		if bank.ChannelID != "" {
+			p := message.NewPrinter(language.English)
+			embeds := formatAccounts(p, fmt.Sprintf("%s %d Top 10", lastSeason.Month().String(), lastSeason.Year()), accounts)
+			_, err := shard.Session(bank.ID).ChannelMessageSendComplex(bank.ChannelID, &discordgo.MessageSend{
+				Embeds: embeds,
+			})
+			if err != nil {
+				log.Error("Unable to send montly leaderboard, err:", err)
 			}
+		} else {
+			log.WithField("guildID", bank.ID).Warning("No leaderboard channel set for server")
+		}
 
-			bank.LastSeason = nextMonth
-			for _, account := range bank.Accounts {
-				account.mutex.Lock()
-				account.MonthlyBalance = 0
-				account.mutex.Unlock()
-			}
-			SaveBank(bank)
+		bank.LastSeason = season
+		for _, account := range bank.Accounts {
+			account.mutex.Lock()
+			account.MonthlyBalance = 0
+			account.mutex.Unlock()
 		}
+		SaveBank(bank)
 	}
+
+	return nil
 }
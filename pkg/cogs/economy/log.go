@@ -0,0 +1,6 @@
+package economy
+
+import "github.com/rbrabson/heist/pkg/logging"
+
+// log is the logger used by the economy cog.
+var log = logging.Logger("economy")
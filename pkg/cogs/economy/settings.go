@@ -0,0 +1,122 @@
+package economy
+
+import (
+	"errors"
+	"sort"
+)
+
+const (
+	maxNameLength = 32
+)
+
+// BankConfig is the configuration of the bank for a server.
+type BankConfig struct {
+	BankName       string
+	Currency       string
+	DefaultBalance int
+}
+
+// Validate checks that the bank configuration may be used by a server. All problems that are found are
+// returned in a single error.
+func (c *BankConfig) Validate() error {
+	var problems []error
+
+	if c.BankName == "" || len(c.BankName) > maxNameLength {
+		problems = append(problems, errors.New("the bank name must be between 1 and 32 characters"))
+	}
+	if c.Currency == "" || len(c.Currency) > maxNameLength {
+		problems = append(problems, errors.New("the currency must be between 1 and 32 characters"))
+	}
+	if c.DefaultBalance < 0 {
+		problems = append(problems, errors.New("the default balance must not be negative"))
+	}
+
+	return errors.Join(problems...)
+}
+
+// GetBankConfig returns the configuration of the bank for the server.
+func GetBankConfig(guildID string) *BankConfig {
+	bank := GetBank(guildID)
+	bank.mutex.Lock()
+	defer bank.mutex.Unlock()
+
+	return &BankConfig{
+		BankName:       bank.BankName,
+		Currency:       bank.Currency,
+		DefaultBalance: bank.DefaultBalance,
+	}
+}
+
+// UpdateBankConfig changes the configuration of the bank for the server. The changes are made to a copy
+// of the configuration, which is only used if it is valid. The configuration before the change is returned.
+func UpdateBankConfig(guildID string, update func(cfg *BankConfig)) (*BankConfig, error) {
+	log.Trace("--> UpdateBankConfig")
+	defer log.Trace("<-- UpdateBankConfig")
+
+	oldConfig := GetBankConfig(guildID)
+	newConfig := *oldConfig
+	update(&newConfig)
+	err := newConfig.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	bank := GetBank(guildID)
+	bank.mutex.Lock()
+	bank.BankName = newConfig.BankName
+	bank.Currency = newConfig.Currency
+	bank.DefaultBalance = newConfig.DefaultBalance
+	bank.mutex.Unlock()
+
+	SaveBank(bank)
+	return oldConfig, nil
+}
+
+// SetBalance sets the balance of the member's account. The balance before the change is returned.
+func SetBalance(guildID string, memberID string, memberName string, amount int) (int, error) {
+	log.Trace("--> SetBalance")
+	defer log.Trace("<-- SetBalance")
+
+	if amount < 0 {
+		return 0, ErrInvalidBalance
+	}
+
+	bank := GetBank(guildID)
+	account := bank.GetAccount(memberID, memberName)
+	account.mutex.Lock()
+	oldBalance := account.CurrentBalance
+	account.MonthlyBalance = amount
+	account.CurrentBalance = amount
+	account.LifetimeBalance = amount
+	account.mutex.Unlock()
+	account.balanceChanged(amount-oldBalance, amount)
+
+	SaveBank(bank)
+	return oldBalance, nil
+}
+
+// GetAccounts returns a copy of the accounts at the server's bank, sorted by name.
+func GetAccounts(guildID string) []*Account {
+	bank, ok := banks[guildID]
+	if !ok {
+		return nil
+	}
+	bank.mutex.Lock()
+	accounts := make([]*Account, 0, len(bank.Accounts))
+	for _, account := range bank.Accounts {
+		accounts = append(accounts, &Account{
+			ID:              account.ID,
+			MonthlyBalance:  account.MonthlyBalance,
+			CurrentBalance:  account.CurrentBalance,
+			LifetimeBalance: account.LifetimeBalance,
+			CreatedAt:       account.CreatedAt,
+			Name:            account.Name,
+		})
+	}
+	bank.mutex.Unlock()
+
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Name < accounts[j].Name
+	})
+	return accounts
+}
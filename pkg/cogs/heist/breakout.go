@@ -0,0 +1,177 @@
+package heist
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/format"
+	discmsg "github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/store"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/message"
+)
+
+const (
+	defaultBreakoutChance  = 25 // Percentage chance a Greenhorn breaks out of jail
+	defaultBreakoutAlert   = 50 // Percentage the police alert grows after a heist with a fugitive in the crew
+	defaultBreakoutPenalty = 50 // Percentage the sentence grows after a failed breakout
+	defaultBreakoutBail    = 50 // Percentage the bail grows after a failed breakout
+	breakoutBonusPerRank   = 5  // Percentage added to the chance of a breakout for each rank above Greenhorn
+	maxBreakoutChance      = 95 // Largest chance a breakout succeeds
+	maxBreakoutIncrease    = 1000
+
+	defaultBreakoutSuccess = "%s slipped out of jail and is now on the run."
+	defaultBreakoutFailure = "The guards caught %s trying to escape."
+)
+
+// setBreakoutDefaults sets the default jailbreak settings for a server.
+func (c *Config) setBreakoutDefaults() {
+	c.BreakoutChance = defaultBreakoutChance
+	c.BreakoutAlert = defaultBreakoutAlert
+	c.BreakoutPenalty = defaultBreakoutPenalty
+	c.BreakoutBail = defaultBreakoutBail
+}
+
+// validateBreakout checks the jailbreak settings for the server.
+func (c *Config) validateBreakout() error {
+	var problems []error
+
+	if c.BreakoutChance <= 0 || c.BreakoutChance > maxBreakoutChance {
+		problems = append(problems, errors.New("the breakout chance must be between 1 and 95 percent"))
+	}
+	if c.BreakoutAlert < 0 || c.BreakoutAlert > maxBreakoutIncrease {
+		problems = append(problems, errors.New("the fugitive police alert must be between 0 and 1,000 percent"))
+	}
+	if c.BreakoutPenalty < 0 || c.BreakoutPenalty > maxBreakoutIncrease {
+		problems = append(problems, errors.New("the breakout sentence penalty must be between 0 and 1,000 percent"))
+	}
+	if c.BreakoutBail < 0 || c.BreakoutBail > maxBreakoutIncrease {
+		problems = append(problems, errors.New("the breakout bail penalty must be between 0 and 1,000 percent"))
+	}
+
+	return errors.Join(problems...)
+}
+
+// formatBreakout returns a description of the jailbreak settings for the server.
+func formatBreakout(p *message.Printer, cfg *Config) string {
+	return p.Sprintf("%d%% chance plus %d%% per rank, fugitives raise the police alert %d%%, a failure adds %d%% to the sentence and %d%% to the bail",
+		cfg.BreakoutChance, breakoutBonusPerRank, cfg.BreakoutAlert, cfg.BreakoutPenalty, cfg.BreakoutBail)
+}
+
+// breakoutChance returns the percentage chance that a player with the given criminal level breaks a
+// prisoner out of jail.
+func (c *Config) breakoutChance(level CriminalLevel) int {
+	return min(int(c.BreakoutChance)+level.rank()*breakoutBonusPerRank, maxBreakoutChance)
+}
+
+// escape frees a player who broke out of jail. The player is a fugitive until their next heist.
+func escape(player *Player) {
+	player.BailCost = 0
+	player.JailTimer = time.Time{}
+	player.NoBail = false
+	player.OOB = false
+	player.Sentence = 0
+	player.Status = FREE
+	player.Fugitive = true
+}
+
+// recapture extends the sentence and raises the bail of a player whose breakout failed.
+func recapture(cfg *Config, player *Player) {
+	extra := player.Sentence * time.Duration(cfg.BreakoutPenalty) / 100
+	player.Sentence += extra
+	player.JailTimer = player.JailTimer.Add(extra)
+	player.BailCost += player.BailCost * cfg.BreakoutBail / 100
+}
+
+// breakout attempts to break a player out of jail. The player initiating the command may break themselves
+// or another player out, and their criminal level is used for the chance of success. A prisoner gets a
+// single attempt for each sentence.
+func breakout(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> breakout")
+	defer log.Trace("<-- breakout")
+
+	server := GetServer(servers, i.GuildID)
+
+	msg, ok := attemptBreakout(server, i)
+	if !ok {
+		discmsg.SendEphemeralResponse(s, i, msg)
+		return
+	}
+	discmsg.SendResponse(s, i, msg)
+}
+
+// attemptBreakout makes the breakout attempt, returning the message announcing the result. If no attempt
+// can be made, the reason is returned along with `false`.
+func attemptBreakout(server *Server, i *discordgo.InteractionCreate) (string, bool) {
+	p := getPrinter(i)
+	theme := getThemes()[server.Config.Theme]
+
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+
+	rescuer := server.GetPlayer(i.Member.User.ID, i.Member.User.Username, i.Member.Nick)
+	prisoner := rescuer
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		if option.Name == "id" {
+			var ok bool
+			prisoner, ok = server.Players[option.StringValue()]
+			if !ok {
+				return "Player " + option.StringValue() + " does not exist.", false
+			}
+		}
+	}
+
+	if prisoner.Status != APPREHENDED || prisoner.OOB {
+		if prisoner.ID == rescuer.ID {
+			return "You are not in " + theme.Jail + ".", false
+		}
+		return p.Sprintf("%s is not in %s.", prisoner.Name, theme.Jail), false
+	}
+	if prisoner.JailTimer.Before(time.Now()) {
+		return p.Sprintf("%s has already served their %s.", prisoner.Name, theme.Sentence), false
+	}
+	if prisoner.BreakoutTried {
+		return p.Sprintf("The guards are watching %s too closely for another attempt. Wait out the %s or pay the %s.", prisoner.Name, theme.Sentence, theme.Bail), false
+	}
+	if rescuer.ID != prisoner.ID && rescuer.Status == APPREHENDED && !rescuer.OOB && rescuer.JailTimer.After(time.Now()) {
+		return "You can't break anyone out while you are in " + theme.Jail + " yourself.", false
+	}
+
+	prisoner.BreakoutTried = true
+	chance := server.Config.breakoutChance(rescuer.CriminalLevel)
+	roll := rand.Intn(100) + 1
+	success := roll <= chance
+	log.WithFields(logrus.Fields{"Server": server.ID, "Rescuer": rescuer.Name, "Prisoner": prisoner.Name, "Chance": chance, "Roll": roll, "Success": success}).Debug("Breakout")
+
+	var msg string
+	if success {
+		escape(prisoner)
+		msg = p.Sprintf(breakoutMessage(theme.BreakoutSuccess, defaultBreakoutSuccess), "**"+prisoner.Name+"**")
+		msg += p.Sprintf("\n%s is now a fugitive. The %s will be on high alert after their next %s, and the %s will be much higher if they are caught.", prisoner.Name, theme.Police, theme.Heist, theme.Bail)
+	} else {
+		recapture(&server.Config, prisoner)
+		msg = p.Sprintf(breakoutMessage(theme.BreakoutFailure, defaultBreakoutFailure), "**"+prisoner.Name+"**")
+		msg += p.Sprintf("\n%s has %s left on their %s", prisoner.Name, format.Duration(time.Until(prisoner.JailTimer)), theme.Sentence)
+		if prisoner.BailCost > 0 {
+			msg += p.Sprintf(", and their %s is now %d credits", theme.Bail, prisoner.BailCost)
+		}
+		msg += "."
+	}
+	if rescuer.ID != prisoner.ID {
+		msg = fmt.Sprintf("%s tried to break %s out of %s.\n", rescuer.Name, prisoner.Name, theme.Jail) + msg
+	}
+	store.Store.Save(HEIST, server.ID, server)
+
+	return msg, true
+}
+
+// breakoutMessage returns the themed breakout message, or the default message if the theme doesn't have one.
+func breakoutMessage(themed string, defaultMessage string) string {
+	if themed == "" {
+		return defaultMessage
+	}
+	return themed
+}
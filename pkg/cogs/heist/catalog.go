@@ -0,0 +1,138 @@
+package heist
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	botconfig "github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/store"
+)
+
+var (
+	current atomic.Pointer[catalog]
+)
+
+// catalog is the set of themes and targets that may be used by the heist bot. The catalog is replaced
+// as a whole when it is reloaded, so a heist always sees a consistent set of themes and targets.
+type catalog struct {
+	themes  map[string]*Theme
+	targets map[string]*Targets
+}
+
+// setCatalog sets the themes and targets used by the heist bot.
+func setCatalog(themes map[string]*Theme, targets map[string]*Targets) {
+	current.Store(&catalog{
+		themes:  themes,
+		targets: targets,
+	})
+}
+
+// getThemes returns the themes that may be used by the heist bot.
+func getThemes() map[string]*Theme {
+	c := current.Load()
+	if c == nil {
+		return nil
+	}
+	return c.themes
+}
+
+// getTargetSet returns the sets of targets that may be used by the heist bot.
+func getTargetSet() map[string]*Targets {
+	c := current.Load()
+	if c == nil {
+		return nil
+	}
+	return c.targets
+}
+
+// Catalog is a set of themes and targets that has been loaded from the store and validated, but isn't
+// used by the heist bot until it is applied.
+type Catalog struct {
+	themes  map[string]*Theme
+	targets map[string]*Targets
+}
+
+// LoadCatalog loads the themes and targets from the store and validates them. They are only returned
+// if they are valid.
+func LoadCatalog() (*Catalog, error) {
+	log.Trace("--> heist.LoadCatalog")
+	defer log.Trace("<-- heist.LoadCatalog")
+
+	themes := LoadThemes()
+	targets := LoadTargets()
+	err := validateCatalog(themes, targets)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{themes: themes, targets: targets}, nil
+}
+
+// Apply replaces the themes and targets used by the heist bot with the ones in the catalog. The targets
+// for each server are updated to match the new targets without changing the vault balances.
+func (c *Catalog) Apply() {
+	log.Trace("--> heist.Catalog.Apply")
+	defer log.Trace("<-- heist.Catalog.Apply")
+
+	setCatalog(c.themes, c.targets)
+	for _, server := range servers {
+		server.Mutex.Lock()
+		server.applyTargets(c.targets[server.Config.Targets])
+		store.Store.Save(HEIST, server.ID, server)
+		server.Mutex.Unlock()
+	}
+	log.WithField("Themes", len(c.themes)).WithField("Targets", len(c.targets)).Info("Reloaded the heist themes and targets")
+}
+
+// Counts returns the number of themes and target sets in the catalog.
+func (c *Catalog) Counts() (int, int) {
+	return len(c.themes), len(c.targets)
+}
+
+// validateCatalog checks that the themes and targets are usable by the heist bot, including by the
+// servers that are already using them.
+func validateCatalog(themes map[string]*Theme, targets map[string]*Targets) error {
+	var problems []error
+
+	for id, theme := range themes {
+		if id == "" {
+			problems = append(problems, errors.New("a heist theme is missing its ID"))
+			continue
+		}
+		if len(theme.Good) == 0 || len(theme.Bad) == 0 {
+			problems = append(problems, fmt.Errorf("heist theme `%s` must have both good and bad messages", id))
+		}
+	}
+	for id, set := range targets {
+		if id == "" {
+			problems = append(problems, errors.New("a set of heist targets is missing its ID"))
+			continue
+		}
+		if len(set.Targets) == 0 {
+			problems = append(problems, fmt.Errorf("heist targets `%s` has no targets", id))
+		}
+		for _, target := range set.Targets {
+			if err := target.Validate(); err != nil {
+				problems = append(problems, fmt.Errorf("heist target `%s` in `%s` is invalid: %w", target.ID, id, err))
+			}
+		}
+	}
+
+	defaultTheme := botconfig.Get().Heist.DefaultTheme
+	if _, ok := themes[defaultTheme]; !ok {
+		problems = append(problems, fmt.Errorf("the default heist theme `%s` is missing", defaultTheme))
+	}
+	if _, ok := targets[defaultTheme]; !ok {
+		problems = append(problems, fmt.Errorf("the targets for the default heist theme `%s` are missing", defaultTheme))
+	}
+	for _, server := range servers {
+		if _, ok := themes[server.Config.Theme]; !ok {
+			problems = append(problems, fmt.Errorf("heist theme `%s` is missing but is used by server %s", server.Config.Theme, server.ID))
+		}
+		if _, ok := targets[server.Config.Targets]; !ok {
+			problems = append(problems, fmt.Errorf("heist targets `%s` are missing but are used by server %s", server.Config.Targets, server.ID))
+		}
+	}
+
+	return errors.Join(problems...)
+}
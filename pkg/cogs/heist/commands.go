@@ -16,13 +16,20 @@ import (
 	"golang.org/x/text/message"
 
 	"github.com/rbrabson/heist/pkg/channel"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
 	"github.com/rbrabson/heist/pkg/cogs/economy"
 	"github.com/rbrabson/heist/pkg/cogs/payday"
+	botconfig "github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/event"
 	"github.com/rbrabson/heist/pkg/format"
+	"github.com/rbrabson/heist/pkg/logging"
 	hmath "github.com/rbrabson/heist/pkg/math"
+	"github.com/rbrabson/heist/pkg/member"
 	discmsg "github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+	"github.com/rbrabson/heist/pkg/scheduler"
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/olekukonko/tablewriter"
@@ -30,22 +37,38 @@ import (
 
 const (
 	HEIST = "heist"
+
+	hardcoreColor = 0xB71C1C
 )
 
 var (
-	servers   map[string]*Server
-	themes    map[string]*Theme
-	targetSet map[string]*Targets
+	servers map[string]*Server
 )
 
 // componentHandlers are the buttons that appear on messages sent by this bot.
 var (
 	componentHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"join_heist": joinHeist,
+		"join_heist":   joinHeist,
+		"leave_heist":  leaveHeist,
+		"cancel_heist": cancelHeist,
+		targetVoteID:   voteForTarget,
 	}
 	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
 		"heist":       heist,
 		"heist-admin": admin,
+		"Heist stats": playerStatsForMember,
+		"Bail out":    bailoutMember,
+	}
+
+	userCommands = []*discordgo.ApplicationCommand{
+		{
+			Name: "Heist stats",
+			Type: discordgo.UserApplicationCommand,
+		},
+		{
+			Name: "Bail out",
+			Type: discordgo.UserApplicationCommand,
+		},
 	}
 
 	playerCommands = []*discordgo.ApplicationCommand{
@@ -66,6 +89,68 @@ var (
 						},
 					},
 				},
+				{
+					Name:        "breakout",
+					Description: "Attempt to break a player out of jail.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of the player to break out. Defaults to you.",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Name:        "history",
+					Description: "Shows the recent heists, or the details of a single heist.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "id",
+							Description: "ID of a player whose heists are shown. Defaults to all players.",
+							Required:    false,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionInteger,
+							Name:        "heist",
+							Description: "Number of the heist to show the details for.",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Name:        "leaderboard",
+					Description: "Shows a heist leaderboard.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "board",
+							Description: "The leaderboard to show. Defaults to the most loot.",
+							Required:    false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "Most loot", Value: LootBoard},
+								{Name: "Longest spree", Value: SpreeBoard},
+								{Name: "Most heists survived", Value: SurvivedBoard},
+								{Name: "Most deaths", Value: DeathsBoard},
+								{Name: "Most time in jail", Value: JailBoard},
+							},
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "period",
+							Description: "The period the leaderboard covers. Defaults to this month.",
+							Required:    false,
+							Choices: []*discordgo.ApplicationCommandOptionChoice{
+								{Name: "This month", Value: PeriodMonthly},
+								{Name: "All time", Value: PeriodLifetime},
+							},
+						},
+					},
+				},
 				{
 					Name:        "stats",
 					Description: "Shows a user's stats.",
@@ -87,8 +172,9 @@ var (
 
 	adminCommands = []*discordgo.ApplicationCommand{
 		{
-			Name:        "heist-admin",
-			Description: "Heist admin commands.",
+			Name:                     "heist-admin",
+			Description:              "Heist admin commands.",
+			DefaultMemberPermissions: &permission.AdminPermissions,
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Name:        "clear",
@@ -126,6 +212,37 @@ var (
 								},
 							},
 						},
+						{
+							Name:        "breakout",
+							Description: "Sets the chance and consequences of breaking out of jail.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "chance",
+									Description: "Percentage chance a Greenhorn breaks out. Each rank adds 5%.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "alert",
+									Description: "Percentage the police alert grows after a heist with a fugitive.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "penalty",
+									Description: "Percentage added to the sentence after a failed breakout.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "bail",
+									Description: "Percentage added to the bail after a failed breakout.",
+									Required:    false,
+								},
+							},
+						},
 						{
 							Name:        "cost",
 							Description: "Sets the cost to plan or join a heist.",
@@ -152,6 +269,56 @@ var (
 								},
 							},
 						},
+						{
+							Name:        "hardcore",
+							Description: "Turns hardcore mode on or off.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionBoolean,
+									Name:        "enabled",
+									Description: "Whether hardcore mode is used for new heists.",
+									Required:    true,
+								},
+							},
+						},
+						{
+							Name:        "levels",
+							Description: "Sets the XP needed for each criminal level and earned for each heist.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "xp",
+									Description: "The XP needed to reach each criminal level.",
+									Required:    true,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "reward",
+									Description: "The XP earned by each member of the crew who escapes.",
+									Required:    false,
+								},
+							},
+						},
+						{
+							Name:        "output",
+							Description: "Sets how the outcome for each member of the crew is shown.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionString,
+									Name:        "mode",
+									Description: "How the outcomes are shown.",
+									Required:    true,
+									Choices: []*discordgo.ApplicationCommandOptionChoice{
+										{Name: "A message for each crew member", Value: CrewOutputFull},
+										{Name: "A single message listing every outcome", Value: CrewOutputCondensed},
+										{Name: "Only the number who escaped, were caught or died", Value: CrewOutputSummary},
+									},
+								},
+							},
+						},
 						{
 							Name:        "patrol",
 							Description: "Sets the time the authorities will prevent a new heist.",
@@ -178,6 +345,19 @@ var (
 								},
 							},
 						},
+						{
+							Name:        "refund",
+							Description: "Sets whether members who leave a heist get the cost of the heist back.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionBoolean,
+									Name:        "enabled",
+									Description: "Whether the cost of the heist is refunded when leaving.",
+									Required:    true,
+								},
+							},
+						},
 						{
 							Name:        "sentence",
 							Description: "Sets the base apprehension time when caught.",
@@ -191,6 +371,55 @@ var (
 								},
 							},
 						},
+						{
+							Name:        "spree",
+							Description: "Sets the rewards and risks for players on a spree.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "bonus",
+									Description: "Percentage of the loot added for each heist in a spree.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "cap",
+									Description: "Largest percentage of the loot added for a spree.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "penalty",
+									Description: "Percentage taken from the success rate for each heist in a hot streak.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionString,
+									Name:        "milestones",
+									Description: "Comma separated spree lengths that are announced, or none. The first starts a hot streak.",
+									Required:    false,
+								},
+							},
+						},
+						{
+							Name:        "vote",
+							Description: "Sets how the target for a heist is picked.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionString,
+									Name:        "mode",
+									Description: "Who picks the target.",
+									Required:    true,
+									Choices: []*discordgo.ApplicationCommandOptionChoice{
+										{Name: "Picked based on the crew size", Value: TargetAuto},
+										{Name: "Picked by the planner", Value: TargetPlanner},
+										{Name: "Voted on by the crew", Value: TargetCrew},
+									},
+								},
+							},
+						},
 						{
 							Name:        "wait",
 							Description: "Sets how long players can gather others for a heist.",
@@ -231,6 +460,142 @@ var (
 						},
 					},
 				},
+				{
+					Name:        "target",
+					Description: "Commands that manage the custom heist targets for the server.",
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Name:        "add",
+							Description: "Adds a custom target.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionString,
+									Name:        "name",
+									Description: "Name of the target.",
+									Required:    true,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "crew",
+									Description: "Maximum crew size for the target.",
+									Required:    true,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionNumber,
+									Name:        "success",
+									Description: "Success rate for the target, from 0 to 100.",
+									Required:    true,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "vault_max",
+									Description: "Maximum number of credits in the vault.",
+									Required:    true,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "vault",
+									Description: "Current number of credits in the vault. Defaults to the maximum.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "level",
+									Description: "Criminal level the planner needs to hit the target.",
+									Required:    false,
+								},
+							},
+						},
+						{
+							Name:        "edit",
+							Description: "Changes a target, which then becomes a custom target.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionString,
+									Name:        "name",
+									Description: "Name of the target.",
+									Required:    true,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "crew",
+									Description: "Maximum crew size for the target.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionNumber,
+									Name:        "success",
+									Description: "Success rate for the target, from 0 to 100.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "vault_max",
+									Description: "Maximum number of credits in the vault.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "vault",
+									Description: "Current number of credits in the vault.",
+									Required:    false,
+								},
+								{
+									Type:        discordgo.ApplicationCommandOptionInteger,
+									Name:        "level",
+									Description: "Criminal level the planner needs to hit the target.",
+									Required:    false,
+								},
+							},
+						},
+						{
+							Name:        "remove",
+							Description: "Removes a custom target.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionString,
+									Name:        "name",
+									Description: "Name of the target.",
+									Required:    true,
+								},
+							},
+						},
+						{
+							Name:        "list",
+							Description: "Lists the targets for the server.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+						},
+					},
+				},
+				{
+					Name:        "targets",
+					Description: "Commands that interact with the sets of heist targets.",
+					Type:        discordgo.ApplicationCommandOptionSubCommandGroup,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Name:        "list",
+							Description: "Gets the list of available sets of heist targets.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+						},
+						{
+							Name:        "set",
+							Description: "Sets the set of targets used by the server.",
+							Type:        discordgo.ApplicationCommandOptionSubCommand,
+							Options: []*discordgo.ApplicationCommandOption{
+								{
+									Type:        discordgo.ApplicationCommandOptionString,
+									Name:        "name",
+									Description: "Name of the set of targets to use.",
+									Required:    true,
+								},
+							},
+						},
+					},
+				},
 				{
 					Name:        "reset",
 					Description: "Resets a new heist that is hung.",
@@ -260,10 +625,24 @@ func config(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		configBail(s, i)
 	case "death":
 		configDeath(s, i)
+	case "breakout":
+		configBreakout(s, i)
+	case "hardcore":
+		configHardcore(s, i)
+	case "levels":
+		configLevels(s, i)
+	case "output":
+		configOutput(s, i)
+	case "refund":
+		configRefund(s, i)
+	case "spree":
+		configSpree(s, i)
 	case "wait":
 		configWait(s, i)
 	case "payday":
 		configPayday(s, i)
+	case "vote":
+		configVote(s, i)
 	case "info":
 		configInfo(s, i)
 	}
@@ -283,13 +662,45 @@ func theme(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	}
 }
 
+// manageTarget routes the custom target commands to the proper handlers.
+func manageTarget(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> manageTarget")
+	defer log.Trace("<-- manageTarget")
+
+	options := i.ApplicationCommandData().Options[0].Options
+	switch options[0].Name {
+	case "add":
+		addCustomTarget(s, i)
+	case "edit":
+		editCustomTarget(s, i)
+	case "remove":
+		removeCustomTarget(s, i)
+	case "list":
+		listServerTargets(s, i)
+	}
+}
+
+// manageTargetSet routes the target set commands to the proper handlers.
+func manageTargetSet(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> manageTargetSet")
+	defer log.Trace("<-- manageTargetSet")
+
+	options := i.ApplicationCommandData().Options[0].Options
+	switch options[0].Name {
+	case "list":
+		listTargetSets(s, i)
+	case "set":
+		setTargetSet(s, i)
+	}
+}
+
 /******** UTILITY FUNCTIONS ********/
 
 // getPrinter returns a printer for the given locale of the user initiating the message.
 func getPrinter(i *discordgo.InteractionCreate) *message.Printer {
 	tag, err := language.Parse(string(i.Locale))
 	if err != nil {
-		log.Error("Unable to parse locale, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to parse locale, error:", err)
 		tag = language.English
 	}
 	return message.NewPrinter(tag)
@@ -297,11 +708,25 @@ func getPrinter(i *discordgo.InteractionCreate) *message.Printer {
 
 /******** MESSAGE UTILITIES ********/
 
-// heistMessage sends the main command used to plan, join and leave a heist. It also handles the case where
-// the heist starts, disabling the buttons to join/leave/cancel the heist.
-func heistMessage(s *discordgo.Session, i *discordgo.InteractionCreate, action string) error {
-	log.Trace("--> heistMessage")
-	defer log.Trace("<-- heistMessage")
+// heistMessage is the main message used to plan, join and leave a heist. It is built while the server's
+// mutex is held, and sent once the mutex has been released so the heist isn't locked while Discord is
+// being called.
+type heistMessage struct {
+	heist       *Heist
+	seq         uint64
+	interaction *discordgo.InteractionCreate
+	messageID   string
+	channelID   string
+	embeds      []*discordgo.MessageEmbed
+	components  []discordgo.MessageComponent
+}
+
+// newHeistMessage builds the main message used to plan, join and leave a heist. It also handles the case
+// where the heist starts, disabling the buttons to join/leave/cancel the heist. The server's mutex must be
+// held by the caller.
+func newHeistMessage(i *discordgo.InteractionCreate, action string) *heistMessage {
+	log.Trace("--> newHeistMessage")
+	defer log.Trace("<-- newHeistMessage")
 
 	p := getPrinter(i)
 
@@ -329,20 +754,33 @@ func heistMessage(s *discordgo.Session, i *discordgo.InteractionCreate, action s
 		buttonDisabled = true
 	}
 
-	server.Heist.Mutex.Lock()
+	voting := server.Config.targetVoteMode() != TargetAuto
+	var voteMenu discordgo.MessageComponent
+	var leader string
 	crew := make([]string, 0, len(server.Heist.Crew))
 	for _, id := range server.Heist.Crew {
 		crew = append(crew, server.Players[id].Name)
 	}
-	server.Heist.Mutex.Unlock()
+	if voting {
+		voteMenu, leader = voteComponents(p, server, buttonDisabled)
+	}
 
-	theme := themes[server.Config.Theme]
+	theme := getThemes()[server.Config.Theme]
 	caser := cases.Caser(cases.Title(language.Und, cases.NoLower))
-	msg := p.Sprintf("A new %s is being planned by %s. You can join the %s for a cost of %d credits at any time prior to the %s starting.", theme.Heist, player.Name, theme.Heist, server.Config.HeistCost, theme.Heist)
+	msg := p.Sprintf("A new %s is being planned by %s. You can join the %s for a cost of %d credits at any time prior to the %s starting.", theme.Heist, player.Name, theme.Heist, server.Heist.Cost, theme.Heist)
+	title := "Heist"
+	var color int
+	if server.Heist.Hardcore {
+		title = "Heist (Hardcore)"
+		color = hardcoreColor
+		msg = p.Sprintf("**Hardcore mode is active.** Dying costs %d%% of your balance, %ss grow faster, there is no %s, and the %s bonus is halved.\n\n",
+			hardcoreDeathPenalty, theme.Sentence, theme.Bail, theme.Crew) + msg
+	}
 	embeds := []*discordgo.MessageEmbed{
 		{
 			Type:        discordgo.EmbedTypeRich,
-			Title:       "Heist",
+			Title:       title,
+			Color:       color,
 			Description: msg,
 			Fields: []*discordgo.MessageEmbedField{
 				{
@@ -367,20 +805,74 @@ func heistMessage(s *discordgo.Session, i *discordgo.InteractionCreate, action s
 				CustomID: "join_heist",
 				Emoji:    nil,
 			},
+			discordgo.Button{
+				Label:    "Leave",
+				Style:    discordgo.SecondaryButton,
+				Disabled: buttonDisabled,
+				CustomID: "leave_heist",
+				Emoji:    nil,
+			},
+			discordgo.Button{
+				Label:    "Cancel",
+				Style:    discordgo.DangerButton,
+				Disabled: buttonDisabled,
+				CustomID: "cancel_heist",
+				Emoji:    nil,
+			},
 		}},
 	}
-	emptymsg := ""
+	if voting {
+		embeds[0].Fields = append(embeds[0].Fields, &discordgo.MessageEmbedField{
+			Name:   "Target",
+			Value:  leader,
+			Inline: true,
+		})
+		components = append(components, voteMenu)
+	}
 
-	_, err := s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
-		Embeds:     &embeds,
-		Components: &components,
-		Content:    &emptymsg,
-	})
-	if err != nil {
-		return err
+	server.Heist.messageSeq++
+	return &heistMessage{
+		heist:       server.Heist,
+		seq:         server.Heist.messageSeq,
+		interaction: i,
+		messageID:   server.Heist.MessageID,
+		channelID:   server.Heist.ChannelID,
+		embeds:      embeds,
+		components:  components,
+	}
+}
+
+// send sends the heist message, returning the message that was sent. The message isn't sent if a message
+// built after it has already been sent, so the heist message never goes back to an older version. The
+// server's mutex must not be held by the caller.
+func (m *heistMessage) send(s *discordgo.Session) (*discordgo.Message, error) {
+	log.Trace("--> heistMessage.send")
+	defer log.Trace("<-- heistMessage.send")
+
+	m.heist.sendMutex.Lock()
+	defer m.heist.sendMutex.Unlock()
+	if m.seq <= m.heist.sentSeq {
+		return nil, nil
 	}
+	m.heist.sentSeq = m.seq
 
-	return nil
+	emptymsg := ""
+	// A heist resumed after a restart no longer has the interaction used to plan it, so the message is
+	// edited in the channel instead
+	if m.interaction.Token == "" {
+		return s.ChannelMessageEditComplex(&discordgo.MessageEdit{
+			ID:         m.messageID,
+			Channel:    m.channelID,
+			Embeds:     m.embeds,
+			Components: m.components,
+			Content:    &emptymsg,
+		})
+	}
+	return s.InteractionResponseEdit(m.interaction.Interaction, &discordgo.WebhookEdit{
+		Embeds:     &m.embeds,
+		Components: &m.components,
+		Content:    &emptymsg,
+	})
 }
 
 /******** COMMAND ROUTERS ********/
@@ -398,6 +890,10 @@ func admin(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		config(s, i)
 	case "reset":
 		resetHeist(s, i)
+	case "target":
+		manageTarget(s, i)
+	case "targets":
+		manageTargetSet(s, i)
 	case "theme":
 		theme(s, i)
 	}
@@ -412,6 +908,12 @@ func heist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	switch options[0].Name {
 	case "bail":
 		bailoutPlayer(s, i)
+	case "breakout":
+		breakout(s, i)
+	case "history":
+		showHistory(s, i)
+	case "leaderboard":
+		showLeaderboard(s, i)
 	case "start":
 		planHeist(s, i)
 	case "stats":
@@ -429,7 +931,7 @@ func planHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	defer log.Trace("<-- planHeist")
 
 	server := GetServer(servers, i.GuildID)
-	theme := themes[server.Config.Theme]
+	theme := getThemes()[server.Config.Theme]
 	discmsg.SendResponse(s, i, "Starting "+theme.Heist+"...")
 	server.Mutex.Lock()
 	// Heist is already in progress
@@ -451,20 +953,32 @@ func planHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	// Withdraw the cost of the heist from the player's account. We know the player already
 	// as the required number of credits as this is verified in `heistChecks`.
+	server.Heist = NewHeist(server, player)
+	server.Heist.Interaction = i
+	server.Heist.Locale = i.Locale
 	bank := economy.GetBank(server.ID)
 	account := bank.GetAccount(player.ID, player.Name)
-	account.WithdrawCredits(int(server.Config.HeistCost))
+	account.WithdrawCredits(int(server.Heist.Cost))
 	economy.SaveBank(bank)
-
-	server.Heist = NewHeist(server, player)
-	server.Heist.Interaction = i
-	server.Heist.Planned = true
-	err := heistMessage(s, i, "plan")
+	heist := server.Heist
+	planMessage := newHeistMessage(i, "plan")
 	server.Mutex.Unlock()
+
+	message, err := planMessage.send(s)
 	if err != nil {
-		log.Error("Unable to create the `Plan Heist` message, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to create the `Plan Heist` message, error:", err)
 		return
 	}
+	server.Mutex.Lock()
+	if message != nil {
+		heist.MessageID = message.ID
+		heist.ChannelID = message.ChannelID
+	}
+	if heist.isPlanning() {
+		// Save the heist so it can be resumed if the bot restarts while it is being planned
+		store.Store.Save(HEIST, server.ID, server)
+	}
+	server.Mutex.Unlock()
 
 	/*
 		if msg != "" {
@@ -472,16 +986,36 @@ func planHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 	*/
 
-	for !time.Now().After(server.Heist.StartTime) {
-		maximumWait := time.Until(server.Heist.StartTime)
+	waitForHeist(s, i, server, heist)
+}
+
+// waitForHeist updates the heist message until it is time for the heist to start, and then starts the heist.
+// Waiting stops if the heist is canceled.
+func waitForHeist(s *discordgo.Session, i *discordgo.InteractionCreate, server *Server, heist *Heist) {
+	log.Trace("--> waitForHeist")
+	defer log.Trace("<-- waitForHeist")
+
+	for !time.Now().After(heist.StartTime) {
+		maximumWait := time.Until(heist.StartTime)
 		timeToWait := hmath.Min(maximumWait, 5*time.Second)
 		if timeToWait < 0 {
 			break
 		}
-		time.Sleep(timeToWait)
-		err := heistMessage(s, i, "update")
-		if err != nil {
-			log.Error("Unable to update the time for the heist message, error:", err)
+		select {
+		case <-heist.cancel:
+			log.WithField("Server", server.ID).Debug("Stopped waiting for a canceled heist")
+			return
+		case <-time.After(timeToWait):
+		}
+		server.Mutex.Lock()
+		if !heist.isPlanning() {
+			server.Mutex.Unlock()
+			return
+		}
+		updateMessage := newHeistMessage(i, "update")
+		server.Mutex.Unlock()
+		if _, err := updateMessage.send(s); err != nil {
+			logging.WithInteraction(log, i).Error("Unable to update the time for the heist message, error:", err)
 			continue
 		}
 	}
@@ -494,62 +1028,179 @@ func joinHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> joinHeist")
 	defer log.Trace("<-- joinHeist")
 
-	p := getPrinter(i)
-
 	server := GetServer(servers, i.GuildID)
-	theme := themes[server.Config.Theme]
+	theme := getThemes()[server.Config.Theme]
 
 	discmsg.SendEphemeralResponse(s, i, "Joining "+theme.Heist+"...")
 
-	if server.Heist == nil {
-		discmsg.EditResponse(s, i, "No "+theme.Heist+" is planned.")
-		return
+	response, heistMsg := addToCrew(server, i)
+	discmsg.EditResponse(s, i, response)
+	sendHeistMessage(s, heistMsg)
+}
+
+// addToCrew adds the member to the crew of the heist being planned, returning the response for the member and,
+// if the member joined, the updated heist message.
+func addToCrew(server *Server, i *discordgo.InteractionCreate) (string, *heistMessage) {
+	p := getPrinter(i)
+	theme := getThemes()[server.Config.Theme]
+
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+	heist := server.Heist
+	if heist == nil {
+		return "No " + theme.Heist + " is planned.", nil
+	}
+	if !heist.isPlanning() {
+		return "The heist has already been started", nil
 	}
 	player := server.GetPlayer(i.Member.User.ID, i.Member.User.Username, i.Member.Nick)
-	server.Heist.Mutex.Lock()
-	isMember := contains(server.Heist.Crew, player.ID)
-	server.Heist.Mutex.Unlock()
-	if isMember {
-		discmsg.EditResponse(s, i, "You are already a member of the "+theme.Heist+".")
-		return
+	if contains(heist.Crew, player.ID) {
+		return "You are already a member of the " + theme.Heist + ".", nil
 	}
 	msg, ok := heistChecks(server, i, player, server.Targets)
 	if !ok {
-		discmsg.EditResponse(s, i, msg)
-		return
-	}
-	if server.Heist.Started {
-		discmsg.EditResponse(s, i, "The heist has already been started")
-		return
+		return msg, nil
 	}
 
-	server.Heist.Mutex.Lock()
-	server.Heist.Crew = append(server.Heist.Crew, player.ID)
-	server.Heist.Mutex.Unlock()
-	err := heistMessage(s, server.Heist.Interaction, "join")
-	if err != nil {
-		log.Error("Unable to update the heist message, error:", err)
-	}
+	heist.Crew = append(heist.Crew, player.ID)
+	heistMsg := newHeistMessage(heist.Interaction, "join")
 
 	// Withdraw the cost of the heist from the player's account. We know the player already
 	// as the required number of credits as this is verified in `heistChecks`.
 	bank := economy.GetBank(server.ID)
 	account := bank.GetAccount(player.ID, player.Name)
-	account.WithdrawCredits(int(server.Config.HeistCost))
+	account.WithdrawCredits(int(heist.Cost))
 	economy.SaveBank(bank)
+	store.Store.Save(HEIST, server.ID, server)
 
 	if msg != "" {
-		msg := p.Sprintf("%s You have joined the %s at a cost of %d credits.", msg, theme.Heist, server.Config.HeistCost)
-		discmsg.EditResponse(s, i, msg)
-	} else {
-		msg := p.Sprintf("You have joined the %s at a cost of %d credits.", theme.Heist, server.Config.HeistCost)
-		discmsg.EditResponse(s, i, msg)
+		return p.Sprintf("%s You have joined the %s at a cost of %d credits.", msg, theme.Heist, heist.Cost), heistMsg
 	}
+	return p.Sprintf("You have joined the %s at a cost of %d credits.", theme.Heist, heist.Cost), heistMsg
+}
+
+// leaveHeist removes a member from the crew of a heist that is being planned. The cost of the heist is
+// refunded if the server allows it. The planner can't leave the heist, but may cancel it instead.
+func leaveHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> leaveHeist")
+	defer log.Trace("<-- leaveHeist")
+
+	server := GetServer(servers, i.GuildID)
+
+	response, heistMsg := removeFromCrew(server, i)
+	discmsg.SendEphemeralResponse(s, i, response)
+	sendHeistMessage(s, heistMsg)
+}
+
+// removeFromCrew removes the member from the crew of the heist being planned, returning the response for the
+// member and, if the member left, the updated heist message.
+func removeFromCrew(server *Server, i *discordgo.InteractionCreate) (string, *heistMessage) {
+	p := getPrinter(i)
+	theme := getThemes()[server.Config.Theme]
 
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+	heist := server.Heist
+	if !heist.isPlanning() {
+		return "No " + theme.Heist + " is planned.", nil
+	}
+	memberID := i.Member.User.ID
+	if memberID == heist.Planner {
+		return "You are planning the " + theme.Heist + ". Use the Cancel button to call it off.", nil
+	}
+	if !contains(heist.Crew, memberID) {
+		return "You aren't a member of the " + theme.Heist + ".", nil
+	}
+	heist.Crew = remove(heist.Crew, memberID)
+	delete(heist.Votes, memberID)
+
+	response := "You have left the " + theme.Heist + "."
+	if server.Config.LeaveRefund {
+		player := server.Players[memberID]
+		bank := economy.GetBank(server.ID)
+		account := bank.GetAccount(player.ID, player.Name)
+		account.DepositCredits(int(heist.Cost))
+		economy.SaveBank(bank)
+		response = p.Sprintf("You have left the %s and been refunded %d credits.", theme.Heist, heist.Cost)
+	}
+	log.WithFields(logrus.Fields{"Server": server.ID, "Member": memberID, "Refund": server.Config.LeaveRefund}).Debug("Member left the heist")
+
+	heistMsg := newHeistMessage(heist.Interaction, "leave")
+	store.Store.Save(HEIST, server.ID, server)
+	return response, heistMsg
+}
+
+// cancelHeist calls off a heist that is being planned. Only the planner or an admin may cancel the heist.
+// The cost of the heist is refunded to each member of the crew.
+func cancelHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> cancelHeist")
+	defer log.Trace("<-- cancelHeist")
+
+	server := GetServer(servers, i.GuildID)
+
+	response, heistMsg := callOffHeist(server, i)
+	discmsg.SendEphemeralResponse(s, i, response)
+	sendHeistMessage(s, heistMsg)
+}
+
+// callOffHeist cancels the heist being planned, returning the response for the member and, if the heist was
+// canceled, the updated heist message.
+func callOffHeist(server *Server, i *discordgo.InteractionCreate) (string, *heistMessage) {
+	theme := getThemes()[server.Config.Theme]
+
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+	heist := server.Heist
+	if !heist.isPlanning() {
+		return "No " + theme.Heist + " is planned.", nil
+	}
+	if i.Member.User.ID != heist.Planner && !permission.HasPermissions(i, &permission.AdminPermissions) {
+		return "Only the planner or an admin may cancel the " + theme.Heist + ".", nil
+	}
+
+	if err := heist.transition(HeistCanceled); err != nil {
+		logging.WithInteraction(log, i).Error("Unable to cancel the heist, error:", err)
+		return "The " + theme.Heist + " can no longer be canceled.", nil
+	}
+	crewSize := refundCrew(server, heist)
+	log.WithFields(logrus.Fields{"Server": server.ID, "Member": i.Member.User.ID, "Crew": crewSize}).Info("Heist canceled")
+
+	heistMsg := newHeistMessage(heist.Interaction, "cancel")
+	server.Heist = nil
 	store.Store.Save(HEIST, server.ID, server)
+	return "The " + theme.Heist + " has been canceled and the " + theme.Crew + " has been refunded.", heistMsg
 }
 
-// startHeist is called once the wait time for planning the heist completes
+// sendHeistMessage sends the updated heist message, if there is one. The server's mutex must not be held by
+// the caller.
+func sendHeistMessage(s *discordgo.Session, heistMsg *heistMessage) {
+	if heistMsg == nil {
+		return
+	}
+	if _, err := heistMsg.send(s); err != nil {
+		log.Error("Unable to update the heist message, error:", err)
+	}
+}
+
+// refundCrew refunds the cost of the heist to each member of the crew, returning the size of the crew. The
+// server's mutex must be held by the caller.
+func refundCrew(server *Server, heist *Heist) int {
+	bank := economy.GetBank(server.ID)
+	for _, memberID := range heist.Crew {
+		player, ok := server.Players[memberID]
+		if !ok {
+			continue
+		}
+		account := bank.GetAccount(player.ID, player.Name)
+		account.DepositCredits(int(heist.Cost))
+	}
+	economy.SaveBank(bank)
+	return len(heist.Crew)
+}
+
+// startHeist is called once the wait time for planning the heist completes. The server's mutex is only held
+// while the heist is being changed, so it isn't held while the results are being announced. Once the heist is
+// running, members can no longer join, leave or cancel it.
 func startHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> startHeist")
 	defer log.Trace("<-- startHeist")
@@ -557,58 +1208,73 @@ func startHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	p := getPrinter(i)
 
 	server := GetServer(servers, i.GuildID)
-	theme := themes[server.Config.Theme]
+	theme := getThemes()[server.Config.Theme]
 	bank := economy.GetBank(server.ID)
-	if server.Heist == nil {
+
+	server.Mutex.Lock()
+	heist := server.Heist
+	if heist == nil {
+		server.Mutex.Unlock()
 		s.ChannelMessageSend(i.ChannelID, "Error: no heist found.")
-		heistMessage(s, i, "cancel")
 		return
 	}
+	if err := heist.transition(HeistRunning); err != nil {
+		// The heist was canceled while waiting for it to start
+		server.Mutex.Unlock()
+		log.WithField("Server", server.ID).Debug("Unable to start the heist, error:", err)
+		return
+	}
+	// Save the running heist so it is canceled rather than run again if the bot restarts
+	store.Store.Save(HEIST, server.ID, server)
 	if len(server.Targets) == 1 {
+		endHeist(server, heist, HeistCanceled)
+		server.Mutex.Unlock()
 		discmsg.SendEphemeralResponse(s, i, "There are no heist targets.")
-		server.Heist = nil
 		return
 	}
 
-	mute := channel.NewChannelMute(s, i)
-	mute.MuteChannel()
-	defer mute.UnmuteChannel()
-
-	server.Heist.Started = true
-	server.Heist.Planned = false
-
-	err := heistMessage(s, i, "start")
-	if err != nil {
-		log.Error("Unable to mark the heist message as started, error:", err)
-	}
-	if len(server.Heist.Crew) <= 1 {
-		heistMessage(s, i, "ended")
+	if len(heist.Crew) <= 1 {
+		endedMessage := newHeistMessage(i, "ended")
+		endHeist(server, heist, HeistCanceled)
+		server.Mutex.Unlock()
+		sendHeistMessage(s, endedMessage)
 		msg := p.Sprintf("You tried to rally a %s, but no one wanted to follow you. The %s has been cancelled.", theme.Crew, theme.Heist)
 		s.ChannelMessageSend(i.ChannelID, msg)
-		server.Heist = nil
 		return
 	}
+	startMessage := newHeistMessage(i, "start")
+	crewSize := len(heist.Crew)
+	server.Mutex.Unlock()
+	sendHeistMessage(s, startMessage)
+
+	mute := channel.NewChannelMute(s, i)
+	mute.MuteChannel()
+	defer mute.UnmuteChannel()
+
 	log.Debug("Heist is starting")
-	msg := p.Sprintf("Get ready! The %s is starting with %d members.", theme.Heist, len(server.Heist.Crew))
+	msg := p.Sprintf("Get ready! The %s is starting with %d members.", theme.Heist, crewSize)
 	s.ChannelMessageSend(i.ChannelID, msg)
 	time.Sleep(3 * time.Second)
-	heistMessage(s, i, "start")
-	target := getTarget(server.Heist, server.Targets)
+
+	server.Mutex.Lock()
+	if heist.State != HeistRunning {
+		// The heist was reset by an admin
+		server.Mutex.Unlock()
+		return
+	}
+	startMessage = newHeistMessage(i, "start")
+	target := chooseTarget(server)
+	vaultBefore := target.Vault
 	results := getHeistResults(server, target)
+	server.Mutex.Unlock()
+	sendHeistMessage(s, startMessage)
 	log.Debug("Hitting " + target.ID)
 	msg = p.Sprintf("The %s has decided to hit **%s**.", theme.Crew, target.ID)
 	s.ChannelMessageSend(i.ChannelID, msg)
 	time.Sleep(3 * time.Second)
 
 	// Process the results
-	for _, result := range results.memberResults {
-		msg = p.Sprintf(result.message+"\n", "**"+result.player.Name+"**")
-		if result.status == APPREHENDED {
-			msg += p.Sprintf("`%s dropped out of the game.`", result.player.Name)
-		}
-		s.ChannelMessageSend(i.ChannelID, msg)
-		time.Sleep(3 * time.Second)
-	}
+	sendCrewOutcomes(s, i, p, server, results)
 
 	if results.escaped == 0 {
 		msg = "\nNo one made it out safe."
@@ -640,41 +1306,148 @@ func startHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		s.ChannelMessageSend(i.ChannelID, "```\n"+tableBuffer.String()+"```")
 	}
 
+	server.Mutex.Lock()
+	if heist.State != HeistRunning {
+		server.Mutex.Unlock()
+		log.WithField("Server", server.ID).Warning("The heist was reset before the results were paid out")
+		return
+	}
+
+	// The police alert depends on who was in the crew, so get it before the players are updated
+	alert := server.Config.policeAlert(results.memberResults)
+
 	// Update the status for each player and then save the information
+	var penalties []string
+	var promoted []*Player
+	var milestones []*Player
 	for _, result := range results.memberResults {
 		player := result.player
 		if result.status == APPREHENDED || result.status == DEAD {
 			handleHeistFailure(server, player, result)
+			if result.status == DEAD && heist.Hardcore {
+				if lost := applyDeathPenalty(bank, player); lost > 0 {
+					penalties = append(penalties, p.Sprintf("**%s** lost %d credits.", player.Name, lost))
+				}
+			}
 		} else {
 			player.Spree++
+			if server.Config.isMilestone(player.Spree) {
+				milestones = append(milestones, player)
+			}
+			if awardXP(&server.Config, player) {
+				promoted = append(promoted, player)
+			}
 		}
+		recordStats(player, result)
+		player.Fugitive = false
 		if results.escaped > 0 && result.stolenCredits != 0 {
 			account := bank.GetAccount(player.ID, player.Name)
 			account.DepositCredits(result.stolenCredits + result.bonusCredits)
 			target.Vault -= int64(result.stolenCredits)
-			log.WithFields(log.Fields{"Member": account.Name, "Stolen": result.stolenCredits, "Bonus": result.bonusCredits}).Debug("Heist Loot")
+			log.WithFields(logrus.Fields{"Member": account.Name, "Stolen": result.stolenCredits, "Bonus": result.bonusCredits}).Debug("Heist Loot")
 		}
 	}
 	target.Vault = hmath.Max(target.Vault, target.VaultMax*4/100)
+	var announcements []string
+	if len(penalties) != 0 {
+		announcements = append(announcements, "Hardcore mode takes its toll on the fallen:\n"+strings.Join(penalties, "\n"))
+	}
+	if len(promoted) != 0 {
+		announcements = append(announcements, formatLevelUps(p, theme, promoted))
+	}
+	if len(milestones) != 0 {
+		announcements = append(announcements, formatMilestoneReached(p, theme, milestones))
+	}
 
+	endedMessage := newHeistMessage(i, "ended")
+	recordHeist(server, results, vaultBefore)
+
+	// Update the heist status information. The settled heist is saved before the bank, so a restart
+	// can never pay out the heist a second time.
+	server.Config.AlertTime = time.Now().Add(alert)
+	endHeist(server, heist, HeistSettled)
 	economy.SaveBank(bank)
+	server.Mutex.Unlock()
 
-	heistMessage(s, i, "ended")
+	for _, announcement := range announcements {
+		s.ChannelMessageSend(i.ChannelID, announcement)
+	}
+	sendHeistMessage(s, endedMessage)
 
-	// Update the heist status information
-	server.Config.AlertTime = time.Now().Add(server.Config.PoliceAlert)
-	server.Heist = nil
+	publishHeistCompleted(server, results)
+}
+
+// endHeist moves a running heist to its final state and removes it from the server. The server's mutex must
+// be held by the caller.
+func endHeist(server *Server, heist *Heist, state HeistState) {
+	if err := heist.transition(state); err != nil {
+		log.WithField("Server", server.ID).Error("Unable to end the heist, error:", err)
+	}
+	if server.Heist == heist {
+		server.Heist = nil
+	}
 	store.Store.Save(HEIST, server.ID, server)
 }
 
+// publishHeistCompleted publishes the results of the heist.
+func publishHeistCompleted(server *Server, results *HeistResult) {
+	crew := make([]*event.HeistMember, 0, len(results.memberResults))
+	for _, result := range results.memberResults {
+		crew = append(crew, &event.HeistMember{
+			MemberID: result.player.ID,
+			Name:     result.player.Name,
+			Status:   result.status,
+			Stolen:   result.stolenCredits,
+			Bonus:    result.bonusCredits,
+		})
+	}
+	event.Publish(event.HeistCompleted{
+		GuildID:     server.ID,
+		Target:      results.target.ID,
+		Crew:        crew,
+		Escaped:     results.escaped,
+		Apprehended: results.apprehended,
+		Dead:        results.dead,
+		Time:        time.Now(),
+	})
+}
+
 // playerStats shows a player's heist stats
 func playerStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> playerStats")
 	defer log.Trace("<-- playerStats")
 
+	sendPlayerStats(s, i, i.Member)
+}
+
+// playerStatsForMember shows the heist stats for the member selected from the user context menu.
+func playerStatsForMember(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> playerStatsForMember")
+	defer log.Trace("<-- playerStatsForMember")
+
+	target := member.GetTarget(i)
+	if target == nil {
+		discmsg.SendEphemeralResponse(s, i, "The member could not be found.")
+		return
+	}
+	server := GetServer(servers, i.GuildID)
+	if _, ok := server.Players[target.User.ID]; !ok && target.User.ID != i.Member.User.ID {
+		theme := getThemes()[server.Config.Theme]
+		discmsg.SendEphemeralResponse(s, i, member.GetName(target.User.Username, target.Nick)+" has not taken part in a "+theme.Heist+".")
+		return
+	}
+
+	sendPlayerStats(s, i, target)
+}
+
+// sendPlayerStats sends the heist stats for the member.
+func sendPlayerStats(s *discordgo.Session, i *discordgo.InteractionCreate, m *discordgo.Member) {
+	log.Trace("--> sendPlayerStats")
+	defer log.Trace("<-- sendPlayerStats")
+
 	server := GetServer(servers, i.GuildID)
-	theme := themes[server.Config.Theme]
-	player := server.GetPlayer(i.Member.User.ID, i.Member.User.Username, i.Member.Nick)
+	theme := getThemes()[server.Config.Theme]
+	player := server.GetPlayer(m.User.ID, m.User.Username, m.Nick)
 	caser := cases.Caser(cases.Title(language.Und, cases.NoLower))
 
 	p := getPrinter(i)
@@ -693,21 +1466,30 @@ func playerStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	} else {
 		sentence = "None"
 	}
+	status := player.Status
+	if player.Fugitive {
+		status += " (fugitive)"
+	}
 
 	embeds := []*discordgo.MessageEmbed{
 		{
 			Type:        discordgo.EmbedTypeRich,
 			Title:       player.Name,
-			Description: player.CriminalLevel.String(),
+			Description: player.CriminalLevel.Title(),
 			Fields: []*discordgo.MessageEmbedField{
 				{
 					Name:   "Status",
-					Value:  player.Status,
+					Value:  status,
+					Inline: true,
+				},
+				{
+					Name:   "XP",
+					Value:  formatXP(p, &server.Config, player),
 					Inline: true,
 				},
 				{
 					Name:   "Spree",
-					Value:  p.Sprintf("%d", player.Spree),
+					Value:  p.Sprintf("%d (+%d%% loot)", player.Spree, server.Config.spreeBonus(player.Spree)),
 					Inline: true,
 				},
 				{
@@ -745,6 +1527,11 @@ func playerStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
 					Value:  p.Sprintf("%d", account.CurrentBalance),
 					Inline: true,
 				},
+				{
+					Name:   "Rankings",
+					Value:  formatRankings(p, server, player),
+					Inline: false,
+				},
 			},
 		},
 	}
@@ -757,7 +1544,7 @@ func playerStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 	if err != nil {
-		log.Error("Unable to send the player stats to Discord, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to send the player stats to Discord, error:", err)
 	}
 }
 
@@ -767,8 +1554,6 @@ func bailoutPlayer(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> bailoutPlayer")
 	log.Trace("<-- bailoutPlayer")
 
-	p := getPrinter(i)
-
 	var playerID string
 	options := i.ApplicationCommandData().Options[0].Options
 	for _, option := range options {
@@ -777,7 +1562,28 @@ func bailoutPlayer(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		}
 	}
 
+	bailout(s, i, playerID)
+}
+
+// bailoutMember bails the member selected from the user context menu out of jail.
+func bailoutMember(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> bailoutMember")
+	defer log.Trace("<-- bailoutMember")
+
+	bailout(s, i, i.ApplicationCommandData().TargetID)
+}
+
+// bailout bails the player with the given ID out of jail, with the player initiating the command paying
+// the bail. If the ID is empty, the player initiating the command is bailed out.
+func bailout(s *discordgo.Session, i *discordgo.InteractionCreate, playerID string) {
+	log.Trace("--> bailout")
+	defer log.Trace("<-- bailout")
+
+	p := getPrinter(i)
+
 	server := GetServer(servers, i.GuildID)
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
 	initiatingPlayer := server.GetPlayer(i.Member.User.ID, i.Member.User.Username, i.Member.Nick)
 	bank := economy.GetBank(server.ID)
 	account := bank.GetAccount(initiatingPlayer.ID, initiatingPlayer.Name)
@@ -810,6 +1616,11 @@ func bailoutPlayer(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		player.Reset()
 		return
 	}
+	if player.NoBail {
+		theme := getThemes()[server.Config.Theme]
+		discmsg.EditResponse(s, i, "There is no "+theme.Bail+" for a "+theme.Sentence+" from a hardcore "+theme.Heist+".")
+		return
+	}
 	if account.CurrentBalance < int(player.BailCost) {
 		msg := p.Sprintf("You do not have enough credits to play the bail of %d", player.BailCost)
 		discmsg.EditResponse(s, i, msg)
@@ -842,17 +1653,15 @@ func resetHeist(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	defer mute.UnmuteChannel()
 
 	server := GetServer(servers, i.GuildID)
-	theme := themes[server.Config.Theme]
-	if server.Heist == nil {
+	theme := getThemes()[server.Config.Theme]
+	caser := cases.Caser(cases.Title(language.Und, cases.NoLower))
+	crew, err := ResetHeist(s, i.GuildID)
+	if err != nil {
 		discmsg.SendEphemeralResponse(s, i, "No "+theme.Heist+" is being planned.")
 		return
 	}
-
-	heistMessage(s, server.Heist.Interaction, "cancel")
-	server.Heist = nil
 	discmsg.SendResponse(s, i, "The "+theme.Heist+" has been reset.")
-
-	store.Store.Save(HEIST, server.ID, server)
+	audit.Record(s, i, "/heist-admin reset", caser.String(theme.Heist), strings.Join(crew, ", "), nil)
 }
 
 // listTargets displays a list of available heist targets.
@@ -863,7 +1672,7 @@ func listTargets(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	p := getPrinter(i)
 
 	server := GetServer(servers, i.GuildID)
-	theme := themes[server.Config.Theme]
+	theme := getThemes()[server.Config.Theme]
 
 	if len(server.Targets) == 0 {
 		msg := "There aren't any targets!"
@@ -875,6 +1684,13 @@ func listTargets(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	for _, target := range server.Targets {
 		targets = append(targets, target)
 	}
+
+	discmsg.SendEphemeralResponse(s, i, formatTargets(p, theme, targets, false))
+}
+
+// formatTargets returns the targets, sorted by crew size, as a table. If showCustom is `true`, then the table
+// also shows which of the targets are custom targets.
+func formatTargets(p *message.Printer, theme *Theme, targets []*Target, showCustom bool) string {
 	sort.SliceStable(targets, func(i, j int) bool {
 		return targets[i].CrewSize < targets[j].CrewSize
 	})
@@ -895,14 +1711,25 @@ func listTargets(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	table.SetBorder(false)
 	table.SetTablePadding("\t")
 	table.SetNoWhiteSpace(true)
-	table.SetHeader([]string{"ID", "Max Crew", theme.Vault, "Max " + theme.Vault, "Success Rate"})
+	header := []string{"ID", "Max Crew", theme.Vault, "Max " + theme.Vault, "Success Rate", "Level"}
+	if showCustom {
+		header = append(header, "Custom")
+	}
+	table.SetHeader(header)
 	for _, target := range targets {
-		data := []string{target.ID, p.Sprintf("%d", target.CrewSize), p.Sprintf("%d", target.Vault), p.Sprintf("%d", target.VaultMax), p.Sprintf("%.2f", target.Success)}
+		data := []string{target.ID, p.Sprintf("%d", target.CrewSize), p.Sprintf("%d", target.Vault), p.Sprintf("%d", target.VaultMax), p.Sprintf("%.2f", target.Success), p.Sprintf("%d", target.Level)}
+		if showCustom {
+			if target.Custom {
+				data = append(data, "Yes")
+			} else {
+				data = append(data, "No")
+			}
+		}
 		table.Append(data)
 	}
 	table.Render()
 
-	discmsg.SendEphemeralResponse(s, i, "```\n"+tableBuffer.String()+"\n```")
+	return "```\n" + tableBuffer.String() + "\n```"
 }
 
 // clearMember clears the criminal state of the player.
@@ -911,16 +1738,13 @@ func clearMember(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("<-- clearMember")
 
 	memberID := i.ApplicationCommandData().Options[0].Options[0].StringValue()
-	server := GetServer(servers, i.GuildID)
-	player, ok := server.Players[memberID]
-	if !ok {
+	oldPlayer, player, err := ClearPlayer(i.GuildID, memberID)
+	if err != nil {
 		discmsg.SendEphemeralResponse(s, i, "Player \""+memberID+"\" not found.")
 		return
 	}
-	player.Reset()
 	discmsg.SendResponse(s, i, "Player \""+player.Name+"\"'s settings cleared.")
-
-	store.Store.Save(HEIST, server.ID, server)
+	audit.Record(s, i, "/heist-admin clear", player.Name+" ("+player.ID+")", oldPlayer.String(), player.String())
 }
 
 // listThemes returns the list of available themes that may be used for heists
@@ -928,9 +1752,9 @@ func listThemes(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> listThemes")
 	defer log.Trace("<-- listThemes")
 
-	themes, err := GetThemeNames(themes)
+	themes, err := GetThemeNames()
 	if err != nil {
-		log.Warning("Unable to get the themes, error:", err)
+		logging.WithInteraction(log, i).Warning("Unable to get the themes, error:", err)
 	}
 
 	embeds := []*discordgo.MessageEmbed{
@@ -955,7 +1779,7 @@ func listThemes(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 	if err != nil {
-		log.Error("Unable to send list of themes to the user, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to send list of themes to the user, error:", err)
 	}
 }
 
@@ -985,12 +1809,146 @@ func setTheme(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		discmsg.SendEphemeralResponse(s, i, str)
 		return
 	}
-	server.Config.Theme = theme.ID
-	log.Debug("Now using theme ", server.Config.Theme)
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.Theme = theme.ID
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the theme: "+err.Error())
+		return
+	}
+	log.Debug("Now using theme ", theme.ID)
+	audit.Record(s, i, "/heist-admin theme set", "Theme", oldConfig.Theme, theme.ID)
 
 	discmsg.SendResponse(s, i, "Theme "+themeName+" is now being used.")
+}
 
-	store.Store.Save(HEIST, server.ID, server)
+// getTargetOptions returns the target name and the options that were set when adding or editing a target.
+func getTargetOptions(i *discordgo.InteractionCreate) (string, map[string]*discordgo.ApplicationCommandInteractionDataOption) {
+	var name string
+	options := make(map[string]*discordgo.ApplicationCommandInteractionDataOption)
+	for _, option := range i.ApplicationCommandData().Options[0].Options[0].Options {
+		if option.Name == "name" {
+			name = strings.TrimSpace(option.StringValue())
+		} else {
+			options[option.Name] = option
+		}
+	}
+	return name, options
+}
+
+// addCustomTarget adds a custom target for the server.
+func addCustomTarget(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> addCustomTarget")
+	defer log.Trace("<-- addCustomTarget")
+
+	name, options := getTargetOptions(i)
+	target := NewTarget(name, options["crew"].IntValue(), options["success"].FloatValue(), options["vault_max"].IntValue(), options["vault_max"].IntValue())
+	if option, ok := options["vault"]; ok {
+		target.Vault = option.IntValue()
+	}
+	if option, ok := options["level"]; ok {
+		target.Level = CriminalLevel(option.IntValue())
+	}
+	err := AddTarget(i.GuildID, target)
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to add the target: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin target add", "Target "+target.ID, nil, target.String())
+
+	discmsg.SendResponse(s, i, "Target "+target.ID+" has been added.")
+}
+
+// editCustomTarget changes a target for the server.
+func editCustomTarget(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> editCustomTarget")
+	defer log.Trace("<-- editCustomTarget")
+
+	name, options := getTargetOptions(i)
+	if len(options) == 0 {
+		discmsg.SendEphemeralResponse(s, i, "Nothing to change for target "+name+".")
+		return
+	}
+	oldTarget, newTarget, err := EditTarget(i.GuildID, name, func(target *Target) {
+		for _, option := range options {
+			switch option.Name {
+			case "crew":
+				target.CrewSize = option.IntValue()
+			case "success":
+				target.Success = option.FloatValue()
+			case "vault":
+				target.Vault = option.IntValue()
+			case "vault_max":
+				target.VaultMax = option.IntValue()
+			case "level":
+				target.Level = CriminalLevel(option.IntValue())
+			}
+		}
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to change the target: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin target edit", "Target "+newTarget.ID, oldTarget.String(), newTarget.String())
+
+	discmsg.SendResponse(s, i, "Target "+newTarget.ID+" has been changed.")
+}
+
+// removeCustomTarget removes a custom target from the server.
+func removeCustomTarget(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> removeCustomTarget")
+	defer log.Trace("<-- removeCustomTarget")
+
+	name, _ := getTargetOptions(i)
+	target, err := RemoveTarget(i.GuildID, name)
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to remove the target: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin target remove", "Target "+target.ID, target.String(), nil)
+
+	discmsg.SendResponse(s, i, "Target "+target.ID+" has been removed.")
+}
+
+// listServerTargets lists the targets for the server, including which are custom targets.
+func listServerTargets(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> listServerTargets")
+	defer log.Trace("<-- listServerTargets")
+
+	p := getPrinter(i)
+	cfg := GetServerConfig(i.GuildID)
+	theme := getThemes()[cfg.Theme]
+	targets := GetServerTargets(i.GuildID)
+	if len(targets) == 0 {
+		discmsg.SendEphemeralResponse(s, i, "There aren't any targets!")
+		return
+	}
+
+	discmsg.SendEphemeralResponse(s, i, "Using the `"+cfg.Targets+"` targets.\n"+formatTargets(p, theme, targets, true))
+}
+
+// listTargetSets returns the list of sets of targets that may be used by the server.
+func listTargetSets(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> listTargetSets")
+	defer log.Trace("<-- listTargetSets")
+
+	discmsg.SendEphemeralResponse(s, i, "Available sets of targets: "+strings.Join(GetTargetSetNames(), ", "))
+}
+
+// setTargetSet sets the set of targets used by the server.
+func setTargetSet(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> setTargetSet")
+	defer log.Trace("<-- setTargetSet")
+
+	name := strings.TrimSpace(i.ApplicationCommandData().Options[0].Options[0].Options[0].StringValue())
+	oldName, err := SetTargetSet(i.GuildID, name)
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the targets: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin targets set", "Targets", oldName, name)
+
+	discmsg.SendResponse(s, i, "Targets "+name+" are now being used.")
 }
 
 // configCost sets the cost to plan or join a heist
@@ -1000,14 +1958,18 @@ func configCost(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	p := getPrinter(i)
 
-	server := GetServer(servers, i.GuildID)
 	options := i.ApplicationCommandData().Options[0].Options[0].Options
 	cost := options[0].IntValue()
-	server.Config.HeistCost = cost
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.HeistCost = cost
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the cost: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config cost", "Cost", oldConfig.HeistCost, cost)
 
 	discmsg.SendResponse(s, i, p.Sprintf("Cost set to %d", cost))
-
-	store.Store.Save(HEIST, server.ID, server)
 }
 
 // configSentence sets the base aprehension time when a player is apprehended.
@@ -1017,13 +1979,18 @@ func configSentence(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	p := getPrinter(i)
 
-	server := GetServer(servers, i.GuildID)
-	sentence := i.ApplicationCommandData().Options[0].Options[0].IntValue()
-	server.Config.SentenceBase = time.Duration(sentence * int64(time.Second))
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	sentence := options[0].IntValue()
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.SentenceBase = time.Duration(sentence * int64(time.Second))
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the sentence: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config sentence", "Sentence", oldConfig.SentenceBase, time.Duration(sentence*int64(time.Second)))
 
 	discmsg.SendResponse(s, i, p.Sprintf("Sentence set to %d", sentence))
-
-	store.Store.Save(HEIST, server.ID, server)
 }
 
 // configPatrol sets the time authorities will prevent a new heist following one being completed.
@@ -1033,14 +2000,18 @@ func configPatrol(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	p := getPrinter(i)
 
-	server := GetServer(servers, i.GuildID)
 	options := i.ApplicationCommandData().Options[0].Options[0].Options
 	patrol := options[0].IntValue()
-	server.Config.PoliceAlert = time.Duration(patrol * int64(time.Second))
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.PoliceAlert = time.Duration(patrol * int64(time.Second))
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the patrol time: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config patrol", "Patrol", oldConfig.PoliceAlert, time.Duration(patrol*int64(time.Second)))
 
 	discmsg.SendResponse(s, i, p.Sprintf("Patrol set to %d", patrol))
-
-	store.Store.Save(HEIST, server.ID, server)
 }
 
 // configBail sets the base cost of bail.
@@ -1050,14 +2021,18 @@ func configBail(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	p := getPrinter(i)
 
-	server := GetServer(servers, i.GuildID)
 	options := i.ApplicationCommandData().Options[0].Options[0].Options
 	bail := options[0].IntValue()
-	server.Config.BailBase = bail
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.BailBase = bail
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the bail: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config bail", "Bail", oldConfig.BailBase, bail)
 
 	discmsg.SendResponse(s, i, p.Sprintf("Bail set to %d", bail))
-
-	store.Store.Save(HEIST, server.ID, server)
 }
 
 // configDeath sets how long players remain dead.
@@ -1067,14 +2042,18 @@ func configDeath(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	p := getPrinter(i)
 
-	server := GetServer(servers, i.GuildID)
 	options := i.ApplicationCommandData().Options[0].Options[0].Options
 	death := options[0].IntValue()
-	server.Config.PoliceAlert = time.Duration(death * int64(time.Second))
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.DeathTimer = time.Duration(death * int64(time.Second))
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the death time: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config death", "Death", oldConfig.DeathTimer, time.Duration(death*int64(time.Second)))
 
 	discmsg.SendResponse(s, i, p.Sprintf("Death set to %d", death))
-
-	store.Store.Save(HEIST, server.ID, server)
 }
 
 // configWait sets how long players wait for others to join the heist.
@@ -1084,14 +2063,215 @@ func configWait(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	p := getPrinter(i)
 
-	server := GetServer(servers, i.GuildID)
 	options := i.ApplicationCommandData().Options[0].Options[0].Options
 	wait := options[0].IntValue()
-	server.Config.WaitTime = time.Duration(wait * int64(time.Second))
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.WaitTime = time.Duration(wait * int64(time.Second))
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the wait time: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config wait", "Wait", oldConfig.WaitTime, time.Duration(wait*int64(time.Second)))
 
 	discmsg.SendResponse(s, i, p.Sprintf("Wait set to %d", wait))
+}
 
-	store.Store.Save(HEIST, server.ID, server)
+// configHardcore turns hardcore mode on or off. Heists that are already being planned aren't changed.
+func configHardcore(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> configHardcore")
+	defer log.Trace("<-- configHardcore")
+
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	hardcore := options[0].BoolValue()
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.Hardcore = hardcore
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set hardcore mode: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config hardcore", "Hardcore", oldConfig.Hardcore, hardcore)
+
+	if hardcore {
+		discmsg.SendResponse(s, i, "Hardcore mode is on for new heists.")
+	} else {
+		discmsg.SendResponse(s, i, "Hardcore mode is off for new heists.")
+	}
+}
+
+// configRefund sets whether members who leave a heist that is being planned get the cost of the heist back.
+func configRefund(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> configRefund")
+	defer log.Trace("<-- configRefund")
+
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	refund := options[0].BoolValue()
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.LeaveRefund = refund
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the leave refund: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config refund", "LeaveRefund", oldConfig.LeaveRefund, refund)
+
+	if refund {
+		discmsg.SendResponse(s, i, "Members who leave a heist are refunded the cost of the heist.")
+	} else {
+		discmsg.SendResponse(s, i, "Members who leave a heist are not refunded the cost of the heist.")
+	}
+}
+
+// configLevels sets the XP needed for each criminal level and, optionally, the XP earned for each heist.
+func configLevels(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> configLevels")
+	defer log.Trace("<-- configLevels")
+
+	p := getPrinter(i)
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	levelXP := options[0].IntValue()
+	var newConfig Config
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.LevelXP = levelXP
+		if len(options) > 1 {
+			cfg.HeistXP = options[1].IntValue()
+		}
+		newConfig = *cfg
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the criminal levels: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config levels", "Level XP", oldConfig.LevelXP, newConfig.LevelXP)
+	if len(options) > 1 {
+		audit.Record(s, i, "/heist-admin config levels", "Heist XP", oldConfig.HeistXP, newConfig.HeistXP)
+	}
+
+	discmsg.SendResponse(s, i, p.Sprintf("Each criminal level now takes %d XP, and each heist earns %d XP.", newConfig.LevelXP, newConfig.HeistXP))
+}
+
+// configSpree sets the rewards and risks for players on a spree.
+func configSpree(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> configSpree")
+	defer log.Trace("<-- configSpree")
+
+	p := getPrinter(i)
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	if len(options) == 0 {
+		discmsg.SendEphemeralResponse(s, i, "Nothing to change for the spree rewards.")
+		return
+	}
+	var milestones []int64
+	for _, option := range options {
+		if option.Name == "milestones" {
+			var err error
+			milestones, err = ParseMilestones(option.StringValue())
+			if err != nil {
+				discmsg.SendEphemeralResponse(s, i, "Unable to set the spree rewards: "+err.Error())
+				return
+			}
+		}
+	}
+
+	var newConfig Config
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		for _, option := range options {
+			switch option.Name {
+			case "bonus":
+				cfg.SpreeBonus = option.IntValue()
+			case "cap":
+				cfg.SpreeCap = option.IntValue()
+			case "penalty":
+				cfg.SpreePenalty = option.IntValue()
+			case "milestones":
+				cfg.SpreeMilestones = milestones
+			}
+		}
+		newConfig = *cfg
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the spree rewards: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config spree", "Spree", formatSpree(p, oldConfig), formatSpree(p, &newConfig))
+
+	discmsg.SendResponse(s, i, "Spree rewards set to "+formatSpree(p, &newConfig))
+}
+
+// configBreakout sets the chance of breaking out of jail and the consequences of a breakout.
+func configBreakout(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> configBreakout")
+	defer log.Trace("<-- configBreakout")
+
+	p := getPrinter(i)
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	if len(options) == 0 {
+		discmsg.SendEphemeralResponse(s, i, "Nothing to change for breakouts.")
+		return
+	}
+
+	var newConfig Config
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		for _, option := range options {
+			switch option.Name {
+			case "chance":
+				cfg.BreakoutChance = option.IntValue()
+			case "alert":
+				cfg.BreakoutAlert = option.IntValue()
+			case "penalty":
+				cfg.BreakoutPenalty = option.IntValue()
+			case "bail":
+				cfg.BreakoutBail = option.IntValue()
+			}
+		}
+		newConfig = *cfg
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the breakout settings: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config breakout", "Breakout", formatBreakout(p, oldConfig), formatBreakout(p, &newConfig))
+
+	discmsg.SendResponse(s, i, "Breakouts set to "+formatBreakout(p, &newConfig))
+}
+
+// configOutput sets how the outcome for each member of the crew is shown.
+func configOutput(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> configOutput")
+	defer log.Trace("<-- configOutput")
+
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	mode := options[0].StringValue()
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.CrewOutput = mode
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the crew output: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config output", "Crew Output", oldConfig.crewOutputMode(), mode)
+
+	discmsg.SendResponse(s, i, "Crew output set to "+mode)
+}
+
+// configVote sets how the target for a heist is picked.
+func configVote(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> configVote")
+	defer log.Trace("<-- configVote")
+
+	options := i.ApplicationCommandData().Options[0].Options[0].Options
+	mode := options[0].StringValue()
+	oldConfig, err := UpdateConfig(i.GuildID, func(cfg *Config) {
+		cfg.TargetVote = mode
+	})
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the target vote: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config vote", "Target Vote", oldConfig.targetVoteMode(), mode)
+
+	discmsg.SendResponse(s, i, "Target vote set to "+mode)
 }
 
 // configPayday sets how many credits a player gets for a playday. This is kinda a hack as
@@ -1102,14 +2282,17 @@ func configPayday(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	p := getPrinter(i)
 
-	server := GetServer(servers, i.GuildID)
 	options := i.ApplicationCommandData().Options[0].Options[0].Options
 	amount := options[0].IntValue()
-	payday.SetPaydayAmount(server.ID, amount)
+	oldAmount := payday.GetPaydayAmount(i.GuildID)
+	err := payday.SetPaydayAmount(i.GuildID, amount)
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to set the payday: "+err.Error())
+		return
+	}
+	audit.Record(s, i, "/heist-admin config payday", "Payday", oldAmount, amount)
 
 	discmsg.SendResponse(s, i, p.Sprintf("Payday is set to %d", amount))
-
-	store.Store.Save(HEIST, server.ID, server)
 }
 
 // configInfo returns the configuration for the Heist bot on this server.
@@ -1138,6 +2321,41 @@ func configInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 				Value:  p.Sprintf("%.f", server.Config.DeathTimer.Seconds()),
 				Inline: true,
 			},
+			{
+				Name:   "hardcore",
+				Value:  strconv.FormatBool(server.Config.Hardcore),
+				Inline: true,
+			},
+			{
+				Name:   "leave refund",
+				Value:  strconv.FormatBool(server.Config.LeaveRefund),
+				Inline: true,
+			},
+			{
+				Name:   "level xp",
+				Value:  p.Sprintf("%d", server.Config.levelXP()),
+				Inline: true,
+			},
+			{
+				Name:   "heist xp",
+				Value:  p.Sprintf("%d", server.Config.heistXP()),
+				Inline: true,
+			},
+			{
+				Name:   "spree",
+				Value:  formatSpree(p, &server.Config),
+				Inline: false,
+			},
+			{
+				Name:   "breakout",
+				Value:  formatBreakout(p, &server.Config),
+				Inline: false,
+			},
+			{
+				Name:   "output",
+				Value:  server.Config.crewOutputMode(),
+				Inline: true,
+			},
 			{
 				Name:   "patrol",
 				Value:  p.Sprintf("%.f", server.Config.PoliceAlert.Seconds()),
@@ -1153,6 +2371,11 @@ func configInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 				Value:  p.Sprintf("%.f", server.Config.SentenceBase.Seconds()),
 				Inline: true,
 			},
+			{
+				Name:   "vote",
+				Value:  server.Config.targetVoteMode(),
+				Inline: true,
+			},
 			{
 				Name:   "wait",
 				Value:  p.Sprintf("%.f", server.Config.WaitTime.Seconds()),
@@ -1172,23 +2395,57 @@ func configInfo(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 	if err != nil {
-		log.Error("Unable to send a response, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to send a response, error:", err)
 	}
 }
 
 // Start initializes anything needed by the heist bot.
 func Start(s *discordgo.Session) {
-	targetSet = LoadTargets()
+	setCatalog(LoadThemes(), LoadTargets())
+	defaultTheme := botconfig.Get().Heist.DefaultTheme
+	if _, err := GetTheme(defaultTheme); err != nil {
+		log.Fatalf("The default heist theme `%s` does not exist; set heist.default_theme to an existing theme", defaultTheme)
+	}
+	if _, err := GetTargets(defaultTheme); err != nil {
+		log.Fatalf("The targets for the default heist theme `%s` do not exist; set heist.default_theme to an existing theme", defaultTheme)
+	}
 	servers = LoadServers()
-	themes = LoadThemes()
+	loadHistories()
+
+	err := scheduler.Add(scheduler.Job{
+		Name:     "heist-resume",
+		Schedule: scheduler.At(time.Now()),
+		Run:      resumeHeists,
+	})
+	if err != nil {
+		log.Error("Unable to schedule resuming the planned heists, error:", err)
+	}
 
-	go vaultUpdater()
+	err = scheduler.Add(scheduler.Job{
+		Name:     "heist-vaults",
+		Schedule: scheduler.Every(1 * time.Minute),
+		Jitter:   5 * time.Second,
+		Run:      updateVaults,
+	})
+	if err != nil {
+		log.Error("Unable to schedule the vault updates, error:", err)
+	}
+	err = scheduler.Add(scheduler.Job{
+		Name:       "heist-monthly-reset",
+		Schedule:   scheduler.MustCron("0 0 1 * *"),
+		RunOnStart: true,
+		Run:        resetMonthlyStats,
+	})
+	if err != nil {
+		log.Error("Unable to schedule the monthly heist stats reset, error:", err)
+	}
 }
 
 // GetCommands ret urns the component handlers, command handlers, and commands for the Heist bot.
 func GetCommands() (map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), []*discordgo.ApplicationCommand) {
-	commands := make([]*discordgo.ApplicationCommand, 0, len(adminCommands)+len(playerCommands))
+	commands := make([]*discordgo.ApplicationCommand, 0, len(adminCommands)+len(playerCommands)+len(userCommands))
 	commands = append(commands, adminCommands...)
 	commands = append(commands, playerCommands...)
+	commands = append(commands, userCommands...)
 	return componentHandlers, commandHandlers, commands
 }
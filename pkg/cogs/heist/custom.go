@@ -0,0 +1,137 @@
+package heist
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/rbrabson/heist/pkg/store"
+)
+
+// GetTargetSetNames returns the names of the sets of targets that may be used by a server.
+func GetTargetSetNames() []string {
+	var names []string
+	for name := range getTargetSet() {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// SetTargetSet changes the set of targets used by the server. The custom targets for the server are kept.
+// The name of the set of targets used before the change is returned.
+func SetTargetSet(guildID string, name string) (string, error) {
+	log.Trace("--> SetTargetSet")
+	defer log.Trace("<-- SetTargetSet")
+
+	targets, err := GetTargets(name)
+	if err != nil {
+		return "", err
+	}
+	oldConfig, err := UpdateConfig(guildID, func(cfg *Config) {
+		cfg.Targets = targets.ID
+	})
+	if err != nil {
+		return "", err
+	}
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	server.applyTargets(targets)
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	return oldConfig.Targets, nil
+}
+
+// AddTarget adds a custom target to the server.
+func AddTarget(guildID string, target *Target) error {
+	log.Trace("--> AddTarget")
+	defer log.Trace("<-- AddTarget")
+
+	err := target.Validate()
+	if len(target.ID) > maxTargetName {
+		err = errors.Join(err, errors.New("the name must be at most 32 characters"))
+	}
+	if err != nil {
+		return err
+	}
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	if _, ok := server.Targets[target.ID]; ok {
+		server.Mutex.Unlock()
+		return ErrTargetExists
+	}
+	newTarget := *target
+	newTarget.Custom = true
+	server.Targets[newTarget.ID] = &newTarget
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	return nil
+}
+
+// EditTarget changes a target on the server. The changes are made to a copy of the target, which is only
+// used if it is valid. A target from the server's set of targets becomes a custom target once it has been
+// changed. The target before and after the change is returned.
+func EditTarget(guildID string, targetID string, update func(target *Target)) (*Target, *Target, error) {
+	log.Trace("--> EditTarget")
+	defer log.Trace("<-- EditTarget")
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	target, ok := server.Targets[targetID]
+	if !ok {
+		server.Mutex.Unlock()
+		return nil, nil, ErrNoTarget
+	}
+	oldTarget := *target
+	newTarget := *target
+	update(&newTarget)
+	newTarget.ID = oldTarget.ID
+	newTarget.Custom = true
+	err := newTarget.Validate()
+	if err != nil {
+		server.Mutex.Unlock()
+		return nil, nil, err
+	}
+	*target = newTarget
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	return &oldTarget, &newTarget, nil
+}
+
+// RemoveTarget removes a custom target from the server. If the custom target replaced a target from the
+// server's set of targets, then that target is used again. The removed target is returned.
+func RemoveTarget(guildID string, targetID string) (*Target, error) {
+	log.Trace("--> RemoveTarget")
+	defer log.Trace("<-- RemoveTarget")
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	target, ok := server.Targets[targetID]
+	if !ok {
+		server.Mutex.Unlock()
+		return nil, ErrNoTarget
+	}
+	if !target.Custom {
+		server.Mutex.Unlock()
+		return nil, ErrNotCustom
+	}
+	delete(server.Targets, targetID)
+	if targets, err := GetTargets(server.Config.Targets); err == nil {
+		server.applyTargets(targets)
+	}
+	if len(server.Targets) == 0 {
+		server.Targets[targetID] = target
+		server.Mutex.Unlock()
+		return nil, ErrLastTarget
+	}
+	removed := *target
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	return &removed, nil
+}
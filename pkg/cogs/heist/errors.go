@@ -6,4 +6,13 @@ var (
 	ErrConfigNotFound = errors.New("configuration file not found")
 	ErrNotAllowed     = errors.New("user is not allowed to perform command")
 	ErrNoHeist        = errors.New("no heist could be found")
+	ErrNoPlayer       = errors.New("player not found")
+	ErrNoTarget       = errors.New("target not found")
+	ErrTargetExists   = errors.New("target already exists")
+	ErrNotCustom      = errors.New("only custom targets may be removed")
+	ErrLastTarget     = errors.New("the last target may not be removed")
+	ErrInvalidBoard   = errors.New("the leaderboard does not exist")
+	ErrInvalidPeriod  = errors.New("the period must be monthly or lifetime")
+
+	ErrInvalidTransition = errors.New("the heist can't change state")
 )
@@ -0,0 +1,46 @@
+package heist
+
+import (
+	"time"
+
+	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	hardcoreDeathPenalty = 25      // Percentage of a player's balance that is lost when they die in hardcore mode
+	maxHardcoreCount     = 1 << 16 // Number of apprehensions above which a hardcore sentence is always the maximum
+)
+
+// getSentence returns the sentence for a player who is apprehended. The sentence grows with each time the
+// player has been apprehended. In hardcore mode, it grows with the square of that number, up to a week.
+func getSentence(cfg *Config, player *Player, hardcore bool) time.Duration {
+	count := player.JailCounter + 1
+	if !hardcore {
+		return cfg.SentenceBase * time.Duration(count)
+	}
+	if cfg.SentenceBase <= 0 {
+		return 0
+	}
+	if count > maxHardcoreCount || count*count > int64(maxDuration/cfg.SentenceBase) {
+		return maxDuration
+	}
+	return cfg.SentenceBase * time.Duration(count*count)
+}
+
+// applyDeathPenalty withdraws a share of the balance of a player who died in hardcore mode. The number
+// of credits that were lost is returned.
+func applyDeathPenalty(bank *economy.Bank, player *Player) int {
+	account := bank.GetAccount(player.ID, player.Name)
+	penalty := account.CurrentBalance * hardcoreDeathPenalty / 100
+	if penalty <= 0 {
+		return 0
+	}
+	err := account.WithdrawCredits(penalty)
+	if err != nil {
+		log.WithField("Player", player.Name).Warning("Unable to apply the hardcore death penalty, error:", err)
+		return 0
+	}
+	log.WithFields(logrus.Fields{"Player": player.Name, "Penalty": penalty}).Debug("Hardcore death penalty")
+	return penalty
+}
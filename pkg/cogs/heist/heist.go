@@ -1,18 +1,21 @@
 package heist
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"math/rand"
-	"sort"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/event"
 	"github.com/rbrabson/heist/pkg/format"
+	"github.com/rbrabson/heist/pkg/help"
 	hmath "github.com/rbrabson/heist/pkg/math"
+	"github.com/rbrabson/heist/pkg/shard"
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -40,12 +43,13 @@ type HeistMemberResult struct {
 	bonusCredits  int
 }
 
-// heistChecks returns an error, with appropriate message, if a heist cannot be started.
+// heistChecks returns an error, with appropriate message, if a heist cannot be started. A player whose jail
+// or death time is over is freed. The server's mutex must be held by the caller.
 func heistChecks(server *Server, i *discordgo.InteractionCreate, player *Player, targets map[string]*Target) (string, bool) {
 
 	p := getPrinter(i)
 
-	theme := themes[server.Config.Theme]
+	theme := getThemes()[server.Config.Theme]
 	bank := economy.GetBank(server.ID)
 
 	if len(targets) == 0 {
@@ -53,19 +57,13 @@ func heistChecks(server *Server, i *discordgo.InteractionCreate, player *Player,
 		return msg, false
 	}
 	log.Debug("Heist:", server.Heist)
-	var isMember bool
-	if server.Heist != nil {
-		server.Heist.Mutex.Lock()
-		isMember = contains(server.Heist.Crew, player.ID)
-		server.Heist.Mutex.Unlock()
-	}
-	if server.Heist != nil && isMember {
+	if server.Heist != nil && contains(server.Heist.Crew, player.ID) {
 		msg := fmt.Sprintf("You are already in the %s.", theme.Crew)
 		return msg, false
 	}
 	account := bank.GetAccount(player.ID, player.Name)
-	if account.CurrentBalance < int(server.Config.HeistCost) {
-		msg := p.Sprintf("You do not have enough credits to cover the cost of entry. You need %d credits to participate", server.Config.HeistCost)
+	if cost := server.entryCost(); account.CurrentBalance < int(cost) {
+		msg := p.Sprintf("You do not have enough credits to cover the cost of entry. You need %d credits to participate", cost)
 		return msg, false
 	}
 	if server.Config.AlertTime.After(time.Now()) {
@@ -82,6 +80,12 @@ func heistChecks(server *Server, i *discordgo.InteractionCreate, player *Player,
 			}
 			return "", true
 		}
+		if player.JailTimer.After(time.Now()) && player.NoBail {
+			remainingTime := time.Until(player.JailTimer)
+			msg := fmt.Sprintf("You are in %s. You are serving a %s of %s.\nYou must wait out your remaining %s of %s, as there is no %s in hardcore mode.",
+				theme.Jail, theme.Sentence, format.Duration(player.Sentence), theme.Sentence, format.Duration(remainingTime), theme.Bail)
+			return msg, false
+		}
 		if player.JailTimer.After(time.Now()) {
 			remainingTime := time.Until(player.JailTimer)
 			msg := fmt.Sprintf("You are in %s. You are serving a %s of %s.\nYou can wait out your remaining %s of %s, or pay %d credits to be released on %s.",
@@ -107,7 +111,7 @@ func heistChecks(server *Server, i *discordgo.InteractionCreate, player *Player,
 }
 
 // calculateCredits determines the number of credits stolen by each surviving crew member.
-func calculateCredits(results *HeistResult) {
+func calculateCredits(cfg *Config, results *HeistResult) {
 	log.Trace("--> calculateCredits")
 	defer log.Trace("<-- calculateCredits")
 
@@ -120,10 +124,12 @@ func calculateCredits(results *HeistResult) {
 	baseStolen := totalStolen / (2*results.escaped + results.apprehended)
 
 	// Caculate a "base amount". Those who escape get 2x those who don't. So Divide the
-	log.WithFields(log.Fields{"Target": results.target.ID, "Vault": results.target.Vault, "Survivors": numSurvived, "Base Credits": baseStolen}).Debug("Looted")
+	log.WithFields(logrus.Fields{"Target": results.target.ID, "Vault": results.target.Vault, "Survivors": numSurvived, "Base Credits": baseStolen}).Debug("Looted")
 	for _, player := range results.survivingCrew {
 		if player.status == FREE {
 			player.stolenCredits = 2 * baseStolen
+			player.bonusCredits += player.stolenCredits * player.player.CriminalLevel.lootBonus() / 100
+			player.bonusCredits += player.stolenCredits * cfg.spreeBonus(player.player.Spree) / 100
 		} else {
 			player.stolenCredits = baseStolen
 		}
@@ -132,26 +138,30 @@ func calculateCredits(results *HeistResult) {
 
 // calculateBonusRate calculates the bonus amount to add to the success rate
 // for a heist. The closer you are to the maximum crew size, the larger
-// the bonus amount.
+// the bonus amount. The bonus is halved in hardcore mode.
 func calculateBonusRate(heist *Heist, target *Target) int {
 	log.Trace("--> calculateBonus")
 	defer log.Trace("<-- calculateBonus")
 
 	percent := 100 * int64(len(heist.Crew)) / target.CrewSize
 	log.WithField("Percent", percent).Debug("Percentage for calculating success bonus")
-	if percent <= 20 {
-		return 0
-	}
-	if percent <= 40 {
-		return 1
-	}
-	if percent <= 60 {
-		return 3
+	var bonus int
+	switch {
+	case percent <= 20:
+		bonus = 0
+	case percent <= 40:
+		bonus = 1
+	case percent <= 60:
+		bonus = 3
+	case percent <= 80:
+		bonus = 4
+	default:
+		bonus = 5
 	}
-	if percent <= 80 {
-		return 4
+	if heist.Hardcore {
+		bonus /= 2
 	}
-	return 5
+	return bonus
 }
 
 // calculateSuccessRate returns the liklihood of a successful raid for each
@@ -162,7 +172,7 @@ func calculateSuccessRate(heist *Heist, target *Target) int {
 
 	bonus := calculateBonusRate(heist, target)
 	successChance := int(math.Round(target.Success)) + bonus
-	log.WithFields(log.Fields{"BonusRate": bonus, "TargetSuccess": math.Round(target.Success), "SuccessChance": successChance}).Debug("Success Rate")
+	log.WithFields(logrus.Fields{"BonusRate": bonus, "TargetSuccess": math.Round(target.Success), "SuccessChance": successChance}).Debug("Success Rate")
 	return successChance
 }
 
@@ -172,22 +182,28 @@ func handleHeistFailure(server *Server, player *Player, result *HeistMemberResul
 	defer log.Trace("<-- handleHeistFailure")
 
 	if result.status == APPREHENDED {
-		sentence := int64(server.Config.SentenceBase) * (player.JailCounter + 1)
+		hardcore := server.Heist != nil && server.Heist.Hardcore
+		sentence := getSentence(&server.Config, player, hardcore)
 		bail := server.Config.BailBase
-		if player.OOB {
+		// Players who are caught while out on bail or on the run have a bounty on their head
+		if player.OOB || player.Fugitive {
 			bail *= 3
 		}
+		if hardcore {
+			bail = 0
+		}
 		player.BailCost = bail
-		player.CriminalLevel++
+		player.NoBail = hardcore
 		player.JailCounter++
 		player.TotalJail++
 		player.OOB = false
-		player.Sentence = time.Duration(sentence)
+		player.BreakoutTried = false
+		player.Sentence = sentence
 		player.JailTimer = time.Now().Add(player.Sentence)
 		player.Spree = 0
 		player.Status = APPREHENDED
 
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"player":        player.Name,
 			"bail":          player.BailCost,
 			"criminalLevel": player.CriminalLevel,
@@ -200,24 +216,34 @@ func handleHeistFailure(server *Server, player *Player, result *HeistMemberResul
 			"totalJail":     player.TotalJail,
 		}).Debug("Apprehended")
 
+		event.Publish(event.PlayerJailed{
+			GuildID:  server.ID,
+			MemberID: player.ID,
+			Name:     player.Name,
+			Sentence: player.Sentence,
+			BailCost: player.BailCost,
+		})
+
 		return
 	}
 
 	player.BailCost = 0
-	player.CriminalLevel = 0
+	forfeitXP(&server.Config, player, server.Heist != nil && server.Heist.Hardcore)
 	player.DeathTimer = time.Now().Add(server.Config.DeathTimer)
 	player.JailCounter = 0
 	player.JailTimer = time.Time{}
+	player.NoBail = false
 	player.OOB = false
 	player.Sentence = 0
 	player.Spree = 0
 	player.Status = DEAD
 	player.Deaths++
 
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"player":        player.Name,
 		"bail":          player.BailCost,
 		"criminalLevel": player.CriminalLevel,
+		"xp":            player.XP,
 		"deathTimer":    player.DeathTimer,
 		"totalDeaths":   player.Deaths,
 		"jailTimer":     player.JailTimer,
@@ -240,7 +266,7 @@ func getHeistResults(server *Server, target *Target) *HeistResult {
 	results.memberResults = make([]*HeistMemberResult, 0, len(server.Heist.Crew))
 	results.survivingCrew = make([]*HeistMemberResult, 0, len(server.Heist.Crew))
 
-	theme := themes[server.Config.Theme]
+	theme := getThemes()[server.Config.Theme]
 	goodResults := theme.Good
 	badResults := theme.Bad
 	successRate := calculateSuccessRate(server.Heist, target)
@@ -248,8 +274,9 @@ func getHeistResults(server *Server, target *Target) *HeistResult {
 	for _, playerID := range server.Heist.Crew {
 		player := server.Players[playerID]
 		chance := rand.Intn(100) + 1
-		log.WithFields(log.Fields{"Player": player.Name, "Chance": chance, "SuccessRate": successRate}).Debug("Heist Results")
-		if chance <= successRate {
+		playerRate := successRate + player.CriminalLevel.successBonus() - server.Config.spreePenalty(player.Spree)
+		log.WithFields(logrus.Fields{"Player": player.Name, "Chance": chance, "SuccessRate": playerRate}).Debug("Heist Results")
+		if chance <= playerRate {
 			index := rand.Intn(len(goodResults))
 			goodResult := goodResults[index]
 			updatedResults := make([]GoodMessage, 0, len(goodResults))
@@ -297,9 +324,9 @@ func getHeistResults(server *Server, target *Target) *HeistResult {
 	// If at least one member escaped, then calculate the credits to distributed.
 	// Also, if no one member escaped, then set the surviving crew to nil so the
 	// "No one made it out alive" message is sent.
-	log.WithFields(log.Fields{"Escaped": results.escaped, "Apprehended": results.apprehended, "Dead": results.dead}).Debug("Heist Results")
+	log.WithFields(logrus.Fields{"Escaped": results.escaped, "Apprehended": results.apprehended, "Dead": results.dead}).Debug("Heist Results")
 	if results.escaped > 0 {
-		calculateCredits(results)
+		calculateCredits(&server.Config, results)
 	} else {
 		results.survivingCrew = nil
 	}
@@ -315,71 +342,146 @@ func getTarget(heist *Heist, targets map[string]*Target) *Target {
 	defer log.Trace("<-- getTarget")
 
 	crewSize := int64(len(heist.Crew))
-	var target *Target
+	var target, largest *Target
 	for _, possible := range targets {
 		if possible.CrewSize >= crewSize {
 			if target == nil || target.CrewSize > possible.CrewSize {
 				target = possible
 			}
 		}
+		if largest == nil || largest.CrewSize < possible.CrewSize {
+			largest = possible
+		}
+	}
+	if target == nil {
+		target = largest
 	}
 	log.WithField("Target", target.ID).Debug("Heist Target")
 	return target
 }
 
-// vaultUpdater updates the vault periodically so each vault will, over time, recover its credits after being
-// hit by a raid.
-func vaultUpdater() {
-	const timer = time.Duration(1 * time.Minute)
-	time.Sleep(20 * time.Second)
-	for {
-		for _, server := range servers {
-			save := false
-			for _, target := range server.Targets {
-				vault := hmath.Min(target.Vault+(target.VaultMax*4/100), target.VaultMax)
-				if vault != target.Vault {
-					log.WithFields(log.Fields{"Target": target.ID, "Old": target.Vault, "New": vault, "Max": target.VaultMax}).Debug("Updating Vault")
-					target.Vault = vault
-					save = true
-				}
-			}
-			if save {
-				store.Store.Save(HEIST, server.ID, server)
+// updateVaults updates the vaults so each vault will, over time, recover its credits after being
+// hit by a raid. Only the servers handled by the shards run by this process are updated.
+func updateVaults(ctx context.Context) error {
+	for _, server := range servers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !shard.Owns(server.ID) {
+			continue
+		}
+		server.Mutex.Lock()
+		save := false
+		for _, target := range server.Targets {
+			vault := hmath.Min(target.Vault+(target.VaultMax*4/100), target.VaultMax)
+			if vault != target.Vault {
+				log.WithFields(logrus.Fields{"Target": target.ID, "Old": target.Vault, "New": vault, "Max": target.VaultMax}).Debug("Updating Vault")
+				target.Vault = vault
+				save = true
 			}
-			save = false
 		}
-		time.Sleep(timer)
-	}
-}
-
-// GetMemberHelp returns help information about the heist bot commands for regular members.
-func GetMemberHelp() []string {
-	help := make([]string, 0, len(playerCommands[0].Options))
-
-	for _, subcommand := range playerCommands[0].Options {
-		commandDescription := fmt.Sprintf("- **/heist %s**:  %s\n", subcommand.Name, subcommand.Description)
-		help = append(help, commandDescription)
+		if save {
+			store.Store.Save(HEIST, server.ID, server)
+		}
+		server.Mutex.Unlock()
 	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Heist**\n"}, help...)
-
-	return help
+	return nil
 }
 
-// GetAdminHelp returns help information about the heist bot for administrators.
-func GetAdminHelp() []string {
-	help := make([]string, 0, len(adminCommands[0].Options))
-
-	for _, command := range adminCommands[0].Options {
-		commandDescription := fmt.Sprintf("- **/heist-admin %s**:  %s\n", command.Name, command.Description)
-		help = append(help, commandDescription)
+// GetHelp returns help information about the heist commands.
+func GetHelp() *help.Topic {
+	commands := make([]*discordgo.ApplicationCommand, 0, len(playerCommands)+len(userCommands)+len(adminCommands))
+	commands = append(commands, playerCommands...)
+	commands = append(commands, userCommands...)
+	commands = append(commands, adminCommands...)
+
+	return &help.Topic{
+		Name:        "Heist",
+		Description: "Plan heists with a crew to steal credits from the targets on the server.",
+		Commands:    commands,
+		Details: map[string]*help.Detail{
+			"heist history": {
+				Defaults: map[string]string{"id": "all players"},
+				Examples: []string{"/heist history", "/heist history id:123456789012345678", "/heist history heist:42"},
+			},
+			"heist leaderboard": {
+				Defaults: map[string]string{"board": "loot", "period": "monthly"},
+				Examples: []string{"/heist leaderboard", "/heist leaderboard board:spree period:lifetime"},
+			},
+			"heist bail": {
+				Examples: []string{"/heist bail", "/heist bail id:123456789012345678"},
+			},
+			"heist breakout": {
+				Defaults: map[string]string{"id": "you"},
+				Examples: []string{"/heist breakout", "/heist breakout id:123456789012345678"},
+			},
+			"heist start": {
+				Examples: []string{"/heist start"},
+			},
+			"heist-admin clear": {
+				Examples: []string{"/heist-admin clear id:123456789012345678"},
+			},
+			"heist-admin config bail": {
+				Examples: []string{"/heist-admin config bail amount:500"},
+			},
+			"heist-admin config breakout": {
+				Defaults: map[string]string{"chance": "unchanged", "alert": "unchanged", "penalty": "unchanged", "bail": "unchanged"},
+				Examples: []string{"/heist-admin config breakout chance:25", "/heist-admin config breakout alert:50 penalty:50 bail:50"},
+			},
+			"heist-admin config cost": {
+				Examples: []string{"/heist-admin config cost amount:1500"},
+			},
+			"heist-admin config death": {
+				Examples: []string{"/heist-admin config death time:45"},
+			},
+			"heist-admin config hardcore": {
+				Examples: []string{"/heist-admin config hardcore enabled:true"},
+			},
+			"heist-admin config levels": {
+				Defaults: map[string]string{"reward": "unchanged"},
+				Examples: []string{"/heist-admin config levels xp:100", "/heist-admin config levels xp:150 reward:30"},
+			},
+			"heist-admin config spree": {
+				Defaults: map[string]string{"bonus": "unchanged", "cap": "unchanged", "penalty": "unchanged", "milestones": "unchanged"},
+				Examples: []string{"/heist-admin config spree bonus:10 cap:50", "/heist-admin config spree penalty:2 milestones:3,5,10,25", "/heist-admin config spree milestones:none"},
+			},
+			"heist-admin config output": {
+				Examples: []string{"/heist-admin config output mode:condensed"},
+			},
+			"heist-admin config patrol": {
+				Examples: []string{"/heist-admin config patrol time:60"},
+			},
+			"heist-admin config payday": {
+				Examples: []string{"/heist-admin config payday amount:5000"},
+			},
+			"heist-admin config refund": {
+				Examples: []string{"/heist-admin config refund enabled:true"},
+			},
+			"heist-admin config sentence": {
+				Examples: []string{"/heist-admin config sentence time:300"},
+			},
+			"heist-admin config vote": {
+				Examples: []string{"/heist-admin config vote mode:crew"},
+			},
+			"heist-admin config wait": {
+				Examples: []string{"/heist-admin config wait time:60"},
+			},
+			"heist-admin target add": {
+				Defaults: map[string]string{"vault": "the vault maximum", "level": "0"},
+				Examples: []string{"/heist-admin target add name:Corner Store crew:2 success:60 vault_max:5000"},
+			},
+			"heist-admin target edit": {
+				Examples: []string{"/heist-admin target edit name:Corner Store success:55.5", "/heist-admin target edit name:Corner Store vault:2500 vault_max:7500", "/heist-admin target edit name:Corner Store level:10"},
+			},
+			"heist-admin target remove": {
+				Examples: []string{"/heist-admin target remove name:Corner Store"},
+			},
+			"heist-admin targets set": {
+				Examples: []string{"/heist-admin targets set name:clash"},
+			},
+			"heist-admin theme set": {
+				Examples: []string{"/heist-admin theme set name:clash"},
+			},
+		},
 	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Heist**\n"}, help...)
-
-	return help
 }
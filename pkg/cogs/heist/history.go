@@ -0,0 +1,316 @@
+package heist
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/logging"
+	discmsg "github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/store"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	HISTORY = "heist_history"
+
+	maxHistory         = 500
+	defaultHistorySize = 10
+)
+
+var (
+	histories    = make(map[string]*History)
+	historyMutex sync.Mutex
+)
+
+// History is the record of the heists that were completed on a server.
+type History struct {
+	ID     string         `json:"_id" bson:"_id"`         // Guild ID
+	NextID int64          `json:"next_id" bson:"next_id"` // Number given to the next heist that is recorded
+	Heists []*HeistRecord `json:"heists" bson:"heists"`   // Most recent heists, oldest first
+	Mutex  sync.Mutex     `json:"-" bson:"-"`
+}
+
+// HeistRecord is the record of a single completed heist.
+type HeistRecord struct {
+	ID          int64                `json:"id" bson:"id"`                     // Number of the heist on the server
+	Time        time.Time            `json:"time" bson:"time"`                 // Time the heist finished
+	Target      string               `json:"target" bson:"target"`             // Target that was hit
+	Theme       string               `json:"theme" bson:"theme"`               // Theme used for the heist
+	Hardcore    bool                 `json:"hardcore" bson:"hardcore"`         // Whether the heist was run in hardcore mode
+	VaultBefore int64                `json:"vault_before" bson:"vault_before"` // Credits in the vault before the heist
+	VaultAfter  int64                `json:"vault_after" bson:"vault_after"`   // Credits in the vault after the heist
+	Crew        []*HeistRecordMember `json:"crew" bson:"crew"`                 // Outcome for each member of the crew
+}
+
+// HeistRecordMember is the outcome of a heist for a single member of the crew.
+type HeistRecordMember struct {
+	MemberID string `json:"member_id" bson:"member_id"` // ID of the member
+	Name     string `json:"name" bson:"name"`           // Name of the member at the time of the heist
+	Status   string `json:"status" bson:"status"`       // Free, Apprehended or Dead
+	Message  string `json:"message" bson:"message"`     // Message from the theme describing the outcome
+	Stolen   int    `json:"stolen" bson:"stolen"`       // Credits taken from the vault
+	Bonus    int    `json:"bonus" bson:"bonus"`         // Bonus credits that were paid
+}
+
+// getHistory returns the history for the server, creating a new one if necessary.
+func getHistory(guildID string) *History {
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	history, ok := histories[guildID]
+	if !ok {
+		history = &History{
+			ID:     guildID,
+			NextID: 1,
+			Heists: make([]*HeistRecord, 0, 1),
+		}
+		histories[history.ID] = history
+	}
+	return history
+}
+
+// loadHistories loads the heist history for all servers from the store.
+func loadHistories() {
+	log.Trace("--> loadHistories")
+	defer log.Trace("<-- loadHistories")
+
+	historyMutex.Lock()
+	defer historyMutex.Unlock()
+
+	histories = make(map[string]*History)
+	for _, guildID := range store.Store.ListDocuments(HISTORY) {
+		var history History
+		store.Store.Load(HISTORY, guildID, &history)
+		histories[history.ID] = &history
+	}
+}
+
+// recordHeist adds the results of a completed heist to the history for the server.
+func recordHeist(server *Server, results *HeistResult, vaultBefore int64) *HeistRecord {
+	log.Trace("--> recordHeist")
+	defer log.Trace("<-- recordHeist")
+
+	record := &HeistRecord{
+		Time:        time.Now(),
+		Target:      results.target.ID,
+		Theme:       server.Config.Theme,
+		Hardcore:    server.Heist.Hardcore,
+		VaultBefore: vaultBefore,
+		VaultAfter:  results.target.Vault,
+		Crew:        make([]*HeistRecordMember, 0, len(results.memberResults)),
+	}
+	for _, result := range results.memberResults {
+		record.Crew = append(record.Crew, &HeistRecordMember{
+			MemberID: result.player.ID,
+			Name:     result.player.Name,
+			Status:   result.status,
+			Message:  fmt.Sprintf(result.message, result.player.Name),
+			Stolen:   result.stolenCredits,
+			Bonus:    result.bonusCredits,
+		})
+	}
+
+	history := getHistory(server.ID)
+	history.Mutex.Lock()
+	record.ID = history.NextID
+	history.NextID++
+	history.Heists = append(history.Heists, record)
+	if len(history.Heists) > maxHistory {
+		history.Heists = history.Heists[len(history.Heists)-maxHistory:]
+	}
+	store.Store.Save(HISTORY, history.ID, history)
+	history.Mutex.Unlock()
+
+	return record
+}
+
+// GetHistory returns the most recent heists on the server, newest first. If a member ID is given, only
+// the heists the member took part in are returned.
+func GetHistory(guildID string, memberID string, limit int) []*HeistRecord {
+	history := getHistory(guildID)
+	history.Mutex.Lock()
+	defer history.Mutex.Unlock()
+
+	records := make([]*HeistRecord, 0, limit)
+	for index := len(history.Heists) - 1; index >= 0 && len(records) < limit; index-- {
+		record := history.Heists[index]
+		if memberID != "" && record.member(memberID) == nil {
+			continue
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+// GetHeistRecord returns the heist with the given number on the server, or `false` if it is not in the
+// server's history.
+func GetHeistRecord(guildID string, id int64) (*HeistRecord, bool) {
+	history := getHistory(guildID)
+	history.Mutex.Lock()
+	defer history.Mutex.Unlock()
+
+	for _, record := range history.Heists {
+		if record.ID == id {
+			return record, true
+		}
+	}
+	return nil, false
+}
+
+// member returns the outcome of the heist for the member, or `nil` if the member wasn't in the crew.
+func (r *HeistRecord) member(memberID string) *HeistRecordMember {
+	for _, member := range r.Crew {
+		if member.MemberID == memberID {
+			return member
+		}
+	}
+	return nil
+}
+
+// counts returns the number of members of the crew who escaped, were apprehended, or died.
+func (r *HeistRecord) counts() (int, int, int) {
+	var escaped, apprehended, dead int
+	for _, member := range r.Crew {
+		switch member.Status {
+		case FREE:
+			escaped++
+		case APPREHENDED:
+			apprehended++
+		case DEAD:
+			dead++
+		}
+	}
+	return escaped, apprehended, dead
+}
+
+// payout returns the number of credits that were paid to the crew.
+func (r *HeistRecord) payout() int {
+	var total int
+	for _, member := range r.Crew {
+		total += member.Stolen + member.Bonus
+	}
+	return total
+}
+
+// showHistory shows the most recent heists on the server, or the details for a single heist.
+func showHistory(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> showHistory")
+	defer log.Trace("<-- showHistory")
+
+	p := getPrinter(i)
+	server := GetServer(servers, i.GuildID)
+	theme := getThemes()[server.Config.Theme]
+
+	var memberID string
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "id":
+			memberID = strings.TrimSpace(option.StringValue())
+		case "heist":
+			record, ok := GetHeistRecord(i.GuildID, option.IntValue())
+			if !ok {
+				discmsg.SendEphemeralResponse(s, i, fmt.Sprintf("No %s #%d was found.", theme.Heist, option.IntValue()))
+				return
+			}
+			sendHistoryEmbed(s, i, formatHeistRecord(p, theme, record))
+			return
+		}
+	}
+
+	records := GetHistory(i.GuildID, memberID, defaultHistorySize)
+	if len(records) == 0 {
+		discmsg.SendEphemeralResponse(s, i, "No "+theme.Heist+" has been recorded yet.")
+		return
+	}
+	sendHistoryEmbed(s, i, formatHistory(p, theme, records, memberID))
+}
+
+// sendHistoryEmbed sends the heist history to the member who asked for it.
+func sendHistoryEmbed(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{embed},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logging.WithInteraction(log, i).Error("Unable to send the heist history to Discord, error:", err)
+	}
+}
+
+// formatHistory returns a summary of each of the heists. If a member ID is given, the member's own outcome
+// is shown for each heist.
+func formatHistory(p *message.Printer, theme *Theme, records []*HeistRecord, memberID string) *discordgo.MessageEmbed {
+	var sb strings.Builder
+	for _, record := range records {
+		line := fmt.Sprintf("`#%d` <t:%d:R> **%s**: ", record.ID, record.Time.Unix(), record.Target)
+		if member := record.member(memberID); member != nil {
+			line += p.Sprintf("%s, %d credits", member.Status, member.Stolen+member.Bonus)
+		} else {
+			escaped, apprehended, dead := record.counts()
+			line += p.Sprintf("%d/%d/%d escaped/apprehended/dead, %d credits", escaped, apprehended, dead, record.payout())
+		}
+		if sb.Len()+len(line)+1 > maxEmbedDescription {
+			break
+		}
+		sb.WriteString(line + "\n")
+	}
+
+	title := "Recent " + theme.Heist + "s"
+	if memberID != "" {
+		title = p.Sprintf("Recent %ss for %s", theme.Heist, records[0].member(memberID).Name)
+	}
+	return &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       title,
+		Description: sb.String(),
+		Footer:      &discordgo.MessageEmbedFooter{Text: "Use /heist history heist:<number> to see the details of a " + theme.Heist + "."},
+	}
+}
+
+// formatHeistRecord returns the details of a single heist, including the outcome for each member of the crew.
+func formatHeistRecord(p *message.Printer, theme *Theme, record *HeistRecord) *discordgo.MessageEmbed {
+	var sb strings.Builder
+	for _, member := range record.Crew {
+		line := p.Sprintf("**%s** (%s): %s\nLoot: %d, Bonus: %d", member.Name, member.Status, member.Message, member.Stolen, member.Bonus)
+		if sb.Len()+len(line)+2 > maxEmbedDescription {
+			break
+		}
+		sb.WriteString(line + "\n\n")
+	}
+
+	caser := cases.Caser(cases.Title(language.Und, cases.NoLower))
+	title := fmt.Sprintf("%s #%d at %s", caser.String(theme.Heist), record.ID, record.Target)
+	if record.Hardcore {
+		title += " (Hardcore)"
+	}
+	return &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       title,
+		Description: sb.String(),
+		Timestamp:   record.Time.Format(time.RFC3339),
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Name:   caser.String(theme.Vault) + " Before",
+				Value:  p.Sprintf("%d", record.VaultBefore),
+				Inline: true,
+			},
+			{
+				Name:   caser.String(theme.Vault) + " After",
+				Value:  p.Sprintf("%d", record.VaultAfter),
+				Inline: true,
+			},
+			{
+				Name:   "Payout",
+				Value:  p.Sprintf("%d", record.payout()),
+				Inline: true,
+			},
+		},
+	}
+}
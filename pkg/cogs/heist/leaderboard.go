@@ -0,0 +1,330 @@
+package heist
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/olekukonko/tablewriter"
+	"github.com/rbrabson/heist/pkg/format"
+	"github.com/rbrabson/heist/pkg/logging"
+	discmsg "github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/shard"
+	"github.com/rbrabson/heist/pkg/store"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/message"
+)
+
+const (
+	LootBoard     = "loot"     // Most credits stolen
+	SpreeBoard    = "spree"    // Longest spree
+	SurvivedBoard = "survived" // Most heists survived
+	DeathsBoard   = "deaths"   // Most deaths
+	JailBoard     = "jail"     // Most time sentenced to jail
+
+	PeriodMonthly  = "monthly"
+	PeriodLifetime = "lifetime"
+
+	defaultLeaderboardSize = 10
+)
+
+// HeistStats are the counters used for the heist leaderboards.
+type HeistStats struct {
+	Loot     int64         `json:"loot" bson:"loot"`           // Credits stolen, including bonuses
+	Spree    int64         `json:"spree" bson:"spree"`         // Longest spree of heists escaped in a row
+	Survived int64         `json:"survived" bson:"survived"`   // Heists the player escaped or was apprehended in
+	Deaths   int64         `json:"deaths" bson:"deaths"`       // Heists the player died in
+	JailTime time.Duration `json:"jail_time" bson:"jail_time"` // Total time the player was sentenced to jail
+}
+
+// board is a heist leaderboard.
+type board struct {
+	name   string
+	title  string
+	value  func(stats *HeistStats) int64
+	format func(p *message.Printer, value int64) string
+}
+
+// LeaderboardEntry is a single entry in a heist leaderboard.
+type LeaderboardEntry struct {
+	MemberID string `json:"member_id"`
+	Name     string `json:"name"`
+	Value    int64  `json:"value"`
+}
+
+// boards are the heist leaderboards, in the order they are shown.
+var boards = []*board{
+	{
+		name:   LootBoard,
+		title:  "Most Loot",
+		value:  func(stats *HeistStats) int64 { return stats.Loot },
+		format: formatCount,
+	},
+	{
+		name:   SpreeBoard,
+		title:  "Longest Spree",
+		value:  func(stats *HeistStats) int64 { return stats.Spree },
+		format: formatCount,
+	},
+	{
+		name:   SurvivedBoard,
+		title:  "Most Survived",
+		value:  func(stats *HeistStats) int64 { return stats.Survived },
+		format: formatCount,
+	},
+	{
+		name:   DeathsBoard,
+		title:  "Most Deaths",
+		value:  func(stats *HeistStats) int64 { return stats.Deaths },
+		format: formatCount,
+	},
+	{
+		name:  JailBoard,
+		title: "Most Time in Jail",
+		value: func(stats *HeistStats) int64 { return int64(stats.JailTime) },
+		format: func(p *message.Printer, value int64) string {
+			return format.Duration(time.Duration(value))
+		},
+	},
+}
+
+// formatCount formats a leaderboard value that is a count.
+func formatCount(p *message.Printer, value int64) string {
+	return p.Sprintf("%d", value)
+}
+
+// getBoard returns the leaderboard with the given name.
+func getBoard(name string) (*board, error) {
+	for _, b := range boards {
+		if b.name == name {
+			return b, nil
+		}
+	}
+	return nil, ErrInvalidBoard
+}
+
+// getStats returns the player's counters for the period.
+func (p *Player) getStats(period string) *HeistStats {
+	if period == PeriodLifetime {
+		return &p.Lifetime
+	}
+	return &p.Monthly
+}
+
+// recordStats adds the player's outcome in a heist to the leaderboard counters. It is called after the
+// player's status, spree and sentence have been updated for the heist.
+func recordStats(player *Player, result *HeistMemberResult) {
+	for _, stats := range []*HeistStats{&player.Monthly, &player.Lifetime} {
+		stats.Loot += int64(result.stolenCredits + result.bonusCredits)
+		switch result.status {
+		case FREE:
+			stats.Survived++
+			stats.Spree = max(stats.Spree, player.Spree)
+		case APPREHENDED:
+			stats.Survived++
+			stats.JailTime += player.Sentence
+		case DEAD:
+			stats.Deaths++
+		}
+	}
+}
+
+// sortedPlayers returns the players on the server sorted by their value on the leaderboard. Players with
+// the same value are sorted by name.
+func sortedPlayers(server *Server, b *board, period string) []*Player {
+	players := make([]*Player, 0, len(server.Players))
+	for _, player := range server.Players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		vi, vj := b.value(players[i].getStats(period)), b.value(players[j].getStats(period))
+		if vi != vj {
+			return vi > vj
+		}
+		return players[i].Name < players[j].Name
+	})
+	return players
+}
+
+// GetLeaderboard returns the top `limit` players on the server for the leaderboard and period. Players
+// who haven't scored on the leaderboard are left out.
+func GetLeaderboard(guildID string, name string, period string, limit int) ([]*LeaderboardEntry, error) {
+	log.Trace("--> GetLeaderboard")
+	defer log.Trace("<-- GetLeaderboard")
+
+	b, err := getBoard(name)
+	if err != nil {
+		return nil, err
+	}
+	if period != PeriodMonthly && period != PeriodLifetime {
+		return nil, ErrInvalidPeriod
+	}
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+
+	leaderboard := make([]*LeaderboardEntry, 0, limit)
+	for _, player := range sortedPlayers(server, b, period) {
+		value := b.value(player.getStats(period))
+		if value == 0 || len(leaderboard) >= limit {
+			break
+		}
+		leaderboard = append(leaderboard, &LeaderboardEntry{
+			MemberID: player.ID,
+			Name:     player.Name,
+			Value:    value,
+		})
+	}
+	return leaderboard, nil
+}
+
+// getRanking returns the player's rank on the leaderboard for the period, or 0 if the player hasn't scored
+// on the leaderboard.
+func getRanking(server *Server, b *board, period string, player *Player) int {
+	if b.value(player.getStats(period)) == 0 {
+		return 0
+	}
+	for rank, p := range sortedPlayers(server, b, period) {
+		if p.ID == player.ID {
+			return rank + 1
+		}
+	}
+	return 0
+}
+
+// formatRankings returns the player's rank on each leaderboard, for the current month and all time.
+func formatRankings(p *message.Printer, server *Server, player *Player) string {
+	var sb strings.Builder
+	for _, b := range boards {
+		sb.WriteString(p.Sprintf("%s: %s this month, %s all time\n", b.title, formatRank(getRanking(server, b, PeriodMonthly, player)), formatRank(getRanking(server, b, PeriodLifetime, player))))
+	}
+	return sb.String()
+}
+
+// formatRank formats the rank on a leaderboard.
+func formatRank(rank int) string {
+	if rank == 0 {
+		return "unranked"
+	}
+	return "#" + strconv.Itoa(rank)
+}
+
+// showLeaderboard shows a heist leaderboard for the server.
+func showLeaderboard(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> showLeaderboard")
+	defer log.Trace("<-- showLeaderboard")
+
+	p := getPrinter(i)
+	name := LootBoard
+	period := PeriodMonthly
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "board":
+			name = option.StringValue()
+		case "period":
+			period = option.StringValue()
+		}
+	}
+	b, err := getBoard(name)
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to show the leaderboard: "+err.Error())
+		return
+	}
+	leaderboard, err := GetLeaderboard(i.GuildID, name, period, defaultLeaderboardSize)
+	if err != nil {
+		discmsg.SendEphemeralResponse(s, i, "Unable to show the leaderboard: "+err.Error())
+		return
+	}
+	if len(leaderboard) == 0 {
+		discmsg.SendEphemeralResponse(s, i, "No one is on the "+strings.ToLower(b.title)+" leaderboard yet.")
+		return
+	}
+
+	title := b.title + " This Month"
+	if period == PeriodLifetime {
+		title = b.title + " of All Time"
+	}
+	err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds: []*discordgo.MessageEmbed{formatLeaderboard(p, title, b, leaderboard)},
+			Flags:  discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		logging.WithInteraction(log, i).Error("Unable to send the heist leaderboard to Discord, error:", err)
+	}
+}
+
+// formatLeaderboard formats the leaderboard to be sent to a Discord server.
+func formatLeaderboard(p *message.Printer, title string, b *board, leaderboard []*LeaderboardEntry) *discordgo.MessageEmbed {
+	var tableBuffer strings.Builder
+	table := tablewriter.NewWriter(&tableBuffer)
+	table.SetAutoWrapText(false)
+	table.SetAutoFormatHeaders(true)
+	table.SetHeaderAlignment(tablewriter.ALIGN_LEFT)
+	table.SetAlignment(tablewriter.ALIGN_LEFT)
+	table.SetCenterSeparator("")
+	table.SetColumnSeparator("")
+	table.SetRowSeparator("")
+	table.SetHeaderLine(false)
+	table.SetBorder(false)
+	table.SetTablePadding("\t")
+	table.SetNoWhiteSpace(true)
+	table.SetHeader([]string{"#", "Name", b.name})
+	for i, entry := range leaderboard {
+		table.Append([]string{strconv.Itoa(i + 1), entry.Name, b.format(p, entry.Value)})
+	}
+	table.Render()
+
+	return &discordgo.MessageEmbed{
+		Type:  discordgo.EmbedTypeRich,
+		Title: title,
+		Fields: []*discordgo.MessageEmbedField{
+			{
+				Value: p.Sprintf("```\n%s```\n", tableBuffer.String()),
+			},
+		},
+	}
+}
+
+// resetMonthlyStats resets the monthly leaderboard counters for all players at the start of each month. A
+// server whose last season started before the current month is reset, so a reset that was missed while the
+// bot was down is done once the bot is back up. Only the servers handled by the shards run by this process
+// are reset.
+func resetMonthlyStats(ctx context.Context) error {
+	log.Trace("--> resetMonthlyStats")
+	defer log.Trace("<-- resetMonthlyStats")
+
+	now := time.Now().UTC()
+	season := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	for _, server := range servers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !shard.Owns(server.ID) {
+			continue
+		}
+		server.Mutex.Lock()
+		if !server.LastSeason.IsZero() && !server.LastSeason.Before(season) {
+			server.Mutex.Unlock()
+			continue
+		}
+		if !server.LastSeason.IsZero() {
+			for _, player := range server.Players {
+				player.Monthly = HeistStats{}
+			}
+			log.WithFields(logrus.Fields{"Server": server.ID, "Players": len(server.Players)}).Info("Monthly heist stats reset")
+		}
+		server.LastSeason = season
+		store.Store.Save(HEIST, server.ID, server)
+		server.Mutex.Unlock()
+	}
+
+	return nil
+}
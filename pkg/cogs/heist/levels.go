@@ -0,0 +1,137 @@
+package heist
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/message"
+)
+
+const (
+	defaultLevelXP   = 100       // XP needed for each criminal level
+	defaultHeistXP   = 25        // XP earned by each member of the crew who escapes
+	maxLevelXP       = 1_000_000 // Largest XP that may be set for a level or a heist
+	lootBonusPerRank = 5         // Percentage of the loot added as a bonus for each rank above Greenhorn
+
+	defaultLevelUp = "%s is now a %s."
+)
+
+// ranks are the criminal levels at which a player is given a new title, from lowest to highest.
+var ranks = []CriminalLevel{Greenhorn, Renegade, Veteran, Commander, WarChief, Legend, Immortal}
+
+// levelXP returns the XP needed for each criminal level on the server.
+func (c *Config) levelXP() int64 {
+	if c.LevelXP <= 0 {
+		return defaultLevelXP
+	}
+	return c.LevelXP
+}
+
+// heistXP returns the XP earned by each member of the crew who escapes a heist on the server.
+func (c *Config) heistXP() int64 {
+	if c.HeistXP <= 0 {
+		return defaultHeistXP
+	}
+	return c.HeistXP
+}
+
+// levelForXP returns the criminal level reached with the given XP.
+func (c *Config) levelForXP(xp int64) CriminalLevel {
+	level := xp / c.levelXP()
+	if level > int64(Immortal) {
+		return Immortal
+	}
+	return CriminalLevel(level)
+}
+
+// rank returns the position of the criminal level's title, starting with 0 for a Greenhorn.
+func (cl CriminalLevel) rank() int {
+	rank := 0
+	for i, level := range ranks {
+		if cl >= level {
+			rank = i
+		}
+	}
+	return rank
+}
+
+// successBonus returns the percentage added to a player's chance of escaping a heist.
+func (cl CriminalLevel) successBonus() int {
+	return cl.rank()
+}
+
+// lootBonus returns the percentage of the loot added as a bonus for a player who escapes a heist.
+func (cl CriminalLevel) lootBonus() int {
+	return cl.rank() * lootBonusPerRank
+}
+
+// Title returns the criminal level with the title for the level, such as "Veteran (level 12)".
+func (cl CriminalLevel) Title() string {
+	return fmt.Sprintf("%s (level %d)", cl.String(), int(cl))
+}
+
+// awardXP gives the player the XP for escaping a heist. If the player reaches a new criminal level,
+// `true` is returned. A player's level is never lowered by the XP they have, so levels reached before
+// XP was tracked are kept.
+func awardXP(cfg *Config, player *Player) bool {
+	player.XP += cfg.heistXP()
+	level := cfg.levelForXP(player.XP)
+	if level <= player.CriminalLevel {
+		return false
+	}
+	log.WithFields(logrus.Fields{"Player": player.Name, "XP": player.XP, "Old": player.CriminalLevel, "New": level}).Debug("Criminal level increased")
+	player.CriminalLevel = level
+	return true
+}
+
+// forfeitXP removes the XP a player earned toward their next criminal level when they die. In hardcore
+// mode, the player also drops a level.
+func forfeitXP(cfg *Config, player *Player, hardcore bool) {
+	if hardcore && player.CriminalLevel > Greenhorn {
+		player.CriminalLevel--
+	}
+	player.XP = min(player.XP, int64(player.CriminalLevel)*cfg.levelXP())
+}
+
+// formatXP returns the player's XP and the XP needed to reach their next criminal level.
+func formatXP(p *message.Printer, cfg *Config, player *Player) string {
+	if player.CriminalLevel >= Immortal {
+		return p.Sprintf("%d", player.XP)
+	}
+	next := (int64(player.CriminalLevel) + 1) * cfg.levelXP()
+	return p.Sprintf("%d / %d", player.XP, next)
+}
+
+// unlockedTargets returns the targets the planner of the heist has a high enough criminal level to hit.
+// If the planner can't hit any of the targets, then all of them are returned.
+func (s *Server) unlockedTargets() map[string]*Target {
+	var level CriminalLevel
+	if planner, ok := s.Players[s.Heist.Planner]; ok {
+		level = planner.CriminalLevel
+	}
+	unlocked := make(map[string]*Target, len(s.Targets))
+	for id, target := range s.Targets {
+		if target.Level <= level {
+			unlocked[id] = target
+		}
+	}
+	if len(unlocked) == 0 {
+		return s.Targets
+	}
+	return unlocked
+}
+
+// formatLevelUps returns the announcement for the players who reached a new criminal level, using the
+// wording of the theme.
+func formatLevelUps(p *message.Printer, theme *Theme, players []*Player) string {
+	levelUp := theme.LevelUp
+	if levelUp == "" {
+		levelUp = defaultLevelUp
+	}
+	lines := make([]string, 0, len(players))
+	for _, player := range players {
+		lines = append(lines, p.Sprintf(levelUp, "**"+player.Name+"**", player.CriminalLevel.Title()))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,46 @@
+package heist
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sirupsen/logrus"
+)
+
+// HeistState is the stage a heist is at in its lifecycle.
+type HeistState string
+
+const (
+	HeistPlanning HeistState = "planning" // The crew is being gathered
+	HeistRunning  HeistState = "running"  // The crew is hitting the target
+	HeistSettled  HeistState = "settled"  // The results of the heist have been paid out
+	HeistCanceled HeistState = "canceled" // The heist was called off before it was settled
+)
+
+// heistTransitions are the states a heist may move to from each state. Settled and canceled heists are done,
+// so they can't move to any other state.
+var heistTransitions = map[HeistState][]HeistState{
+	HeistPlanning: {HeistRunning, HeistCanceled},
+	HeistRunning:  {HeistSettled, HeistCanceled},
+}
+
+// transition moves the heist to a new state, returning an error if the heist can't move from its current
+// state to the new one. Canceling a heist stops the wait for it to start. The server's mutex must be held
+// by the caller.
+func (h *Heist) transition(to HeistState) error {
+	if !slices.Contains(heistTransitions[h.State], to) {
+		return fmt.Errorf("%w from %s to %s", ErrInvalidTransition, h.State, to)
+	}
+	log.WithFields(logrus.Fields{"Planner": h.Planner, "From": h.State, "To": to}).Debug("Heist state changed")
+	h.State = to
+	if to == HeistCanceled && h.cancel != nil {
+		close(h.cancel)
+	}
+	return nil
+}
+
+// isPlanning returns `true` if the crew for the heist is still being gathered. The server's mutex must be
+// held by the caller.
+func (h *Heist) isPlanning() bool {
+	return h != nil && h.State == HeistPlanning
+}
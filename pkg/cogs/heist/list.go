@@ -14,3 +14,14 @@ func contains[V comparable](list []V, element V) bool {
 	}
 	return false
 }
+
+// remove returns the slice without the element.
+func remove[V comparable](list []V, element V) []V {
+	result := make([]V, 0, len(list))
+	for _, a := range list {
+		if a != element {
+			result = append(result, a)
+		}
+	}
+	return result
+}
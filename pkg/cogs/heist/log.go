@@ -0,0 +1,6 @@
+package heist
+
+import "github.com/rbrabson/heist/pkg/logging"
+
+// log is the logger used by the heist cog.
+var log = logging.Logger("heist")
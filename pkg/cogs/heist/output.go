@@ -0,0 +1,84 @@
+package heist
+
+import (
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"golang.org/x/text/cases"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+const (
+	CrewOutputFull      = "full"      // A message for each member of the crew, sent a few seconds apart
+	CrewOutputCondensed = "condensed" // A single message listing the outcome for each member of the crew
+	CrewOutputSummary   = "summary"   // A single message with the number of members who escaped, were caught or died
+
+	maxEmbedDescription = 4096
+)
+
+// crewOutputMode returns how the outcome for each member of the crew is shown on the server. Servers that
+// haven't picked a mode use the full output.
+func (c *Config) crewOutputMode() string {
+	switch c.CrewOutput {
+	case CrewOutputCondensed, CrewOutputSummary:
+		return c.CrewOutput
+	default:
+		return CrewOutputFull
+	}
+}
+
+// sendCrewOutcomes sends the outcome of the heist for each member of the crew, using the crew output mode
+// for the server.
+func sendCrewOutcomes(s *discordgo.Session, i *discordgo.InteractionCreate, p *message.Printer, server *Server, results *HeistResult) {
+	log.Trace("--> sendCrewOutcomes")
+	defer log.Trace("<-- sendCrewOutcomes")
+
+	theme := getThemes()[server.Config.Theme]
+
+	switch server.Config.crewOutputMode() {
+	case CrewOutputFull:
+		for _, result := range results.memberResults {
+			msg := p.Sprintf(result.message+"\n", "**"+result.player.Name+"**")
+			if result.status == APPREHENDED {
+				msg += p.Sprintf("`%s dropped out of the game.`", result.player.Name)
+			}
+			s.ChannelMessageSend(i.ChannelID, msg)
+			time.Sleep(3 * time.Second)
+		}
+	case CrewOutputCondensed:
+		var sb strings.Builder
+		for idx, result := range results.memberResults {
+			line := p.Sprintf(result.message, "**"+result.player.Name+"**")
+			if result.status != FREE {
+				line += " (" + result.status + ")"
+			}
+			// Leave room for the line counting the members that don't fit in the description
+			size := sb.Len() + len(line) + 1
+			if remaining := len(results.memberResults) - idx - 1; remaining > 0 {
+				size += len(p.Sprintf("…and %d more", remaining))
+			}
+			if size > maxEmbedDescription {
+				sb.WriteString(p.Sprintf("…and %d more", len(results.memberResults)-idx))
+				break
+			}
+			sb.WriteString(line + "\n")
+		}
+		caser := cases.Caser(cases.Title(language.Und, cases.NoLower))
+		embed := &discordgo.MessageEmbed{
+			Type:        discordgo.EmbedTypeRich,
+			Title:       p.Sprintf("%s at %s", caser.String(theme.Heist), results.target.ID),
+			Description: sb.String(),
+			Footer:      &discordgo.MessageEmbedFooter{Text: formatCrewSummary(p, results)},
+		}
+		s.ChannelMessageSendEmbed(i.ChannelID, embed)
+	case CrewOutputSummary:
+		s.ChannelMessageSend(i.ChannelID, formatCrewSummary(p, results))
+	}
+}
+
+// formatCrewSummary returns the number of crew members who escaped, were apprehended, or died.
+func formatCrewSummary(p *message.Printer, results *HeistResult) string {
+	return p.Sprintf("%d escaped, %d apprehended, %d dead.", results.escaped, results.apprehended, results.dead)
+}
@@ -0,0 +1,134 @@
+package heist
+
+import (
+	"context"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/shard"
+	"github.com/rbrabson/heist/pkg/store"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	maxResumeDelay = 5 * time.Minute // Longest a heist may have been due to start and still be resumed
+)
+
+// resumeHeists resumes the heists that were being planned when the bot was stopped. Only the heists for
+// the servers handled by the shards run by this process are resumed.
+func resumeHeists(ctx context.Context) error {
+	log.Trace("--> resumeHeists")
+	defer log.Trace("<-- resumeHeists")
+
+	for _, server := range servers {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if server.Heist == nil || !shard.Owns(server.ID) {
+			continue
+		}
+		resumeHeist(shard.Session(server.ID), server)
+	}
+
+	return nil
+}
+
+// resumeHeist picks up the planning of a heist after a restart. A heist that was already started, or
+// whose message can no longer be updated, can't be resumed and is canceled instead.
+func resumeHeist(s *discordgo.Session, server *Server) {
+	log.Trace("--> resumeHeist")
+	defer log.Trace("<-- resumeHeist")
+
+	server.Mutex.Lock()
+	heist := server.Heist
+	heist.Interaction = heistInteraction(server, heist)
+	heist.cancel = make(chan struct{})
+
+	var reason string
+	var updateMessage *heistMessage
+	switch {
+	case heist.State == HeistRunning:
+		reason = "it was in progress when the bot restarted"
+	case !heist.isPlanning() || heist.MessageID == "" || heist.ChannelID == "":
+		reason = "it could not be found after the bot restarted"
+	case time.Since(heist.StartTime) > maxResumeDelay:
+		reason = "the bot was down when it was due to start"
+	default:
+		updateMessage = newHeistMessage(heist.Interaction, "update")
+	}
+	server.Mutex.Unlock()
+
+	if updateMessage != nil {
+		if _, err := updateMessage.send(s); err != nil {
+			log.WithField("Server", server.ID).Warning("Unable to update the message for a resumed heist, error:", err)
+			reason = "its message could not be updated after the bot restarted"
+		}
+	}
+	if reason != "" {
+		abortHeist(s, server, heist, reason)
+		return
+	}
+
+	log.WithFields(logrus.Fields{"Server": server.ID, "Crew": len(heist.Crew), "StartTime": heist.StartTime}).Info("Resumed a planned heist")
+	go waitForHeist(s, heist.Interaction, server, heist)
+}
+
+// abortHeist cancels a heist that can't be resumed, refunding the crew and telling the channel why. The
+// server's mutex must not be held by the caller.
+func abortHeist(s *discordgo.Session, server *Server, heist *Heist, reason string) {
+	log.Trace("--> abortHeist")
+	defer log.Trace("<-- abortHeist")
+
+	theme := getThemes()[server.Config.Theme]
+	p := getPrinter(heist.Interaction)
+
+	server.Mutex.Lock()
+	if server.Heist != heist {
+		// The heist was reset while it was being resumed
+		server.Mutex.Unlock()
+		return
+	}
+	if err := heist.transition(HeistCanceled); err != nil {
+		log.WithField("Server", server.ID).Warning("Canceling a heist in an unknown state, error:", err)
+	}
+	crewSize := refundCrew(server, heist)
+	log.WithFields(logrus.Fields{"Server": server.ID, "Crew": crewSize, "Reason": reason}).Warning("Canceled a heist that could not be resumed")
+
+	var cancelMessage *heistMessage
+	if heist.MessageID != "" && heist.ChannelID != "" {
+		cancelMessage = newHeistMessage(heist.Interaction, "cancel")
+	}
+	server.Heist = nil
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	if cancelMessage != nil {
+		if _, err := cancelMessage.send(s); err != nil {
+			log.Error("Unable to mark the heist message as canceled, error:", err)
+		}
+		msg := p.Sprintf("The %s was canceled because %s. The %s has been refunded.", theme.Heist, reason, theme.Crew)
+		if _, err := s.ChannelMessageSend(heist.ChannelID, msg); err != nil {
+			log.Error("Unable to send the heist cancellation message, error:", err)
+		}
+	}
+}
+
+// heistInteraction returns an interaction for a heist resumed after a restart, standing in for the
+// interaction that was used to plan the heist. It has no token, so the heist message is edited in the
+// channel rather than through the interaction.
+func heistInteraction(server *Server, heist *Heist) *discordgo.InteractionCreate {
+	member := &discordgo.Member{User: &discordgo.User{ID: heist.Planner}}
+	if planner, ok := server.Players[heist.Planner]; ok {
+		member.User.Username = planner.Name
+		member.Nick = planner.Name
+	}
+	return &discordgo.InteractionCreate{
+		Interaction: &discordgo.Interaction{
+			Type:      discordgo.InteractionApplicationCommand,
+			GuildID:   server.ID,
+			ChannelID: heist.ChannelID,
+			Member:    member,
+			Locale:    heist.Locale,
+		},
+	}
+}
@@ -0,0 +1,163 @@
+package heist
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/store"
+)
+
+const (
+	maxDuration = 7 * 24 * time.Hour
+)
+
+// Validate checks that the heist configuration may be used by a server. All problems that are found are
+// returned in a single error.
+func (c *Config) Validate() error {
+	var problems []error
+
+	if c.HeistCost < 0 {
+		problems = append(problems, errors.New("the cost must not be negative"))
+	}
+	if c.BailBase < 0 {
+		problems = append(problems, errors.New("the bail must not be negative"))
+	}
+	if c.SentenceBase < 0 || c.SentenceBase > maxDuration {
+		problems = append(problems, errors.New("the sentence must be between 0 seconds and 7 days"))
+	}
+	if c.PoliceAlert < 0 || c.PoliceAlert > maxDuration {
+		problems = append(problems, errors.New("the patrol time must be between 0 seconds and 7 days"))
+	}
+	if c.DeathTimer < 0 || c.DeathTimer > maxDuration {
+		problems = append(problems, errors.New("the death time must be between 0 seconds and 7 days"))
+	}
+	if c.WaitTime <= 0 || c.WaitTime > maxDuration {
+		problems = append(problems, errors.New("the wait time must be between 1 second and 7 days"))
+	}
+	if c.LevelXP <= 0 || c.LevelXP > maxLevelXP {
+		problems = append(problems, errors.New("the XP for each level must be between 1 and 1,000,000"))
+	}
+	if c.HeistXP <= 0 || c.HeistXP > maxLevelXP {
+		problems = append(problems, errors.New("the XP for a heist must be between 1 and 1,000,000"))
+	}
+	if err := c.validateSpree(); err != nil {
+		problems = append(problems, err)
+	}
+	if err := c.validateBreakout(); err != nil {
+		problems = append(problems, err)
+	}
+	switch c.CrewOutput {
+	case "", "None", CrewOutputFull, CrewOutputCondensed, CrewOutputSummary:
+	default:
+		problems = append(problems, fmt.Errorf("the crew output must be %s, %s or %s", CrewOutputFull, CrewOutputCondensed, CrewOutputSummary))
+	}
+	switch c.TargetVote {
+	case "", TargetAuto, TargetPlanner, TargetCrew:
+	default:
+		problems = append(problems, fmt.Errorf("the target vote must be %s, %s or %s", TargetAuto, TargetPlanner, TargetCrew))
+	}
+	if _, ok := getThemes()[c.Theme]; !ok {
+		problems = append(problems, fmt.Errorf("theme %s does not exist", c.Theme))
+	}
+	if _, ok := getTargetSet()[c.Targets]; !ok {
+		problems = append(problems, fmt.Errorf("targets %s do not exist", c.Targets))
+	}
+
+	return errors.Join(problems...)
+}
+
+// UpdateConfig changes the heist configuration for the server. The changes are made to a copy of the
+// configuration, which is only used if it is valid. The configuration before the change is returned.
+func UpdateConfig(guildID string, update func(cfg *Config)) (*Config, error) {
+	log.Trace("--> UpdateConfig")
+	defer log.Trace("<-- UpdateConfig")
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	oldConfig := server.Config
+	newConfig := server.Config
+	update(&newConfig)
+	err := newConfig.Validate()
+	if err != nil {
+		server.Mutex.Unlock()
+		return nil, err
+	}
+	server.Config = newConfig
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	return &oldConfig, nil
+}
+
+// GetPlayers returns a copy of all players that have taken part in a heist on the server.
+func GetPlayers(guildID string) []*Player {
+	server, ok := servers[guildID]
+	if !ok {
+		return nil
+	}
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+
+	players := make([]*Player, 0, len(server.Players))
+	for _, player := range server.Players {
+		p := *player
+		players = append(players, &p)
+	}
+	return players
+}
+
+// ClearPlayer clears the criminal state of the player. The player before and after being cleared
+// is returned.
+func ClearPlayer(guildID string, memberID string) (*Player, *Player, error) {
+	log.Trace("--> ClearPlayer")
+	defer log.Trace("<-- ClearPlayer")
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	player, ok := server.Players[memberID]
+	if !ok {
+		server.Mutex.Unlock()
+		return nil, nil, ErrNoPlayer
+	}
+	oldPlayer := *player
+	player.Reset()
+	newPlayer := *player
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	return &oldPlayer, &newPlayer, nil
+}
+
+// ResetHeist cancels a heist on the server that is hung. The names of the crew members of the heist are
+// returned.
+func ResetHeist(s *discordgo.Session, guildID string) ([]string, error) {
+	log.Trace("--> ResetHeist")
+	defer log.Trace("<-- ResetHeist")
+
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	if server.Heist == nil {
+		server.Mutex.Unlock()
+		return nil, ErrNoHeist
+	}
+	if err := server.Heist.transition(HeistCanceled); err != nil {
+		log.WithField("Server", server.ID).Warning("Resetting a heist that has already ended, error:", err)
+	}
+
+	crew := make([]string, 0, len(server.Heist.Crew))
+	for _, id := range server.Heist.Crew {
+		crew = append(crew, server.Players[id].Name)
+	}
+	var heistMsg *heistMessage
+	if server.Heist.Interaction != nil {
+		heistMsg = newHeistMessage(server.Heist.Interaction, "cancel")
+	}
+	server.Heist = nil
+	store.Store.Save(HEIST, server.ID, server)
+	server.Mutex.Unlock()
+
+	sendHeistMessage(s, heistMsg)
+	return crew, nil
+}
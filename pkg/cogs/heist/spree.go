@@ -0,0 +1,146 @@
+package heist
+
+import (
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/text/message"
+)
+
+const (
+	defaultSpreeBonus   = 10 // Percentage of the loot added as a bonus for each heist in a player's spree
+	defaultSpreeCap     = 50 // Largest percentage of the loot that may be added for a spree
+	defaultSpreePenalty = 2  // Percentage taken from a hot player's chance of success for each heist in their streak
+	maxSpreePenalty     = 20 // Largest percentage that may be taken from a hot player's chance of success
+	spreeAlertIncrease  = 10 // Percentage the police alert grows for each heist in the crew's hottest streak
+	maxSpreeAlertSteps  = 10 // Number of heists in a streak above which the police alert no longer grows
+	maxSpreeMilestones  = 10
+)
+
+var (
+	defaultSpreeMilestones = []int64{3, 5, 10, 25}
+)
+
+// setSpreeDefaults sets the default spree rewards for a server.
+func (c *Config) setSpreeDefaults() {
+	c.SpreeBonus = defaultSpreeBonus
+	c.SpreeCap = defaultSpreeCap
+	c.SpreePenalty = defaultSpreePenalty
+	c.SpreeMilestones = slices.Clone(defaultSpreeMilestones)
+}
+
+// validateSpree checks the spree rewards for the server.
+func (c *Config) validateSpree() error {
+	var problems []error
+
+	if c.SpreeBonus < 0 || c.SpreeBonus > 100 {
+		problems = append(problems, errors.New("the spree bonus must be between 0 and 100 percent"))
+	}
+	if c.SpreeCap < 0 || c.SpreeCap > 1000 {
+		problems = append(problems, errors.New("the spree cap must be between 0 and 1,000 percent"))
+	}
+	if c.SpreePenalty < 0 || c.SpreePenalty > maxSpreePenalty {
+		problems = append(problems, errors.New("the spree penalty must be between 0 and 20 percent"))
+	}
+	if len(c.SpreeMilestones) > maxSpreeMilestones {
+		problems = append(problems, errors.New("there may be at most 10 spree milestones"))
+	}
+	for index, milestone := range c.SpreeMilestones {
+		if milestone <= 0 || (index > 0 && milestone <= c.SpreeMilestones[index-1]) {
+			problems = append(problems, errors.New("the spree milestones must be positive and in increasing order"))
+			break
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
+// ParseMilestones returns the spree milestones in a comma separated list. An empty list, or "none", turns
+// the milestones off.
+func ParseMilestones(value string) ([]int64, error) {
+	milestones := make([]int64, 0, maxSpreeMilestones)
+	if strings.EqualFold(strings.TrimSpace(value), "none") {
+		return milestones, nil
+	}
+	for _, field := range strings.Split(value, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		milestone, err := strconv.ParseInt(field, 10, 64)
+		if err != nil {
+			return nil, errors.New("the spree milestones must be a comma separated list of numbers")
+		}
+		milestones = append(milestones, milestone)
+	}
+	slices.Sort(milestones)
+	return slices.Compact(milestones), nil
+}
+
+// FormatMilestones returns the spree milestones as a comma separated list.
+func FormatMilestones(milestones []int64) string {
+	if len(milestones) == 0 {
+		return "None"
+	}
+	values := make([]string, 0, len(milestones))
+	for _, milestone := range milestones {
+		values = append(values, strconv.FormatInt(milestone, 10))
+	}
+	return strings.Join(values, ", ")
+}
+
+// formatSpree returns a description of the spree rewards for the server.
+func formatSpree(p *message.Printer, cfg *Config) string {
+	return p.Sprintf("%d%% bonus per heist up to %d%%, %d%% penalty per heist in a hot streak, milestones: %s", cfg.SpreeBonus, cfg.SpreeCap, cfg.SpreePenalty, FormatMilestones(cfg.SpreeMilestones))
+}
+
+// hotStreak returns the number of heists a player's spree is above the first milestone, counting the first
+// milestone itself. A player who hasn't reached the first milestone isn't on a hot streak.
+func (c *Config) hotStreak(spree int64) int64 {
+	if len(c.SpreeMilestones) == 0 || spree < c.SpreeMilestones[0] {
+		return 0
+	}
+	return spree - c.SpreeMilestones[0] + 1
+}
+
+// spreeBonus returns the percentage of the loot added as a bonus for the player's spree.
+func (c *Config) spreeBonus(spree int64) int {
+	return int(min(spree*c.SpreeBonus, c.SpreeCap))
+}
+
+// spreePenalty returns the percentage taken from the chance of success for a player on a hot streak.
+func (c *Config) spreePenalty(spree int64) int {
+	return int(min(c.hotStreak(spree)*c.SpreePenalty, maxSpreePenalty))
+}
+
+// policeAlert returns how long the authorities patrol after a heist. The patrol grows for the crew member
+// with the hottest streak, and again if a fugitive was in the crew.
+func (c *Config) policeAlert(crew []*HeistMemberResult) time.Duration {
+	var steps int64
+	var fugitive int64
+	for _, result := range crew {
+		steps = max(steps, c.hotStreak(result.player.Spree))
+		if result.player.Fugitive {
+			fugitive = c.BreakoutAlert
+		}
+	}
+	steps = min(steps, maxSpreeAlertSteps)
+	return c.PoliceAlert * time.Duration(100+steps*spreeAlertIncrease+fugitive) / 100
+}
+
+// isMilestone returns `true` if the spree is one of the server's spree milestones.
+func (c *Config) isMilestone(spree int64) bool {
+	return slices.Contains(c.SpreeMilestones, spree)
+}
+
+// formatMilestoneReached returns the announcement for the players who reached a spree milestone.
+func formatMilestoneReached(p *message.Printer, theme *Theme, players []*Player) string {
+	lines := make([]string, 0, len(players))
+	for _, player := range players {
+		lines = append(lines, p.Sprintf("**%s** is on a spree of %d %ss in a row!", player.Name, player.Spree, theme.Heist))
+	}
+	return strings.Join(lines, "\n")
+}
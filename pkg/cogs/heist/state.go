@@ -2,14 +2,15 @@ package heist
 
 import (
 	"encoding/json"
-	"os"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	botconfig "github.com/rbrabson/heist/pkg/config"
 	hmath "github.com/rbrabson/heist/pkg/math"
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 type CriminalLevel int
@@ -29,62 +30,88 @@ type Server struct {
 	ID      string             `json:"_id" bson:"_id"`
 	Config  Config             `json:"config" bson:"config"`
 	Players map[string]*Player `json:"players" bson:"players"`
-	Heist   *Heist             `json:"-" bson:"-"`
+	Heist   *Heist             `json:"heist,omitempty" bson:"heist,omitempty"`
 	Targets map[string]*Target `json:"targets" bson:"targets"`
-	Mutex   sync.Mutex         `json:"-" bson:"-"`
+	// LastSeason is the start of the month the monthly heist stats are being kept for.
+	LastSeason time.Time `json:"last_season" bson:"last_season"`
+	// Mutex guards the players, targets and heist for the server.
+	Mutex sync.Mutex `json:"-" bson:"-"`
 }
 
 // Config is the configuration data for a given server.
 type Config struct {
-	AlertTime    time.Time     `json:"alert_time" bson:"alert_time"`
-	BailBase     int64         `json:"bail_base" bson:"bail_base"`
-	CrewOutput   string        `json:"crew_output" bson:"crew_output"`
-	DeathTimer   time.Duration `json:"death_timer" bson:"death_timer"`
-	Hardcore     bool          `json:"hardcore" bson:"hardcore"`
-	HeistCost    int64         `json:"heist_cost" bson:"heist_cost"`
-	PoliceAlert  time.Duration `json:"police_alert" bson:"police_alert"`
-	SentenceBase time.Duration `json:"sentence_base" bson:"sentence_base"`
-	Theme        string        `json:"theme" bson:"theme"`
-	Targets      string        `json:"targets" bson:"targets"`
-	WaitTime     time.Duration `json:"wait_time" bson:"wait_time"`
+	AlertTime       time.Time     `json:"alert_time" bson:"alert_time"`
+	BailBase        int64         `json:"bail_base" bson:"bail_base"`
+	BreakoutAlert   int64         `json:"breakout_alert" bson:"breakout_alert"`
+	BreakoutBail    int64         `json:"breakout_bail" bson:"breakout_bail"`
+	BreakoutChance  int64         `json:"breakout_chance" bson:"breakout_chance"`
+	BreakoutPenalty int64         `json:"breakout_penalty" bson:"breakout_penalty"`
+	CrewOutput      string        `json:"crew_output" bson:"crew_output"`
+	DeathTimer      time.Duration `json:"death_timer" bson:"death_timer"`
+	Hardcore        bool          `json:"hardcore" bson:"hardcore"`
+	HeistCost       int64         `json:"heist_cost" bson:"heist_cost"`
+	HeistXP         int64         `json:"heist_xp" bson:"heist_xp"`
+	LeaveRefund     bool          `json:"leave_refund" bson:"leave_refund"`
+	LevelXP         int64         `json:"level_xp" bson:"level_xp"`
+	PoliceAlert     time.Duration `json:"police_alert" bson:"police_alert"`
+	SentenceBase    time.Duration `json:"sentence_base" bson:"sentence_base"`
+	SpreeBonus      int64         `json:"spree_bonus" bson:"spree_bonus"`
+	SpreeCap        int64         `json:"spree_cap" bson:"spree_cap"`
+	SpreeMilestones []int64       `json:"spree_milestones" bson:"spree_milestones"`
+	SpreePenalty    int64         `json:"spree_penalty" bson:"spree_penalty"`
+	Theme           string        `json:"theme" bson:"theme"`
+	Targets         string        `json:"targets" bson:"targets"`
+	TargetVote      string        `json:"target_vote" bson:"target_vote"`
+	WaitTime        time.Duration `json:"wait_time" bson:"wait_time"`
 }
 
 // Heist is the data for a heist that is either planned or being executed.
 type Heist struct {
 	Planner     string                       `json:"planner" bson:"planner"`
 	Crew        []string                     `json:"crew" bson:"crew"`
-	Planned     bool                         `json:"planned" bson:"planned"`
-	Started     bool                         `json:"started" bson:"started"`
+	State       HeistState                   `json:"state" bson:"state"`
 	MessageID   string                       `json:"message_id" bson:"message_id"`
+	ChannelID   string                       `json:"channel_id" bson:"channel_id"`
+	Locale      discordgo.Locale             `json:"locale" bson:"locale"`
 	StartTime   time.Time                    `json:"start_time" bson:"start_time"`
+	Votes       map[string]string            `json:"votes,omitempty" bson:"votes,omitempty"`
+	Hardcore    bool                         `json:"hardcore" bson:"hardcore"`
+	Cost        int64                        `json:"cost" bson:"cost"`
 	Interaction *discordgo.InteractionCreate `json:"-" bson:"-"`
-	Mutex       sync.Mutex                   `json:"-" bson:"-"`
+	cancel      chan struct{}                // Closed when the heist is canceled
+	messageSeq  uint64                       // Version of the last heist message that was built
+	sendMutex   sync.Mutex                   // Held while the heist message is being sent
+	sentSeq     uint64                       // Version of the last heist message that was sent
 }
 
 // Player is a specific player of the heist game on a given server.
 type Player struct {
 	ID            string        `json:"_id" bson:"_id"`
 	BailCost      int64         `json:"bail_cost" bson:"bail_cost"`
+	BreakoutTried bool          `json:"breakout_tried" bson:"breakout_tried"`
 	CriminalLevel CriminalLevel `json:"criminal_level" bson:"criminal_level"`
 	DeathTimer    time.Time     `json:"death_timer" bson:"death_timer"`
 	Deaths        int64         `json:"deaths" bson:"deaths"`
+	Fugitive      bool          `json:"fugitive" bson:"fugitive"`
 	JailCounter   int64         `json:"jail_counter" bson:"jail"`
 	Name          string        `json:"name" bson:"name"`
+	NoBail        bool          `json:"no_bail" bson:"no_bail"`
 	OOB           bool          `json:"oob" bson:"oob"`
 	Sentence      time.Duration `json:"sentence" bson:"sentence"`
 	Spree         int64         `json:"spree" bson:"spree"`
 	Status        string        `json:"status" bson:"status"`
 	JailTimer     time.Time     `json:"time_served" bson:"time_served"`
 	TotalJail     int64         `json:"total_jail" bson:"total_jail"`
+	XP            int64         `json:"xp" bson:"xp"`
+	Monthly       HeistStats    `json:"monthly" bson:"monthly"`
+	Lifetime      HeistStats    `json:"lifetime" bson:"lifetime"`
 }
 
 // NewServer creates a new server with the specified ID. It is typically called when
 // the first call from a server is made to the heist bot.
 func NewServer(guildID string) *Server {
-	defaultTheme := os.Getenv("HEIST_DEFAULT_THEME")
-	if defaultTheme == "" {
-		log.Fatal("Default theme not set in environment variable `HEIST_DEFAULT_THEME`")
-	}
+	defaults := botconfig.Get().Heist
+	defaultTheme := defaults.DefaultTheme
 	_, err := GetTheme(defaultTheme)
 	if err != nil {
 		log.Fatal("Unable to load the default theme, error:", err)
@@ -98,24 +125,30 @@ func NewServer(guildID string) *Server {
 		ID: guildID,
 		Config: Config{
 			AlertTime:    time.Time{},
-			BailBase:     250,
-			CrewOutput:   "None",
-			DeathTimer:   45,
+			BailBase:     defaults.BailBase,
+			CrewOutput:   CrewOutputFull,
+			DeathTimer:   defaults.DeathTimer,
 			Hardcore:     false,
-			HeistCost:    1500,
-			PoliceAlert:  60,
-			SentenceBase: 5,
+			HeistCost:    defaults.HeistCost,
+			HeistXP:      defaultHeistXP,
+			LevelXP:      defaultLevelXP,
+			PoliceAlert:  defaults.PoliceAlert,
+			SentenceBase: defaults.SentenceBase,
 			Theme:        defaultTheme,
 			Targets:      defaultTheme,
-			WaitTime:     time.Duration(60 * time.Second),
+			TargetVote:   TargetAuto,
+			WaitTime:     defaults.WaitTime,
 		},
 		Players: make(map[string]*Player, 1),
 		Targets: make(map[string]*Target),
 	}
+	server.Config.setSpreeDefaults()
+	server.Config.setBreakoutDefaults()
 
 	targets, _ := GetTargets(server.Config.Targets)
 	for _, target := range targets.Targets {
 		server.Targets[target.ID] = NewTarget(target.ID, target.CrewSize, target.Success, target.Vault, target.VaultMax)
+		server.Targets[target.ID].Level = target.Level
 	}
 	log.Debugf("Now have %d targets", len(server.Targets))
 
@@ -142,13 +175,26 @@ func NewHeist(server *Server, planner *Player) *Heist {
 	heist := Heist{
 		Planner:   planner.ID,
 		Crew:      make([]string, 0, 5),
+		State:     HeistPlanning,
 		StartTime: time.Now().Add(server.Config.WaitTime),
+		Hardcore:  server.Config.Hardcore,
+		Cost:      server.Config.HeistCost,
+		cancel:    make(chan struct{}),
 	}
 	heist.Crew = append(heist.Crew, heist.Planner)
 
 	return &heist
 }
 
+// entryCost returns the cost to join the heist being planned, or the cost to plan a new heist if there
+// isn't one. The server's mutex must be held by the caller.
+func (s *Server) entryCost() int64 {
+	if s.Heist.isPlanning() {
+		return s.Heist.Cost
+	}
+	return s.Config.HeistCost
+}
+
 // GetServer returns the server for the guild. If the server does not already exist, one is created.
 func GetServer(servers map[string]*Server, guildID string) *Server {
 	server := servers[guildID]
@@ -160,9 +206,76 @@ func GetServer(servers map[string]*Server, guildID string) *Server {
 	return server
 }
 
+// GetServerCount returns the number of servers cached by the heist bot.
+func GetServerCount() int {
+	return len(servers)
+}
+
+// GetActiveHeists returns the size of the crew for each server with a heist that is being
+// planned or executed.
+func GetActiveHeists() map[string]int {
+	active := make(map[string]int)
+	for _, server := range servers {
+		server.Mutex.Lock()
+		if server.Heist != nil {
+			active[server.ID] = len(server.Heist.Crew)
+		}
+		server.Mutex.Unlock()
+	}
+	return active
+}
+
+// GetPlayerStats returns a copy of the heist stats for the member on the guild, or `false` if the
+// member has not taken part in a heist.
+func GetPlayerStats(guildID string, memberID string) (*Player, bool) {
+	server, ok := servers[guildID]
+	if !ok {
+		return nil, false
+	}
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+
+	player, ok := server.Players[memberID]
+	if !ok {
+		return nil, false
+	}
+	stats := *player
+	return &stats, true
+}
+
+// GetServerTargets returns a copy of the heist targets for the guild, sorted by crew size.
+func GetServerTargets(guildID string) []*Target {
+	server, ok := servers[guildID]
+	if !ok {
+		return nil
+	}
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+
+	targets := make([]*Target, 0, len(server.Targets))
+	for _, target := range server.Targets {
+		t := *target
+		targets = append(targets, &t)
+	}
+	sort.SliceStable(targets, func(i, j int) bool {
+		return targets[i].CrewSize < targets[j].CrewSize
+	})
+	return targets
+}
+
+// GetServerConfig returns a copy of the heist configuration for the guild.
+func GetServerConfig(guildID string) *Config {
+	server := GetServer(servers, guildID)
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+
+	cfg := server.Config
+	return &cfg
+}
+
 // LoadServers loads all the heist servers from the store.
 func LoadServers() map[string]*Server {
-	defaultTheme := os.Getenv("HEIST_DEFAULT_THEME")
+	defaultTheme := botconfig.Get().Heist.DefaultTheme
 
 	servers := make(map[string]*Server)
 	serverIDs := store.Store.ListDocuments(HEIST)
@@ -172,26 +285,65 @@ func LoadServers() map[string]*Server {
 		if server.Config.Targets == "" {
 			server.Config.Targets = defaultTheme
 		}
+		if server.Config.LevelXP == 0 {
+			server.Config.LevelXP = defaultLevelXP
+		}
+		if server.Config.HeistXP == 0 {
+			server.Config.HeistXP = defaultHeistXP
+		}
+		// Servers saved before spree rewards were added have no milestones at all, while a server that
+		// turned the milestones off has an empty list.
+		if server.Config.SpreeMilestones == nil {
+			server.Config.setSpreeDefaults()
+		}
+		if server.Config.BreakoutChance == 0 {
+			server.Config.setBreakoutDefaults()
+		}
 
-		// Update the targets to match the configuration, but keep the old vault information
-		// which is being increased to the vault maximum.
-		newTargets := make(map[string]*Target)
-		targets, _ := GetTargets(server.Config.Targets)
-		for _, target := range targets.Targets {
-			oldTarget, ok := server.Targets[target.ID]
-			t := NewTarget(target.ID, target.CrewSize, target.Success, target.Vault, target.VaultMax)
-			if ok {
-				t.Vault = hmath.Min(oldTarget.Vault, target.VaultMax)
-			}
-			newTargets[t.ID] = t
-			log.WithFields(log.Fields{"Target": t.ID, "Server": server.ID}).Debug("Adding target for server")
+		for _, player := range server.Players {
+			player.Lifetime.Deaths = max(player.Lifetime.Deaths, player.Deaths)
+			player.Lifetime.Spree = max(player.Lifetime.Spree, player.Spree)
 		}
-		server.Targets = newTargets
+
+		targets, _ := GetTargets(server.Config.Targets)
+		server.applyTargets(targets)
 		servers[server.ID] = &server
 	}
 	return servers
 }
 
+// applyTargets updates the targets for the server to match the configured targets, but keeps the old vault
+// information which is being increased to the vault maximum. Existing targets are updated in place so a
+// heist in progress keeps using the same target. Custom targets added or changed by the server's admins
+// are kept as they are.
+func (s *Server) applyTargets(targets *Targets) {
+	newTargets := make(map[string]*Target, len(targets.Targets))
+	for _, t := range s.Targets {
+		if t.Custom {
+			newTargets[t.ID] = t
+		}
+	}
+	for _, target := range targets.Targets {
+		t, ok := s.Targets[target.ID]
+		if ok && t.Custom {
+			continue
+		}
+		if ok {
+			t.CrewSize = target.CrewSize
+			t.Success = target.Success
+			t.VaultMax = target.VaultMax
+			t.Level = target.Level
+			t.Vault = hmath.Min(t.Vault, target.VaultMax)
+		} else {
+			t = NewTarget(target.ID, target.CrewSize, target.Success, target.Vault, target.VaultMax)
+			t.Level = target.Level
+		}
+		newTargets[t.ID] = t
+		log.WithFields(logrus.Fields{"Target": t.ID, "Server": s.ID}).Debug("Adding target for server")
+	}
+	s.Targets = newTargets
+}
+
 // GetPlayer returns the player on the server. If the player does not already exist, one is created.
 func (s *Server) GetPlayer(id string, username string, nickname string) *Player {
 	player, ok := s.Players[id]
@@ -256,19 +408,26 @@ func (p *Player) ClearJailAndDeathStatus() {
 	p.BailCost = 0
 	p.Sentence = 0
 	p.JailTimer = time.Time{}
+	p.NoBail = false
 	p.OOB = false
+	p.Fugitive = false
+	p.BreakoutTried = false
 }
 
 // Reset clears the jain and death settings for a player.
 func (p *Player) Reset() {
 	p.Status = FREE
 	p.CriminalLevel = Greenhorn
+	p.XP = 0
 	p.JailCounter = 0
 	p.DeathTimer = time.Time{}
 	p.BailCost = 0
 	p.Sentence = 0
 	p.JailTimer = time.Time{}
+	p.NoBail = false
 	p.OOB = false
+	p.Fugitive = false
+	p.BreakoutTried = false
 }
 
 // String returns a string representation of the server.
@@ -2,14 +2,16 @@ package heist
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
 )
 
 const (
 	TARGET = "target"
+
+	maxTargetName = 32
 )
 
 // Targets is the set of targets for a given theme
@@ -20,11 +22,13 @@ type Targets struct {
 
 // Target is a target of a heist.
 type Target struct {
-	ID       string  `json:"_id" bson:"_id"`
-	CrewSize int64   `json:"crew" bson:"crew"`
-	Success  float64 `json:"success" bson:"success"`
-	Vault    int64   `json:"vault" bson:"vault"`
-	VaultMax int64   `json:"vault_max" bson:"vault_max"`
+	ID       string        `json:"_id" bson:"_id"`
+	CrewSize int64         `json:"crew" bson:"crew"`
+	Success  float64       `json:"success" bson:"success"`
+	Vault    int64         `json:"vault" bson:"vault"`
+	VaultMax int64         `json:"vault_max" bson:"vault_max"`
+	Level    CriminalLevel `json:"level,omitempty" bson:"level,omitempty"`
+	Custom   bool          `json:"custom,omitempty" bson:"custom,omitempty"`
 }
 
 // NewTarget creates a new target for a heist
@@ -39,6 +43,32 @@ func NewTarget(id string, maxCrewSize int64, success float64, vaultCurrent int64
 	return &target
 }
 
+// Validate checks that the target may be used in a heist.
+func (t *Target) Validate() error {
+	var problems []error
+
+	if t.ID == "" {
+		problems = append(problems, errors.New("the name must not be empty"))
+	}
+	if t.CrewSize <= 0 {
+		problems = append(problems, errors.New("the crew size must be positive"))
+	}
+	if t.Success <= 0 || t.Success > 100 {
+		problems = append(problems, errors.New("the success rate must be greater than 0 and at most 100"))
+	}
+	if t.VaultMax <= 0 {
+		problems = append(problems, errors.New("the vault maximum must be positive"))
+	}
+	if t.Vault < 0 || t.Vault > t.VaultMax {
+		problems = append(problems, errors.New("the vault must be between 0 and the vault maximum"))
+	}
+	if t.Level < Greenhorn || t.Level > Immortal {
+		problems = append(problems, errors.New("the criminal level must be between 0 and 100"))
+	}
+
+	return errors.Join(problems...)
+}
+
 // LoadTargets loads the targets that may be used by the heist bot.
 func LoadTargets() map[string]*Targets {
 	targetSet := make(map[string]*Targets)
@@ -54,7 +84,7 @@ func LoadTargets() map[string]*Targets {
 
 // GetTargetSet gets the specified target and returns.
 func GetTargetSet(targetName string) (*Targets, error) {
-	targets, ok := targetSet[targetName]
+	targets, ok := getTargetSet()[targetName]
 	if !ok {
 		msg := targetName + " targets do not exist."
 		log.Warning(msg)
@@ -66,11 +96,11 @@ func GetTargetSet(targetName string) (*Targets, error) {
 
 // GetTargets gets the specified list of targets and returns.
 func GetTargets(targetName string) (*Targets, error) {
-	targets, ok := targetSet[targetName]
+	targets, ok := getTargetSet()[targetName]
 	if !ok {
 		msg := targetName + " targets do not exist."
 		log.Warning(msg)
-		return nil, fmt.Errorf(msg)
+		return nil, fmt.Errorf("%s", msg)
 	}
 
 	return targets, nil
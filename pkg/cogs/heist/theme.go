@@ -3,9 +3,9 @@ package heist
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
 )
 
 const (
@@ -14,17 +14,20 @@ const (
 
 // Theme is a heist theme.
 type Theme struct {
-	ID       string        `json:"_id" bson:"_id"`
-	Good     []GoodMessage `json:"good"`
-	Bad      []BadMessage  `json:"bad"`
-	Jail     string        `json:"jail" bson:"jail"`
-	OOB      string        `json:"oob" bson:"oob"`
-	Police   string        `json:"police" bson:"police"`
-	Bail     string        `json:"bail" bson:"bail"`
-	Crew     string        `json:"crew" bson:"crew"`
-	Sentence string        `json:"sentence" bson:"sentence"`
-	Heist    string        `json:"heist" bson:"heist"`
-	Vault    string        `json:"vault" bson:"vault"`
+	ID              string        `json:"_id" bson:"_id"`
+	Good            []GoodMessage `json:"good"`
+	Bad             []BadMessage  `json:"bad"`
+	Jail            string        `json:"jail" bson:"jail"`
+	OOB             string        `json:"oob" bson:"oob"`
+	Police          string        `json:"police" bson:"police"`
+	Bail            string        `json:"bail" bson:"bail"`
+	Crew            string        `json:"crew" bson:"crew"`
+	Sentence        string        `json:"sentence" bson:"sentence"`
+	Heist           string        `json:"heist" bson:"heist"`
+	Vault           string        `json:"vault" bson:"vault"`
+	LevelUp         string        `json:"level_up,omitempty" bson:"level_up,omitempty"`
+	BreakoutSuccess string        `json:"breakout_success,omitempty" bson:"breakout_success,omitempty"`
+	BreakoutFailure string        `json:"breakout_failure,omitempty" bson:"breakout_failure,omitempty"`
 }
 
 type GoodMessage struct {
@@ -37,12 +40,13 @@ type BadMessage struct {
 	Result  string `json:"result" bson:"result"`
 }
 
-// GetThemeNames returns a list of available themes.
-func GetThemeNames(map[string]*Theme) ([]string, error) {
+// GetThemeNames returns a sorted list of available themes.
+func GetThemeNames() ([]string, error) {
 	var fileNames []string
-	for _, theme := range themes {
+	for _, theme := range getThemes() {
 		fileNames = append(fileNames, theme.ID)
 	}
+	sort.Strings(fileNames)
 
 	return fileNames, nil
 }
@@ -62,11 +66,11 @@ func LoadThemes() map[string]*Theme {
 
 // GetTheme gets the specified theme and returns.
 func GetTheme(themeName string) (*Theme, error) {
-	theme, ok := themes[themeName]
+	theme, ok := getThemes()[themeName]
 	if !ok {
 		msg := "Theme " + themeName + " does not exist."
 		log.Warning(msg)
-		return nil, fmt.Errorf(msg)
+		return nil, fmt.Errorf("%s", msg)
 	}
 
 	return theme, nil
@@ -0,0 +1,214 @@
+package heist
+
+import (
+	"sort"
+
+	"github.com/bwmarrin/discordgo"
+	discmsg "github.com/rbrabson/heist/pkg/msg"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/text/message"
+)
+
+const (
+	TargetAuto    = "auto"    // The target is picked based on the size of the crew
+	TargetPlanner = "planner" // The planner picks the target
+	TargetCrew    = "crew"    // The crew votes on the target
+
+	targetVoteID   = "heist_target_vote"
+	maxVoteOptions = 25
+)
+
+// targetVoteMode returns how the target for a heist is picked on the server.
+func (c *Config) targetVoteMode() string {
+	if c.TargetVote == "" {
+		return TargetAuto
+	}
+	return c.TargetVote
+}
+
+// eligibleTargets returns the targets that allow a crew of the heist's size, sorted by crew size.
+// The server's mutex must be held by the caller.
+func eligibleTargets(heist *Heist, targets map[string]*Target) []*Target {
+	crewSize := int64(len(heist.Crew))
+	eligible := make([]*Target, 0, len(targets))
+	for _, target := range targets {
+		if target.CrewSize >= crewSize {
+			eligible = append(eligible, target)
+		}
+	}
+	sort.Slice(eligible, func(i, j int) bool {
+		if eligible[i].CrewSize != eligible[j].CrewSize {
+			return eligible[i].CrewSize < eligible[j].CrewSize
+		}
+		return eligible[i].ID < eligible[j].ID
+	})
+	return eligible
+}
+
+// countVotes returns the number of votes for each target. Only votes from members of the crew count, and
+// when the planner picks the target, only the planner's vote counts. The server's mutex must be held by
+// the caller.
+func countVotes(heist *Heist, mode string) map[string]int {
+	votes := make(map[string]int)
+	for _, memberID := range heist.Crew {
+		if mode == TargetPlanner && memberID != heist.Planner {
+			continue
+		}
+		if targetID, ok := heist.Votes[memberID]; ok {
+			votes[targetID]++
+		}
+	}
+	return votes
+}
+
+// chooseTarget returns the target for the heist. If the crew or planner voted for a target that still allows
+// the crew's size, then the target with the most votes is used. Ties go to the planner's choice, and then to
+// the target with the smallest crew size. Otherwise, the target is picked based on the size of the crew. The
+// server's mutex must be held by the caller.
+func chooseTarget(server *Server) *Target {
+	log.Trace("--> chooseTarget")
+	defer log.Trace("<-- chooseTarget")
+
+	heist := server.Heist
+	mode := server.Config.targetVoteMode()
+	if mode == TargetAuto {
+		return getTarget(heist, server.unlockedTargets())
+	}
+
+	votes := countVotes(heist, mode)
+	target := leadingTarget(heist, eligibleTargets(heist, server.unlockedTargets()), votes)
+	if target == nil {
+		return getTarget(heist, server.unlockedTargets())
+	}
+	log.WithFields(logrus.Fields{"Target": target.ID, "Votes": votes[target.ID]}).Debug("Heist target chosen by vote")
+	return target
+}
+
+// leadingTarget returns the eligible target with the most votes, or `nil` if none of them have a vote. Ties
+// go to the planner's choice, and then to the target with the smallest crew size. The server's mutex must be
+// held by the caller.
+func leadingTarget(heist *Heist, eligible []*Target, votes map[string]int) *Target {
+	plannerVote := heist.Votes[heist.Planner]
+	var target *Target
+	for _, possible := range eligible {
+		count := votes[possible.ID]
+		if count == 0 {
+			continue
+		}
+		if target == nil || count > votes[target.ID] || (count == votes[target.ID] && possible.ID == plannerVote) {
+			target = possible
+		}
+	}
+	return target
+}
+
+// voteComponents returns the select menu used to vote on the target for the heist, and a description of the
+// target that is currently winning the vote. The server's mutex must be held by the caller.
+func voteComponents(p *message.Printer, server *Server, disabled bool) (discordgo.MessageComponent, string) {
+	heist := server.Heist
+	mode := server.Config.targetVoteMode()
+	theme := getThemes()[server.Config.Theme]
+
+	eligible := eligibleTargets(heist, server.unlockedTargets())
+	if len(eligible) > maxVoteOptions {
+		eligible = eligible[:maxVoteOptions]
+	}
+	votes := countVotes(heist, mode)
+
+	options := make([]discordgo.SelectMenuOption, 0, len(eligible))
+	for _, target := range eligible {
+		label := target.ID
+		if votes[target.ID] == 1 {
+			label = p.Sprintf("%s (1 vote)", target.ID)
+		} else if votes[target.ID] > 1 {
+			label = p.Sprintf("%s (%d votes)", target.ID, votes[target.ID])
+		}
+		options = append(options, discordgo.SelectMenuOption{
+			Label:       label,
+			Value:       target.ID,
+			Description: p.Sprintf("%d%% success, %d credits in the %s", calculateSuccessRate(heist, target), target.Vault, theme.Vault),
+		})
+	}
+
+	placeholder := "Vote for the target"
+	if mode == TargetPlanner {
+		placeholder = "The planner picks the target"
+	}
+	menu := discordgo.ActionsRow{
+		Components: []discordgo.MessageComponent{
+			discordgo.SelectMenu{
+				MenuType:    discordgo.StringSelectMenu,
+				CustomID:    targetVoteID,
+				Placeholder: placeholder,
+				Options:     options,
+				Disabled:    disabled || len(options) == 0,
+			},
+		},
+	}
+
+	status := "No votes yet"
+	if leader := leadingTarget(heist, eligible, votes); leader != nil {
+		status = leader.ID
+	}
+	return menu, status
+}
+
+// voteForTarget records a vote for the target of a heist that is being planned.
+func voteForTarget(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> voteForTarget")
+	defer log.Trace("<-- voteForTarget")
+
+	server := GetServer(servers, i.GuildID)
+
+	response, heistMsg := recordVote(server, i)
+	discmsg.SendEphemeralResponse(s, i, response)
+	sendHeistMessage(s, heistMsg)
+}
+
+// recordVote records the member's vote for the target of the heist being planned, returning the response for
+// the member and the updated heist message.
+func recordVote(server *Server, i *discordgo.InteractionCreate) (string, *heistMessage) {
+	theme := getThemes()[server.Config.Theme]
+
+	server.Mutex.Lock()
+	defer server.Mutex.Unlock()
+	heist := server.Heist
+	if !heist.isPlanning() {
+		return "No " + theme.Heist + " is being planned.", nil
+	}
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return "No target was picked.", nil
+	}
+	targetID := values[0]
+
+	mode := server.Config.targetVoteMode()
+	memberID := i.Member.User.ID
+	var response string
+	switch {
+	case mode == TargetAuto:
+		response = "The target is picked based on the size of the " + theme.Crew + "."
+	case !contains(heist.Crew, memberID):
+		response = "You must join the " + theme.Heist + " to vote on the target."
+	case mode == TargetPlanner && memberID != heist.Planner:
+		response = "Only the planner can pick the target."
+	default:
+		var target *Target
+		for _, t := range eligibleTargets(heist, server.unlockedTargets()) {
+			if t.ID == targetID {
+				target = t
+			}
+		}
+		if target == nil {
+			response = "That target can't be used by a " + theme.Crew + " this size."
+			break
+		}
+		if heist.Votes == nil {
+			heist.Votes = make(map[string]string)
+		}
+		heist.Votes[memberID] = target.ID
+		response = "You voted for " + target.ID + "."
+	}
+
+	return response, newHeistMessage(heist.Interaction, "update")
+}
@@ -5,9 +5,9 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/event"
 	"github.com/rbrabson/heist/pkg/format"
 	discmsg "github.com/rbrabson/heist/pkg/msg"
-	log "github.com/sirupsen/logrus"
 )
 
 var (
@@ -50,6 +50,12 @@ func payday(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	economy.SaveBank(bank)
 	member.NextPayday = time.Now().Add(server.PaydayFrequency)
 	saveServer(server)
+	event.Publish(event.PaydayCollected{
+		GuildID:    i.GuildID,
+		MemberID:   i.Member.User.ID,
+		Amount:     int(server.PaydayAmount),
+		NextPayday: member.NextPayday,
+	})
 
 	msg := p.Sprintf("You deposited your check of %d into your bank account. You now have %d credits.", server.PaydayAmount, account.CurrentBalance)
 	discmsg.EditResponse(s, i, msg)
@@ -0,0 +1,8 @@
+package payday
+
+import "errors"
+
+var (
+	ErrInvalidAmount    = errors.New("the payday amount must not be negative")
+	ErrInvalidFrequency = errors.New("the payday frequency must be between 1 minute and 7 days")
+)
@@ -0,0 +1,6 @@
+package payday
+
+import "github.com/rbrabson/heist/pkg/logging"
+
+// log is the logger used by the payday cog.
+var log = logging.Logger("payday")
@@ -1,19 +1,22 @@
 package payday
 
 import (
-	"fmt"
-	"sort"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/help"
+	"github.com/rbrabson/heist/pkg/logging"
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
 
 const (
 	PAYDAY = "payday"
+
+	minFrequency = time.Minute
+	maxFrequency = 7 * 24 * time.Hour
 )
 
 // server is the server/guild into which payday checks are deposited.
@@ -37,11 +40,12 @@ func init() {
 // newServer creates a new server/guild
 func newServer(serverID string) *server {
 	members := make(map[string]*member)
+	defaults := config.Get().Payday
 	server := &server{
 		ID:              serverID,
 		Members:         members,
-		PaydayAmount:    5000,
-		PaydayFrequency: time.Duration(23 * time.Hour),
+		PaydayAmount:    defaults.Amount,
+		PaydayFrequency: defaults.Frequency,
 	}
 	servers[server.ID] = server
 	saveServer(server)
@@ -49,6 +53,11 @@ func newServer(serverID string) *server {
 	return server
 }
 
+// GetServerCount returns the number of servers cached by the payday bot.
+func GetServerCount() int {
+	return len(servers)
+}
+
 // getServer returns the server/guild, creating a new one if necessary.
 func getServer(serverID string) *server {
 	server, ok := servers[serverID]
@@ -83,7 +92,7 @@ func (s *server) getMember(memberID string) *member {
 func getPrinter(i *discordgo.InteractionCreate) *message.Printer {
 	tag, err := language.Parse(string(i.Locale))
 	if err != nil {
-		log.Error("Unable to parse locale, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to parse locale, error:", err)
 		tag = language.English
 	}
 	return message.NewPrinter(tag)
@@ -99,14 +108,42 @@ func GetPaydayAmount(serverID string) int64 {
 }
 
 // SetPaydayAmount sets the amount of credits a player deposits into their account on a given payday.
-func SetPaydayAmount(serverID string, amount int64) {
+func SetPaydayAmount(serverID string, amount int64) error {
 	log.Trace("--> SetPaydayAmount")
 	defer log.Trace("<-- SetPaydayAmount")
 
+	if amount < 0 {
+		return ErrInvalidAmount
+	}
 	server := getServer(serverID)
 	server.PaydayAmount = amount
 
 	saveServer(server)
+	return nil
+}
+
+// GetPaydayFrequency returns how often a player may collect a payday.
+func GetPaydayFrequency(serverID string) time.Duration {
+	log.Trace("--> GetPaydayFrequency")
+	defer log.Trace("<-- GetPaydayFrequency")
+
+	server := getServer(serverID)
+	return server.PaydayFrequency
+}
+
+// SetPaydayFrequency sets how often a player may collect a payday.
+func SetPaydayFrequency(serverID string, frequency time.Duration) error {
+	log.Trace("--> SetPaydayFrequency")
+	defer log.Trace("<-- SetPaydayFrequency")
+
+	if frequency < minFrequency || frequency > maxFrequency {
+		return ErrInvalidFrequency
+	}
+	server := getServer(serverID)
+	server.PaydayFrequency = frequency
+
+	saveServer(server)
+	return nil
 }
 
 // loadServers loads payday information for all servers from the store.
@@ -141,23 +178,16 @@ func getMemberName(username string, nickname string) string {
 	return username
 }
 
-// GetMemberHelp returns help information about the heist bot commands
-func GetMemberHelp() []string {
-	help := make([]string, 0, 1)
-
-	for _, command := range commands {
-		commandDescription := fmt.Sprintf("- **/%s**:  %s\n", command.Name, command.Description)
-		help = append(help, commandDescription)
+// GetHelp returns help information about the payday command.
+func GetHelp() *help.Topic {
+	return &help.Topic{
+		Name:        "Payday",
+		Description: "Collect credits for your bank account once a day.",
+		Commands:    commands,
+		Details: map[string]*help.Detail{
+			"payday": {
+				Examples: []string{"/payday"},
+			},
+		},
 	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Payday**\n"}, help...)
-
-	return help
-}
-
-// GetAdminHelp returns help information about the heist bot commands
-func GetAdminHelp() []string {
-	return nil
 }
@@ -7,11 +7,16 @@ import (
 	"time"
 
 	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
 	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/event"
 	"github.com/rbrabson/heist/pkg/format"
+	"github.com/rbrabson/heist/pkg/logging"
 	"github.com/rbrabson/heist/pkg/math"
+	"github.com/rbrabson/heist/pkg/member"
 	"github.com/rbrabson/heist/pkg/msg"
-	log "github.com/sirupsen/logrus"
+	"github.com/rbrabson/heist/pkg/permission"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/text/language"
 	"golang.org/x/text/message"
 )
@@ -55,6 +60,14 @@ var (
 	commandHandlers = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
 		"race":       race,
 		"race-admin": admin,
+		"Race stats": raceStatsForMember,
+	}
+
+	userCommands = []*discordgo.ApplicationCommand{
+		{
+			Name: "Race stats",
+			Type: discordgo.UserApplicationCommand,
+		},
 	}
 
 	playerCommands = []*discordgo.ApplicationCommand{
@@ -83,8 +96,9 @@ var (
 
 	adminCommands = []*discordgo.ApplicationCommand{
 		{
-			Name:        "race-admin",
-			Description: "Race game admin commands.",
+			Name:                     "race-admin",
+			Description:              "Race game admin commands.",
+			DefaultMemberPermissions: &permission.AdminPermissions,
 			Options: []*discordgo.ApplicationCommandOption{
 				{
 					Name:        "reset",
@@ -105,7 +119,7 @@ func getPrinter(i *discordgo.InteractionCreate) *message.Printer {
 
 	tag, err := language.Parse(string(i.Locale))
 	if err != nil {
-		log.Error("Unable to parse locale, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to parse locale, error:", err)
 		tag = language.English
 	}
 	return message.NewPrinter(tag)
@@ -138,7 +152,7 @@ func getRacerButtons(race *Race) []discordgo.ActionsRow {
 
 		row := discordgo.ActionsRow{Components: buttons}
 		rows = append(rows, row)
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"numRacers": len(race.Racers),
 			"buttons":   len(buttons),
 			"row":       len(rows),
@@ -178,7 +192,7 @@ func raceMessage(s *discordgo.Session, i *discordgo.InteractionCreate, action st
 		msg = "Not enough players entered the race, so it was cancelled."
 	} else {
 		errMsg := fmt.Sprintf("Unrecognized action: %s", action)
-		log.Error(errMsg)
+		logging.WithInteraction(log, i).Error(errMsg)
 		return errors.New(errMsg)
 	}
 
@@ -373,15 +387,15 @@ func prepareRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	server.Race.Interaction = i
 
 	player := server.GetPlayer(i.Member.User.ID, i.Member.User.Username, i.Member.Nick)
-	mode := Modes[server.Config.Mode]
+	mode := getModes()[server.Config.Mode]
 	racer := NewRacer(player, mode)
 	server.Race.Racers = append(server.Race.Racers, racer)
 
 	err := raceMessage(s, i, "start")
 	if err != nil {
-		log.Error("Unable to update the race message, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to update the race message, error:", err)
 	}
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"Name":      player.Name,
 		"ID":        player.ID,
 		"Character": racer.Character.Emoji,
@@ -399,7 +413,7 @@ func prepareRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		time.Sleep(timeToWait)
 		err = raceMessage(s, i, "update")
 		if err != nil {
-			log.Error("Unable to update the time for the race message, error:", err)
+			logging.WithInteraction(log, i).Error("Unable to update the time for the race message, error:", err)
 		}
 	}
 
@@ -416,7 +430,7 @@ func startRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	server.mutex.Lock()
 	if len(server.Race.Racers) < server.Config.MinRacers {
-		log.WithFields(log.Fields{"Number": len(server.Race.Racers)}).Info("Race cancelled due to lack of racers.")
+		log.WithFields(logrus.Fields{"Number": len(server.Race.Racers)}).Info("Race cancelled due to lack of racers.")
 		raceMessage(s, i, "cancelled")
 		server.Race = nil
 		server.mutex.Unlock()
@@ -425,7 +439,7 @@ func startRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	err := raceMessage(s, i, "betting")
 	if err != nil {
-		log.Error("Unable to update the race message for betting, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to update the race message for betting, error:", err)
 	}
 	server.mutex.Unlock()
 
@@ -446,14 +460,14 @@ func startRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		time.Sleep(timeToWait)
 		err = raceMessage(s, i, "betting")
 		if err != nil {
-			log.Error("Unable to update the time for the race message, error:", err)
+			logging.WithInteraction(log, i).Error("Unable to update the time for the race message, error:", err)
 		}
 	}
 
 	server.mutex.Lock()
 	err = raceMessage(s, i, "started")
 	if err != nil {
-		log.Error("Unable to update the race message for the race starting, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to update the race message for the race starting, error:", err)
 	}
 	server.Race.Started = true
 	server.Race.Planned = false
@@ -463,7 +477,7 @@ func startRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	err = raceMessage(s, i, "ended")
 	if err != nil {
-		log.Error("Unable to update the race message, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to update the race message, error:", err)
 	}
 
 	raceMessage(s, i, "ended")
@@ -506,10 +520,40 @@ func startRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	sendRaceResults(s, i.ChannelID, server)
 	server.GamesPlayed++
 	server.LastRaceEnded = time.Now()
+	publishRaceFinished(server)
 	server.Race = nil
 	SaveServer(server)
 }
 
+// publishRaceFinished publishes the results of the race and of each bet placed on the race.
+func publishRaceFinished(server *Server) {
+	racers := make([]*event.RaceResult, 0, len(server.Race.Racers))
+	for index, racer := range server.Race.Racers {
+		racers = append(racers, &event.RaceResult{
+			MemberID: racer.Player.ID,
+			Name:     racer.Player.Name,
+			Position: index + 1,
+			Prize:    racer.Prize,
+		})
+	}
+	event.Publish(event.RaceFinished{
+		GuildID: server.ID,
+		Racers:  racers,
+		Time:    server.LastRaceEnded,
+	})
+
+	for _, bet := range server.Race.Bets {
+		event.Publish(event.BetSettled{
+			GuildID:  server.ID,
+			MemberID: bet.ID,
+			Name:     bet.Name,
+			RacerID:  bet.Racer.Player.ID,
+			Bet:      bet.Bet,
+			Winnings: bet.Winnings,
+		})
+	}
+}
+
 // joinRace attempts to join a race that is getting ready to start.
 func joinRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> joinRace")
@@ -518,7 +562,7 @@ func joinRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	p := message.NewPrinter(language.English)
 
 	server := GetServer(i.GuildID)
-	mode := Modes[server.Config.Mode]
+	mode := getModes()[server.Config.Mode]
 
 	server.mutex.Lock()
 	defer server.mutex.Unlock()
@@ -547,9 +591,9 @@ func joinRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	server.Race.Racers = append(server.Race.Racers, racer)
 	err := raceMessage(s, server.Race.Interaction, "join")
 	if err != nil {
-		log.Error("Unable to update the race message, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to update the race message, error:", err)
 	}
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"Name":      player.Name,
 		"ID":        player.ID,
 		"Character": racer.Character.Emoji,
@@ -559,12 +603,39 @@ func joinRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 // raceStats returns a players race stats.
 func raceStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	log.Trace("--> joinRace")
-	defer log.Trace("<-- joinRace")
+	log.Trace("--> raceStats")
+	defer log.Trace("<-- raceStats")
+
+	sendRaceStats(s, i, i.Member)
+}
+
+// raceStatsForMember returns the race stats for the member selected from the user context menu.
+func raceStatsForMember(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> raceStatsForMember")
+	defer log.Trace("<-- raceStatsForMember")
+
+	target := member.GetTarget(i)
+	if target == nil {
+		msg.SendEphemeralResponse(s, i, "The member could not be found.")
+		return
+	}
+	server := GetServer(i.GuildID)
+	if _, ok := server.Players[target.User.ID]; !ok {
+		msg.SendEphemeralResponse(s, i, member.GetName(target.User.Username, target.Nick)+" has not entered a race.")
+		return
+	}
+
+	sendRaceStats(s, i, target)
+}
+
+// sendRaceStats sends the race stats for the member.
+func sendRaceStats(s *discordgo.Session, i *discordgo.InteractionCreate, m *discordgo.Member) {
+	log.Trace("--> sendRaceStats")
+	defer log.Trace("<-- sendRaceStats")
 
 	p := getPrinter(i)
 	server := GetServer(i.GuildID)
-	player := server.GetPlayer(i.Member.User.ID, i.Member.User.Username, i.Member.Nick)
+	player := server.GetPlayer(m.User.ID, m.User.Username, m.Nick)
 
 	var betPercentage float64
 	if player.Results.BetsPlaced > 0 {
@@ -634,7 +705,7 @@ func raceStats(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		},
 	})
 	if err != nil {
-		log.Error("Unable to send the player stats to Discord, error:", err)
+		logging.WithInteraction(log, i).Error("Unable to send the player stats to Discord, error:", err)
 	}
 }
 
@@ -643,7 +714,7 @@ func raceLeaderboard(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> raceLeaderboard")
 	defer log.Trace("<-- raceLeaderboard")
 
-	lb := getLeaderboard(i.GuildID, 10)
+	lb := GetLeaderboard(i.GuildID, 10)
 
 	p := getPrinter(i)
 	embeds := formatAccounts(p, "Race Leaderboard", lb)
@@ -695,7 +766,7 @@ func betOnRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	server.Race.Bets = append(server.Race.Bets, bettor)
 	account.WithdrawCredits(bettor.Bet)
 	economy.SaveBank(bank)
-	log.WithFields(log.Fields{
+	log.WithFields(logrus.Fields{
 		"Name":  player.Name,
 		"ID":    player.ID,
 		"Racer": racer.Player.Name,
@@ -712,8 +783,8 @@ func resetRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> resetRace")
 	defer log.Trace("<-- resetRace")
 
-	server := GetServer(i.GuildID)
-	server.Race = nil
+	racerNames := ResetRace(i.GuildID)
+	audit.Record(s, i, "/race-admin reset", "Race", strings.Join(racerNames, ", "), nil)
 	// Uncomment this out if we change to mute the channel again
 	// mute := channel.NewChannelMute(s, i)
 	// mute.UnmuteChannel()
@@ -722,8 +793,9 @@ func resetRace(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 // GetCommands ret urns the component handlers, command handlers, and commands for the Race game.
 func GetCommands() (map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate), []*discordgo.ApplicationCommand) {
-	commands := make([]*discordgo.ApplicationCommand, 0, len(adminCommands)+len(playerCommands))
+	commands := make([]*discordgo.ApplicationCommand, 0, len(adminCommands)+len(playerCommands)+len(userCommands))
 	commands = append(commands, adminCommands...)
 	commands = append(commands, playerCommands...)
+	commands = append(commands, userCommands...)
 	return componentHandlers, commandHandlers, commands
 }
@@ -7,41 +7,42 @@ import (
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/olekukonko/tablewriter"
-	log "github.com/sirupsen/logrus"
+	"github.com/rbrabson/heist/pkg/math"
 	"golang.org/x/text/message"
 )
 
-type leaderboardAccount struct {
-	name    string
-	balance int
+// LeaderboardAccount is a single entry in a leaderboard.
+type LeaderboardAccount struct {
+	Name    string `json:"name"`
+	Balance int    `json:"balance"`
 }
 
-// getLeaderboard returns the race leaderboard for all players on a given server
-func getLeaderboard(serverID string, limit int) []leaderboardAccount {
-	log.Trace("--> getLeaderboard")
-	defer log.Trace("<-- getLeaderboard")
+// GetLeaderboard returns the race leaderboard for all players on a given server
+func GetLeaderboard(serverID string, limit int) []LeaderboardAccount {
+	log.Trace("--> GetLeaderboard")
+	defer log.Trace("<-- GetLeaderboard")
 
 	server := GetServer(serverID)
 	players := server.Players
-	lb := make([]leaderboardAccount, 0, len(players))
+	lb := make([]LeaderboardAccount, 0, len(players))
 	for _, player := range players {
 		balance := player.Results.Earnings + (player.Results.BetEarnings - (server.Config.BetAmount * player.Results.BetsPlaced))
-		lbAccount := leaderboardAccount{
-			name:    player.Name,
-			balance: balance,
+		lbAccount := LeaderboardAccount{
+			Name:    player.Name,
+			Balance: balance,
 		}
 		lb = append(lb, lbAccount)
 	}
 
 	sort.Slice(lb, func(i, j int) bool {
-		return lb[i].balance > lb[j].balance
+		return lb[i].Balance > lb[j].Balance
 	})
 
-	return lb[:limit]
+	return lb[:math.Min(limit, len(lb))]
 }
 
 // formatAccounts formats the leaderboard to be sent to a Discord server
-func formatAccounts(p *message.Printer, title string, accounts []leaderboardAccount) []*discordgo.MessageEmbed {
+func formatAccounts(p *message.Printer, title string, accounts []LeaderboardAccount) []*discordgo.MessageEmbed {
 	log.Trace("--> formatAccounts")
 	defer log.Trace("<-- formatAccounts")
 
@@ -60,7 +61,7 @@ func formatAccounts(p *message.Printer, title string, accounts []leaderboardAcco
 	table.SetNoWhiteSpace(true)
 	table.SetHeader([]string{"#", "Name", "Balance"})
 	for i, account := range accounts {
-		data := []string{strconv.Itoa(i + 1), account.name, p.Sprintf("%d", account.balance)}
+		data := []string{strconv.Itoa(i + 1), account.Name, p.Sprintf("%d", account.Balance)}
 		table.Append(data)
 	}
 	table.Render()
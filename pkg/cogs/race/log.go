@@ -0,0 +1,6 @@
+package race
+
+import "github.com/rbrabson/heist/pkg/logging"
+
+// log is the logger used by the race cog.
+var log = logging.Logger("race")
@@ -2,10 +2,12 @@ package race
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"sync/atomic"
 
-	log "github.com/sirupsen/logrus"
-
+	"github.com/rbrabson/heist/pkg/config"
 	"github.com/rbrabson/heist/pkg/store"
 )
 
@@ -14,7 +16,7 @@ const (
 )
 
 var (
-	Modes map[string]*Mode
+	modes atomic.Pointer[map[string]*Mode]
 )
 
 // Mode represents the type of characters and symbols used in the race.
@@ -31,12 +33,13 @@ type Character struct {
 	Movement string `json:"movement" bson:"movement"` // The movement for the race character
 }
 
-// GetModeNames returns a list of available race modes.
-func GetModeNames(modes map[string]*Mode) ([]string, error) {
+// GetModeNames returns a sorted list of available race modes.
+func GetModeNames() ([]string, error) {
 	var fileNames []string
-	for _, mode := range modes {
+	for _, mode := range getModes() {
 		fileNames = append(fileNames, mode.ID)
 	}
+	sort.Strings(fileNames)
 
 	return fileNames, nil
 }
@@ -54,13 +57,82 @@ func LoadModes() map[string]*Mode {
 	return modes
 }
 
+// getModes returns the race modes that may be used by the race game. The modes are replaced as a
+// whole when they are reloaded, so a race always sees a consistent set of modes.
+func getModes() map[string]*Mode {
+	m := modes.Load()
+	if m == nil {
+		return nil
+	}
+	return *m
+}
+
+// Catalog is a set of race modes that has been loaded from the store and validated, but isn't used by
+// the race game until it is applied.
+type Catalog struct {
+	modes map[string]*Mode
+}
+
+// LoadCatalog loads the race modes from the store and validates them. They are only returned if they
+// are valid.
+func LoadCatalog() (*Catalog, error) {
+	log.Trace("--> race.LoadCatalog")
+	defer log.Trace("<-- race.LoadCatalog")
+
+	newModes := LoadModes()
+	err := validateModes(newModes)
+	if err != nil {
+		return nil, err
+	}
+	return &Catalog{modes: newModes}, nil
+}
+
+// Apply replaces the race modes used by the race game with the ones in the catalog.
+func (c *Catalog) Apply() {
+	modes.Store(&c.modes)
+	log.WithField("Modes", len(c.modes)).Info("Reloaded the race modes")
+}
+
+// Count returns the number of race modes in the catalog.
+func (c *Catalog) Count() int {
+	return len(c.modes)
+}
+
+// validateModes checks that the race modes are usable by the race game, including by the servers
+// that are already using them.
+func validateModes(newModes map[string]*Mode) error {
+	var problems []error
+
+	for id, mode := range newModes {
+		if id == "" {
+			problems = append(problems, errors.New("a race mode is missing its ID"))
+			continue
+		}
+		if len(mode.Characters) == 0 {
+			problems = append(problems, fmt.Errorf("race mode `%s` has no characters", id))
+		}
+	}
+
+	defaultMode := config.Get().Race.Mode
+	if _, ok := newModes[defaultMode]; !ok {
+		problems = append(problems, fmt.Errorf("the default race mode `%s` is missing", defaultMode))
+	}
+	for _, server := range Servers {
+		if _, ok := newModes[server.Config.Mode]; !ok {
+			problems = append(problems, fmt.Errorf("race mode `%s` is missing but is used by server %s", server.Config.Mode, server.ID))
+		}
+	}
+
+	return errors.Join(problems...)
+}
+
 // Getode gets the specified race mode.
 func GetMode(modeName string) (*Mode, error) {
-	theme, ok := Modes[modeName]
+	theme, ok := getModes()[modeName]
 	if !ok {
 		msg := "Race mode " + modeName + " does not exist."
 		log.Warning(msg)
-		return nil, fmt.Errorf(msg)
+		return nil, fmt.Errorf("%s", msg)
 	}
 
 	return theme, nil
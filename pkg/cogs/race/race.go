@@ -9,10 +9,11 @@ import (
 	"time"
 	"unicode/utf8"
 
+	"github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/help"
 	"github.com/rbrabson/heist/pkg/math"
 
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
 
 	"github.com/bwmarrin/discordgo"
 )
@@ -122,6 +123,25 @@ func NewServer(guildID string) *Server {
 	return server
 }
 
+// GetServerCount returns the number of servers cached by the race game.
+func GetServerCount() int {
+	return len(Servers)
+}
+
+// GetActiveRaces returns the number of racers for each server with a race that is being
+// planned or run.
+func GetActiveRaces() map[string]int {
+	active := make(map[string]int)
+	for _, server := range Servers {
+		server.mutex.Lock()
+		if server.Race != nil {
+			active[server.ID] = len(server.Race.Racers)
+		}
+		server.mutex.Unlock()
+	}
+	return active
+}
+
 // GetServer gets a server for the given guild ID, creating a new one if necessary.
 func GetServer(guildID string) *Server {
 	log.Trace("--> GetServer")
@@ -135,30 +155,58 @@ func GetServer(guildID string) *Server {
 	return server
 }
 
+// GetPlayerStats returns a copy of the race stats for the member on the guild, or `false` if the
+// member has not taken part in a race.
+func GetPlayerStats(guildID string, memberID string) (*Player, bool) {
+	server, ok := Servers[guildID]
+	if !ok {
+		return nil, false
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	player, ok := server.Players[memberID]
+	if !ok {
+		return nil, false
+	}
+	stats := *player
+	return &stats, true
+}
+
+// GetServerConfig returns a copy of the race configuration for the guild.
+func GetServerConfig(guildID string) *Config {
+	server := GetServer(guildID)
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	cfg := *server.Config
+	return &cfg
+}
+
 // NewConfig creates a configuration for a new server. The configuration will use the default values, which
 // may be overwritten using commands sent to the bot.
 func NewConfig() *Config {
 	log.Trace("--> NewConfig")
 	defer log.Trace("<-- NewConfig")
 
-	modeName := "clash"
-	mode, ok := Modes[modeName]
+	defaults := config.Get().Race
+	mode, ok := getModes()[defaults.Mode]
 	if !ok {
-		log.Fatalf("Unable to load characters for mode %s", modeName)
+		log.Fatalf("Unable to load characters for mode %s", defaults.Mode)
 	}
-	config := &Config{
-		BetAmount:        100,
-		Currency:         "credit",
+	cfg := &Config{
+		BetAmount:        defaults.BetAmount,
+		Currency:         defaults.Currency,
 		Mode:             mode.ID,
-		PrizeMin:         750,
-		PrizeMax:         1250,
-		MinRacers:        2,
-		MaxRacers:        10,
-		WaitForJoin:      time.Duration(30 * time.Second),
-		WaitForBetting:   time.Duration(30 * time.Second),
-		WaitBetweenRaces: time.Duration(1 * time.Minute),
+		PrizeMin:         defaults.PrizeMin,
+		PrizeMax:         defaults.PrizeMax,
+		MinRacers:        defaults.MinRacers,
+		MaxRacers:        defaults.MaxRacers,
+		WaitForJoin:      defaults.WaitForJoin,
+		WaitForBetting:   defaults.WaitForBetting,
+		WaitBetweenRaces: defaults.WaitBetweenRaces,
 	}
-	return config
+	return cfg
 }
 
 // NewPlayer creates a new race game player for the server.
@@ -326,7 +374,7 @@ func (s *Server) RunRace(channelID string) {
 	log.Trace("--> RunRace")
 	defer log.Trace("<-- RunRace")
 
-	mode := Modes[s.Config.Mode]
+	mode := getModes()[s.Config.Mode]
 	racers := s.Race.Racers
 	track := getCurrentTrack(racers, mode)
 	message, err := session.ChannelMessageSend(channelID, fmt.Sprintf("%s\n", track))
@@ -441,41 +489,41 @@ func SaveServer(server *Server) {
 	store.Store.Save(RACE, server.ID, server)
 }
 
-// GetMemberHelp returns help information about the race game commands for regular members.
-func GetMemberHelp() []string {
-	help := make([]string, 0, len(playerCommands[0].Options))
-
-	for _, subcommand := range playerCommands[0].Options {
-		commandDescription := fmt.Sprintf("- **/race %s**:  %s\n", subcommand.Name, subcommand.Description)
-		help = append(help, commandDescription)
+// GetHelp returns help information about the race commands.
+func GetHelp() *help.Topic {
+	commands := make([]*discordgo.ApplicationCommand, 0, len(playerCommands)+len(userCommands)+len(adminCommands))
+	commands = append(commands, playerCommands...)
+	commands = append(commands, userCommands...)
+	commands = append(commands, adminCommands...)
+
+	return &help.Topic{
+		Name:        "Race",
+		Description: "Enter races against other members, or bet on who will win.",
+		Commands:    commands,
+		Details: map[string]*help.Detail{
+			"race start": {
+				Examples: []string{"/race start"},
+			},
+			"race stats": {
+				Examples: []string{"/race stats"},
+			},
+			"race leaderboard": {
+				Examples: []string{"/race leaderboard"},
+			},
+			"race-admin reset": {
+				Examples: []string{"/race-admin reset"},
+			},
+		},
 	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Race**\n"}, help...)
-
-	return help
-}
-
-// GetAdminHelp returns help information about the race game for administrators.
-func GetAdminHelp() []string {
-	help := make([]string, 0, len(adminCommands[0].Options))
-
-	for _, command := range adminCommands[0].Options {
-		commandDescription := fmt.Sprintf("- **/race-admin %s**:  %s\n", command.Name, command.Description)
-		help = append(help, commandDescription)
-	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Race**\n"}, help...)
-
-	return help
 }
 
 // Start initializes anything needed by the race game.
 func Start(s *discordgo.Session) {
 	session = s
-	Modes = LoadModes()
+	loaded := LoadModes()
+	modes.Store(&loaded)
+	if _, ok := getModes()[config.Get().Race.Mode]; !ok {
+		log.Fatalf("The default race mode `%s` does not exist; set race.mode to an existing mode", config.Get().Race.Mode)
+	}
 	LoadServers()
 }
@@ -0,0 +1,103 @@
+package race
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rbrabson/heist/pkg/config"
+)
+
+const (
+	maxWait = 24 * time.Hour
+)
+
+// Validate checks that the race configuration may be used by a server. All problems that are found are
+// returned in a single error.
+func (c *Config) Validate() error {
+	var problems []error
+
+	if _, ok := getModes()[c.Mode]; !ok {
+		problems = append(problems, fmt.Errorf("race mode %s does not exist", c.Mode))
+	}
+	if c.Currency == "" {
+		problems = append(problems, errors.New("the currency must not be empty"))
+	}
+	if c.BetAmount < 0 {
+		problems = append(problems, errors.New("the bet amount must not be negative"))
+	}
+	if c.PrizeMin < 0 || c.PrizeMin > c.PrizeMax {
+		problems = append(problems, errors.New("the minimum prize must not be negative, and must not be more than the maximum prize"))
+	}
+	if c.MinRacers < 1 || c.MinRacers > c.MaxRacers {
+		problems = append(problems, errors.New("the minimum racers must be at least 1, and must not be more than the maximum racers"))
+	}
+	if c.MaxRacers > config.MaxRacers {
+		problems = append(problems, fmt.Errorf("the maximum racers must not be more than %d", config.MaxRacers))
+	}
+	if c.WaitForJoin <= 0 || c.WaitForJoin > maxWait ||
+		c.WaitForBetting <= 0 || c.WaitForBetting > maxWait ||
+		c.WaitBetweenRaces <= 0 || c.WaitBetweenRaces > maxWait {
+		problems = append(problems, errors.New("the wait times must be between 1 second and 24 hours"))
+	}
+
+	return errors.Join(problems...)
+}
+
+// UpdateConfig changes the race configuration for the server. The changes are made to a copy of the
+// configuration, which is only used if it is valid. The configuration before the change is returned.
+func UpdateConfig(guildID string, update func(cfg *Config)) (*Config, error) {
+	log.Trace("--> UpdateConfig")
+	defer log.Trace("<-- UpdateConfig")
+
+	server := GetServer(guildID)
+	server.mutex.Lock()
+	oldConfig := *server.Config
+	newConfig := *server.Config
+	update(&newConfig)
+	err := newConfig.Validate()
+	if err != nil {
+		server.mutex.Unlock()
+		return nil, err
+	}
+	server.Config = &newConfig
+	server.mutex.Unlock()
+
+	SaveServer(server)
+	return &oldConfig, nil
+}
+
+// GetPlayers returns a copy of all players that have taken part in a race on the server.
+func GetPlayers(guildID string) []*Player {
+	server, ok := Servers[guildID]
+	if !ok {
+		return nil
+	}
+	server.mutex.Lock()
+	defer server.mutex.Unlock()
+
+	players := make([]*Player, 0, len(server.Players))
+	for _, player := range server.Players {
+		p := *player
+		players = append(players, &p)
+	}
+	return players
+}
+
+// ResetRace resets a race on the server that is hung. The names of the racers in the race are returned.
+func ResetRace(guildID string) []string {
+	log.Trace("--> ResetRace")
+	defer log.Trace("<-- ResetRace")
+
+	// The server isn't locked, as a hung race may be holding the lock
+	server := GetServer(guildID)
+	var racerNames []string
+	if server.Race != nil {
+		racerNames = make([]string, 0, len(server.Race.Racers))
+		for _, racer := range server.Race.Racers {
+			racerNames = append(racerNames, racer.Player.Name)
+		}
+	}
+	server.Race = nil
+	return racerNames
+}
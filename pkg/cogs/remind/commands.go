@@ -3,11 +3,8 @@ package remind
 import (
 	"github.com/bwmarrin/discordgo"
 	"github.com/rbrabson/heist/pkg/msg"
-	log "github.com/sirupsen/logrus"
-)
-
-var (
-	session *discordgo.Session
+	"github.com/rbrabson/heist/pkg/scheduler"
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -28,7 +25,7 @@ var (
 						{
 							Type:        discordgo.ApplicationCommandOptionString,
 							Name:        "when",
-							Description: "Time to wait before sending the reminder",
+							Description: "Time to wait before sending the reminder, such as `1h30m`. A number alone is in hours.",
 							Required:    true,
 						},
 						{
@@ -89,14 +86,14 @@ func addReminder(s *discordgo.Session, i *discordgo.InteractionCreate) {
 
 	var response string
 	if message == "" {
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"GuildID":  i.GuildID,
 			"MemberID": i.Member.User.ID,
 			"When":     when,
 		}).Debug("Creating a reminder")
 		response, _ = server.createReminder(i.ChannelID, i.Member.User.ID, when)
 	} else {
-		log.WithFields(log.Fields{
+		log.WithFields(logrus.Fields{
 			"GuildID":  i.GuildID,
 			"MemberID": i.Member.User.ID,
 			"When":     when,
@@ -105,7 +102,6 @@ func addReminder(s *discordgo.Session, i *discordgo.InteractionCreate) {
 		response, _ = server.createReminder(i.ChannelID, i.Member.User.ID, when, message)
 	}
 
-	saveReminders(server)
 	msg.SendEphemeralResponse(s, i, response)
 }
 
@@ -124,10 +120,7 @@ func removeReminders(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	defer log.Trace("<-- removeReminders")
 
 	response, _ := deleteReminders(i.GuildID, i.Member.User.ID)
-	server := getServer(i.GuildID)
-	saveReminders(server)
 	msg.SendEphemeralResponse(s, i, response)
-
 }
 
 // GetCommands returns the component handlers, command handlers, and commands for the remind bot.
@@ -137,7 +130,7 @@ func GetCommands() (map[string]func(s *discordgo.Session, i *discordgo.Interacti
 
 // Start starts up the bot
 func Start(s *discordgo.Session) {
-	session = s
+	scheduler.Handle(reminderJob, sendReminders)
 	loadReminders()
-	go sendReminders()
+	scheduleSavedReminders()
 }
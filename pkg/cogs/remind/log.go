@@ -0,0 +1,6 @@
+package remind
+
+import "github.com/rbrabson/heist/pkg/logging"
+
+// log is the logger used by the remind cog.
+var log = logging.Logger("remind")
@@ -1,31 +1,41 @@
 package remind
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/rbrabson/heist/pkg/format"
+	"github.com/rbrabson/heist/pkg/help"
+	"github.com/rbrabson/heist/pkg/scheduler"
+	"github.com/rbrabson/heist/pkg/shard"
 	"github.com/rbrabson/heist/pkg/store"
-	log "github.com/sirupsen/logrus"
+	"github.com/sirupsen/logrus"
 )
 
 const (
 	REMINDER = "reminder"
+
+	reminderJob = "reminder" // Kind of the scheduled job that sends a reminder
 )
 
 var (
-	servers map[string]*server
+	servers      map[string]*server
+	serversMutex sync.Mutex
 )
 
 // Server represents the server on which members may create reminders
 type server struct {
 	ID      string                   `json:"_id" bson:"_id"`
 	Members map[string]*reminderList `json:"members" bson:"members"`
+	mutex   sync.Mutex               `json:"-" bson:"-"` // Lock for reading or updating the reminders
 }
 
 // reminderList is a set of reminders for a given member
@@ -40,6 +50,12 @@ type reminder struct {
 	When     time.Time     `json:"when" bson:"when"`
 	Message  *string       `json:"message,omitempty" bson:"message,omitempty"`
 	Channel  string        `json:"channel" bson:"channel"`
+	JobID    string        `json:"job_id,omitempty" bson:"job_id,omitempty"`
+}
+
+// reminderPayload identifies the member whose reminders are sent by a scheduled job.
+type reminderPayload struct {
+	MemberID string `json:"member_id"`
 }
 
 // init initializes the set of reminders
@@ -49,6 +65,9 @@ func init() {
 
 // getServer returns the given server. If necessary, a new one is created.
 func getServer(serverID string) *server {
+	serversMutex.Lock()
+	defer serversMutex.Unlock()
+
 	s, ok := servers[serverID]
 	if !ok {
 		memberList := make(map[string]*reminderList)
@@ -62,8 +81,17 @@ func getServer(serverID string) *server {
 	return s
 }
 
-// newReminder creates a new reminder and adds it to the set of reminders for a given member.
-func (s *server) newReminder(channelID string, memberID string, wait time.Duration, message ...string) {
+// GetServerCount returns the number of servers cached by the reminder bot.
+func GetServerCount() int {
+	serversMutex.Lock()
+	defer serversMutex.Unlock()
+
+	return len(servers)
+}
+
+// newReminder creates a new reminder and adds it to the set of reminders for a given member. The server's
+// lock must be held.
+func (s *server) newReminder(channelID string, memberID string, wait time.Duration, message ...string) *reminder {
 	rl, ok := s.Members[memberID]
 	if !ok {
 		reminders := make([]*reminder, 0, 1)
@@ -91,6 +119,19 @@ func (s *server) newReminder(channelID string, memberID string, wait time.Durati
 	sort.Slice(rl.Reminders, func(i, j int) bool {
 		return rl.Reminders[i].When.Before(rl.Reminders[j].When)
 	})
+
+	return r
+}
+
+// scheduleReminder schedules the job that sends the reminder to the member once it is due. The server's lock
+// must be held.
+func (s *server) scheduleReminder(memberID string, r *reminder) error {
+	jobID, err := scheduler.Once(s.ID, reminderJob, r.When, &reminderPayload{MemberID: memberID})
+	if err != nil {
+		return err
+	}
+	r.JobID = jobID
+	return nil
 }
 
 // createReminder sets a reminder for a person that will be sent via a Direct Message once the
@@ -111,7 +152,15 @@ func (s *server) createReminder(channelID string, memberID string, when string,
 		return msg, ErrInvalidDuration
 	}
 
-	s.newReminder(channelID, memberID, wait, message...)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r := s.newReminder(channelID, memberID, wait, message...)
+	if err := s.scheduleReminder(memberID, r); err != nil {
+		log.WithFields(logrus.Fields{"Guild": s.ID, "Member": memberID}).Error("Unable to schedule the reminder, error:", err)
+		s.removeReminder(memberID, r)
+		return "Unable to set the reminder. Try again later.", err
+	}
 	saveReminders(s)
 
 	msg := fmt.Sprintf("I will remind you of that in %s", format.Duration(wait))
@@ -124,6 +173,9 @@ func getReminders(serverID string, memberID string) (string, error) {
 	defer log.Trace("<-- getReminders")
 
 	s := getServer(serverID)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
 	reminders, ok := s.Members[memberID]
 	if !ok {
 		msg := "You don't have any upcoming notifications"
@@ -150,76 +202,141 @@ func deleteReminders(serverID string, memberID string) (string, error) {
 	defer log.Trace("<-- deleteReminders")
 
 	s := getServer(serverID)
-	if _, ok := s.Members[memberID]; !ok {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	rl, ok := s.Members[memberID]
+	if !ok {
 		return "You don't have any upcoming notifications.", ErrNoReminders
 	}
+	for _, r := range rl.Reminders {
+		if r.JobID == "" {
+			continue
+		}
+		if err := scheduler.Cancel(s.ID, r.JobID); err != nil {
+			log.WithFields(logrus.Fields{"Guild": s.ID, "Member": memberID, "Job": r.JobID}).Warning("Unable to cancel the reminder, error:", err)
+		}
+	}
 	delete(s.Members, memberID)
 	saveReminders(s)
 	return "All your notifications have been removed.", nil
+}
 
+// removeReminder removes the reminder from the member's reminders. The server's lock must be held.
+func (s *server) removeReminder(memberID string, r *reminder) {
+	rl, ok := s.Members[memberID]
+	if !ok {
+		return
+	}
+	rl.Reminders = slices.DeleteFunc(rl.Reminders, func(other *reminder) bool {
+		return other == r
+	})
+	if len(rl.Reminders) == 0 {
+		delete(s.Members, memberID)
+	}
 }
 
-// sendReminders sends a reminder to players who have set a reminder whose wait duration has expired.
-func sendReminders() {
-	for {
-		time.Sleep(15 * time.Second)
-		now := time.Now()
-		for _, s := range servers {
-			saveServer := false
-			delIDs := make([]string, 0, 1)
-			for _, member := range s.Members {
-				for len(member.Reminders) > 0 {
-					reminder := member.Reminders[0]
-					if reminder.When.After(now) {
-						break
-					}
-
-					c, err := session.UserChannelCreate(member.MemberID)
-					if err != nil {
-						log.Errorf("Error creating private channel to %s, error=%s", member.MemberID, err.Error())
-						break
-					}
-					var embed *discordgo.MessageEmbed
-					desc := fmt.Sprintf("From %s ago:\n\n%s", format.Duration(reminder.Duration), reminder.Channel)
-					if reminder.Message == nil {
-						embed = &discordgo.MessageEmbed{
-							Type:        discordgo.EmbedTypeRich,
-							Title:       ":bell: Reminder! :bell:",
-							Description: desc,
-						}
-					} else {
-						embed = &discordgo.MessageEmbed{
-							Type:        discordgo.EmbedTypeRich,
-							Title:       ":bell: Reminder! :bell:",
-							Description: desc,
-							Fields: []*discordgo.MessageEmbedField{
-								{
-									Name:   "Message",
-									Value:  *reminder.Message,
-									Inline: true,
-								},
-							},
-						}
-					}
-					_, err = session.ChannelMessageSendEmbed(c.ID, embed)
-					if err != nil {
-						log.WithFields(log.Fields{"Channel": c.ID, "Error": err.Error()}).Error("Failed to send reminder")
-					}
-
-					member.Reminders = member.Reminders[1:]
-					saveServer = true
+// sendReminders is run by the scheduler when a reminder is due, and sends the member's reminders whose wait
+// duration has expired. A reminder that can't be sent is dropped, as the member can't be reached.
+func sendReminders(_ context.Context, guildID string, payload []byte) error {
+	log.Trace("--> sendReminders")
+	defer log.Trace("<-- sendReminders")
+
+	var p reminderPayload
+	if err := json.Unmarshal(payload, &p); err != nil {
+		return err
+	}
+	serversMutex.Lock()
+	s, ok := servers[guildID]
+	serversMutex.Unlock()
+	if !ok {
+		return nil
+	}
+
+	// Remove the due reminders while holding the lock, and send them after it is released
+	s.mutex.Lock()
+	rl, ok := s.Members[p.MemberID]
+	if !ok {
+		s.mutex.Unlock()
+		return nil
+	}
+	now := time.Now()
+	var due []*reminder
+	for len(rl.Reminders) > 0 && !rl.Reminders[0].When.After(now) {
+		due = append(due, rl.Reminders[0])
+		rl.Reminders = rl.Reminders[1:]
+	}
+	if len(rl.Reminders) == 0 {
+		delete(s.Members, p.MemberID)
+	}
+	saveReminders(s)
+	s.mutex.Unlock()
+
+	session := shard.Session(guildID)
+	var sendErr error
+	for _, reminder := range due {
+		if err := sendReminder(session, p.MemberID, reminder); err != nil {
+			log.WithFields(logrus.Fields{"Guild": guildID, "Member": p.MemberID}).Error("Failed to send reminder, error:", err)
+			sendErr = err
+		}
+	}
+
+	return sendErr
+}
+
+// sendReminder sends the reminder to the member in a direct message.
+func sendReminder(session *discordgo.Session, memberID string, reminder *reminder) error {
+	c, err := session.UserChannelCreate(memberID)
+	if err != nil {
+		return err
+	}
+	desc := fmt.Sprintf("From %s ago:\n\n%s", format.Duration(reminder.Duration), reminder.Channel)
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       ":bell: Reminder! :bell:",
+		Description: desc,
+	}
+	if reminder.Message != nil {
+		embed.Fields = []*discordgo.MessageEmbedField{
+			{
+				Name:   "Message",
+				Value:  *reminder.Message,
+				Inline: true,
+			},
+		}
+	}
+	_, err = session.ChannelMessageSendEmbed(c.ID, embed)
+	return err
+}
+
+// scheduleSavedReminders schedules the jobs for reminders saved before reminders were sent by one-time
+// jobs. Only the reminders for servers handled by the shards run by this process are scheduled.
+func scheduleSavedReminders() {
+	serversMutex.Lock()
+	defer serversMutex.Unlock()
+
+	for _, s := range servers {
+		if !shard.Owns(s.ID) {
+			continue
+		}
+		s.mutex.Lock()
+		scheduled := false
+		for memberID, rl := range s.Members {
+			for _, r := range rl.Reminders {
+				if r.JobID != "" {
+					continue
 				}
-				if len(member.Reminders) == 0 {
-					delIDs = append(delIDs, member.MemberID)
+				if err := s.scheduleReminder(memberID, r); err != nil {
+					log.WithFields(logrus.Fields{"Guild": s.ID, "Member": memberID}).Error("Unable to schedule a saved reminder, error:", err)
+					continue
 				}
-			}
-			for _, delID := range delIDs {
-				delete(s.Members, delID)
-			}
-			if saveServer {
-				saveReminders(s)
+				scheduled = true
 			}
 		}
+		if scheduled {
+			saveReminders(s)
+		}
+		s.mutex.Unlock()
 	}
 }
 
@@ -228,17 +345,21 @@ func loadReminders() {
 	log.Trace("--> LoadReminders")
 	defer log.Trace("<-- LoadReminders")
 
-	servers = make(map[string]*server)
+	loaded := make(map[string]*server)
 	serverIDs := store.Store.ListDocuments(REMINDER)
 	for _, serverID := range serverIDs {
-		var server server
-		store.Store.Load(REMINDER, serverID, &server)
+		server := &server{}
+		store.Store.Load(REMINDER, serverID, server)
 		log.Debug("Server:", server)
-		servers[server.ID] = &server
+		loaded[server.ID] = server
 	}
+
+	serversMutex.Lock()
+	servers = loaded
+	serversMutex.Unlock()
 }
 
-// saveReminders saves the reminders for a member.
+// saveReminders saves the reminders for a member. The server's lock must be held.
 func saveReminders(server *server) {
 	log.Trace("--> SaveReminder")
 	defer log.Trace("<-- SaveReminder")
@@ -246,25 +367,25 @@ func saveReminders(server *server) {
 	store.Store.Save(REMINDER, server.ID, server)
 }
 
-// GetMemberHelp returns help information about the heist bot commands
-func GetMemberHelp() []string {
-	help := make([]string, 0, 1)
-
-	for _, command := range commands[0].Options {
-		commandDescription := fmt.Sprintf("- **/reminder %s**:  %s\n", command.Name, command.Description)
-		help = append(help, commandDescription)
+// GetHelp returns help information about the reminder commands.
+func GetHelp() *help.Topic {
+	return &help.Topic{
+		Name:        "Reminder",
+		Description: "Have the bot send you a direct message after a period of time.",
+		Commands:    commands,
+		Details: map[string]*help.Detail{
+			"reminder add": {
+				Defaults: map[string]string{"message": "a generic reminder"},
+				Examples: []string{"/reminder add when:2", "/reminder add when:1h30m message:Start a heist"},
+			},
+			"reminder del": {
+				Examples: []string{"/reminder del"},
+			},
+			"reminder list": {
+				Examples: []string{"/reminder list"},
+			},
+		},
 	}
-	sort.Slice(help, func(i, j int) bool {
-		return help[i] < help[j]
-	})
-	help = append([]string{"**Reminder**\n"}, help...)
-
-	return help
-}
-
-// GetAdminHelp returns help information about the heist bot commands
-func GetAdminHelp() []string {
-	return nil
 }
 
 // String returns a string repesentation for all reminders on the given server.
@@ -0,0 +1,446 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/rbrabson/heist/pkg/logging"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// DefaultFile is the configuration file used if `HEIST_CONFIG` isn't set.
+	DefaultFile = "./configs/heist.yaml"
+
+	FILE    = "file"
+	MONGODB = "mongodb"
+
+	// MaxRacers is the most racers for which the race game can show betting buttons.
+	MaxRacers = 11
+
+	// minSecretLength is the shortest key that may be used to sign dashboard logins.
+	minSecretLength = 32
+)
+
+var (
+	current atomic.Pointer[Config]
+)
+
+// Config is the configuration for the bot. It is read from a YAML file, and any value may be
+// overridden using environment variables.
+type Config struct {
+	Bot       Bot            `yaml:"bot"`
+	Store     Store          `yaml:"store"`
+	API       API            `yaml:"api"`
+	Dashboard Dashboard      `yaml:"dashboard"`
+	Logging   logging.Config `yaml:"logging"`
+	Economy   Economy        `yaml:"economy"`
+	Heist     Heist          `yaml:"heist"`
+	Payday    Payday         `yaml:"payday"`
+	Race      Race           `yaml:"race"`
+}
+
+// Bot is the configuration used to connect to Discord.
+type Bot struct {
+	Token      string   `yaml:"token"`       // Token used to log into Discord
+	AppID      string   `yaml:"app_id"`      // ID of the Discord application
+	GuildID    string   `yaml:"guild_id"`    // Guild the commands are registered for; all guilds if empty
+	OwnerIDs   []string `yaml:"owner_ids"`   // Users who may use the owner commands; the application owner if empty
+	ShardCount string   `yaml:"shard_count"` // Total number of shards, or `auto` to use the number recommended by Discord
+	ShardIDs   []int    `yaml:"shard_ids"`   // Shards run by this process; all shards if empty
+}
+
+// Store is the configuration for where the bot saves its data.
+type Store struct {
+	Type     string `yaml:"type"`        // Either `mongodb` or `file`
+	Dir      string `yaml:"dir"`         // Directory used by the file store
+	MongoURI string `yaml:"mongodb_uri"` // URI used to connect to MongoDB
+	Database string `yaml:"database"`    // Name of the MongoDB database
+	Watch    bool   `yaml:"watch"`       // Reload themes, targets and race modes when their files change
+}
+
+// API is the configuration for the read-only HTTP API used to publish game data to other sites.
+type API struct {
+	Enabled bool   `yaml:"enabled"` // Serve the API
+	Address string `yaml:"address"` // Address the API listens on, such as `:8080`
+}
+
+// Dashboard is the configuration for the web dashboard used by server admins to manage the games. The
+// dashboard is served by the same web server as the API.
+type Dashboard struct {
+	Enabled bool   `yaml:"enabled"` // Serve the dashboard
+	URL     string `yaml:"url"`     // Public URL of the web server, used in the login links sent to admins
+	Secret  string `yaml:"secret"`  // Key used to sign login links and sessions
+}
+
+// Economy is the default configuration for a new bank.
+type Economy struct {
+	DefaultBalance int    `yaml:"default_balance"`
+	BankName       string `yaml:"bank_name"`
+	Currency       string `yaml:"currency"`
+}
+
+// Heist is the default configuration for a new heist server.
+type Heist struct {
+	DefaultTheme string        `yaml:"default_theme"`
+	BailBase     int64         `yaml:"bail_base"`
+	HeistCost    int64         `yaml:"heist_cost"`
+	DeathTimer   time.Duration `yaml:"death_timer"`
+	PoliceAlert  time.Duration `yaml:"police_alert"`
+	SentenceBase time.Duration `yaml:"sentence_base"`
+	WaitTime     time.Duration `yaml:"wait_time"`
+}
+
+// Payday is the default configuration for paydays on a new server.
+type Payday struct {
+	Amount    int64         `yaml:"amount"`
+	Frequency time.Duration `yaml:"frequency"`
+}
+
+// Race is the default configuration for races on a new server.
+type Race struct {
+	Mode             string        `yaml:"mode"`
+	BetAmount        int           `yaml:"bet_amount"`
+	Currency         string        `yaml:"currency"`
+	PrizeMin         int           `yaml:"prize_min"`
+	PrizeMax         int           `yaml:"prize_max"`
+	MinRacers        int           `yaml:"min_racers"`
+	MaxRacers        int           `yaml:"max_racers"`
+	WaitForJoin      time.Duration `yaml:"wait_for_join"`
+	WaitForBetting   time.Duration `yaml:"wait_for_betting"`
+	WaitBetweenRaces time.Duration `yaml:"wait_between_races"`
+}
+
+// init sets the configuration to the default values, so the configuration may be used by
+// tools and tests that don't load a configuration file.
+func init() {
+	current.Store(Default())
+}
+
+// Default returns the default configuration.
+func Default() *Config {
+	return &Config{
+		Bot: Bot{
+			ShardCount: "1",
+		},
+		Store: Store{
+			Type:     MONGODB,
+			Dir:      "./store/",
+			Database: "Heist",
+		},
+		API: API{
+			Address: ":8080",
+		},
+		Logging: logging.Config{
+			Level:  logrus.InfoLevel.String(),
+			Format: logging.TEXT,
+			Cogs:   make(map[string]string),
+		},
+		Economy: Economy{
+			DefaultBalance: 20000,
+			BankName:       "Treasury",
+			Currency:       "Coins",
+		},
+		Heist: Heist{
+			DefaultTheme: "clash",
+			BailBase:     250,
+			HeistCost:    1500,
+			DeathTimer:   45 * time.Second,
+			PoliceAlert:  60 * time.Second,
+			SentenceBase: 5 * time.Second,
+			WaitTime:     60 * time.Second,
+		},
+		Payday: Payday{
+			Amount:    5000,
+			Frequency: 23 * time.Hour,
+		},
+		Race: Race{
+			Mode:             "clash",
+			BetAmount:        100,
+			Currency:         "credit",
+			PrizeMin:         750,
+			PrizeMax:         1250,
+			MinRacers:        2,
+			MaxRacers:        10,
+			WaitForJoin:      30 * time.Second,
+			WaitForBetting:   30 * time.Second,
+			WaitBetweenRaces: 1 * time.Minute,
+		},
+	}
+}
+
+// Get returns the configuration for the bot.
+func Get() *Config {
+	return current.Load()
+}
+
+// Load reads the configuration from the YAML file named by `HEIST_CONFIG`, or from `./configs/heist.yaml`
+// if that isn't set and the file exists. Values not in the file use the defaults, and any value may be
+// overridden using environment variables. The configuration is validated before being used by the bot.
+func Load() (*Config, error) {
+	config := Default()
+
+	filename := os.Getenv("HEIST_CONFIG")
+	if filename == "" {
+		if _, err := os.Stat(DefaultFile); err == nil {
+			filename = DefaultFile
+		}
+	}
+	if filename != "" {
+		if err := config.read(filename); err != nil {
+			return nil, err
+		}
+	}
+	if err := config.applyEnv(); err != nil {
+		return nil, err
+	}
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+
+	current.Store(config)
+	return config, nil
+}
+
+// read reads the configuration from the YAML file. Unknown keys are reported as errors so
+// typos in the file aren't silently ignored.
+func (c *Config) read(filename string) error {
+	f, err := os.Open(filename)
+	if err != nil {
+		return &FileError{Filename: filename, Err: err}
+	}
+	defer f.Close()
+
+	decoder := yaml.NewDecoder(f)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(c); err != nil {
+		return &FileError{Filename: filename, Err: err}
+	}
+	if c.Logging.Cogs == nil {
+		c.Logging.Cogs = make(map[string]string)
+	}
+	return nil
+}
+
+// applyEnv overrides the configuration using the values set in environment variables.
+func (c *Config) applyEnv() error {
+	setString(&c.Bot.Token, "BOT_TOKEN")
+	setString(&c.Bot.AppID, "APP_ID")
+	setString(&c.Bot.GuildID, "HEIST_GUILD_ID")
+	setString(&c.Bot.ShardCount, "HEIST_SHARD_COUNT")
+	if ids := os.Getenv("HEIST_OWNER_IDS"); ids != "" {
+		c.Bot.OwnerIDs = c.Bot.OwnerIDs[:0]
+		for _, id := range strings.Split(ids, ",") {
+			c.Bot.OwnerIDs = append(c.Bot.OwnerIDs, strings.TrimSpace(id))
+		}
+	}
+	if ids := os.Getenv("HEIST_SHARD_IDS"); ids != "" {
+		c.Bot.ShardIDs = c.Bot.ShardIDs[:0]
+		for _, id := range strings.Split(ids, ",") {
+			shardID, err := strconv.Atoi(strings.TrimSpace(id))
+			if err != nil {
+				return &EnvError{Name: "HEIST_SHARD_IDS", Value: ids, Err: ErrInvalidShardIDs}
+			}
+			c.Bot.ShardIDs = append(c.Bot.ShardIDs, shardID)
+		}
+	}
+
+	setString(&c.Store.Type, "HEIST_STORE")
+	setString(&c.Store.Dir, "HEIST_FILE_STORE_DIR")
+	setString(&c.Store.MongoURI, "MONGODB_URI")
+	setString(&c.Store.Database, "MONGODB_DATABASE")
+	if watch := os.Getenv("HEIST_STORE_WATCH"); watch != "" {
+		var err error
+		c.Store.Watch, err = strconv.ParseBool(watch)
+		if err != nil {
+			return &EnvError{Name: "HEIST_STORE_WATCH", Value: watch, Err: err}
+		}
+	}
+
+	if enabled := os.Getenv("HEIST_API_ENABLED"); enabled != "" {
+		var err error
+		c.API.Enabled, err = strconv.ParseBool(enabled)
+		if err != nil {
+			return &EnvError{Name: "HEIST_API_ENABLED", Value: enabled, Err: err}
+		}
+	}
+	setString(&c.API.Address, "HEIST_API_ADDRESS")
+	if enabled := os.Getenv("HEIST_DASHBOARD_ENABLED"); enabled != "" {
+		var err error
+		c.Dashboard.Enabled, err = strconv.ParseBool(enabled)
+		if err != nil {
+			return &EnvError{Name: "HEIST_DASHBOARD_ENABLED", Value: enabled, Err: err}
+		}
+	}
+	setString(&c.Dashboard.URL, "HEIST_DASHBOARD_URL")
+	setString(&c.Dashboard.Secret, "HEIST_DASHBOARD_SECRET")
+
+	if filename := os.Getenv("HEIST_LOG_CONFIG"); filename != "" {
+		b, err := os.ReadFile(filename)
+		if err != nil {
+			return &EnvError{Name: "HEIST_LOG_CONFIG", Value: filename, Err: err}
+		}
+		if err := json.Unmarshal(b, &c.Logging); err != nil {
+			return &EnvError{Name: "HEIST_LOG_CONFIG", Value: filename, Err: err}
+		}
+		if c.Logging.Cogs == nil {
+			c.Logging.Cogs = make(map[string]string)
+		}
+	}
+	setString(&c.Logging.Level, "HEIST_LOG_LEVEL")
+	setString(&c.Logging.Format, "HEIST_LOG_FORMAT")
+	if levels := os.Getenv("HEIST_LOG_LEVELS"); levels != "" {
+		for _, pair := range strings.Split(levels, ",") {
+			cog, level, ok := strings.Cut(pair, "=")
+			if !ok {
+				return &EnvError{Name: "HEIST_LOG_LEVELS", Value: levels, Err: ErrInvalidCogLevels}
+			}
+			c.Logging.Cogs[strings.TrimSpace(cog)] = strings.TrimSpace(level)
+		}
+	}
+
+	setString(&c.Heist.DefaultTheme, "HEIST_DEFAULT_THEME")
+
+	return nil
+}
+
+// setString sets the value to that of the environment variable, if the environment variable is set.
+func setString(value *string, name string) {
+	if env, ok := os.LookupEnv(name); ok && env != "" {
+		*value = env
+	}
+}
+
+// Validate checks that the configuration is usable by the bot. All problems that are found are
+// returned in a single error.
+func (c *Config) Validate() error {
+	var problems []string
+	problem := func(msg string) {
+		problems = append(problems, msg)
+	}
+
+	if c.Bot.Token == "" {
+		problem("bot.token is required; set it in the configuration file or using BOT_TOKEN")
+	}
+	if c.Bot.AppID == "" {
+		problem("bot.app_id is required; set it in the configuration file or using APP_ID")
+	}
+	shardCount := 0
+	if !strings.EqualFold(c.Bot.ShardCount, "auto") {
+		count, err := strconv.Atoi(c.Bot.ShardCount)
+		if err != nil || count < 1 {
+			problem("bot.shard_count must be a positive number or `auto`, not `" + c.Bot.ShardCount + "`")
+		}
+		shardCount = count
+	}
+	seen := make(map[int]bool, len(c.Bot.ShardIDs))
+	for _, id := range c.Bot.ShardIDs {
+		if id < 0 || (shardCount > 0 && id >= shardCount) {
+			problem("bot.shard_ids must be between 0 and the shard count, but contains " + strconv.Itoa(id))
+		}
+		if seen[id] {
+			problem("bot.shard_ids must not repeat a shard, but contains " + strconv.Itoa(id) + " more than once")
+		}
+		seen[id] = true
+	}
+
+	switch c.Store.Type {
+	case MONGODB:
+		if c.Store.MongoURI == "" {
+			problem("store.mongodb_uri is required when using MongoDB; set it in the configuration file or using MONGODB_URI")
+		}
+		if c.Store.Database == "" {
+			problem("store.database is required when using MongoDB; set it in the configuration file or using MONGODB_DATABASE")
+		}
+		if c.Store.Watch {
+			problem("store.watch is only supported by the file store")
+		}
+	case FILE:
+		if c.Store.Dir == "" {
+			problem("store.dir is required when using the file store; set it in the configuration file or using HEIST_FILE_STORE_DIR")
+		}
+	default:
+		problem("store.type must be either `mongodb` or `file`, not `" + c.Store.Type + "`")
+	}
+
+	if c.API.Enabled && c.API.Address == "" {
+		problem("api.address is required when the API is enabled; set it in the configuration file or using HEIST_API_ADDRESS")
+	}
+
+	if c.Dashboard.Enabled {
+		if !c.API.Enabled {
+			problem("api.enabled must be set to use the dashboard, as the dashboard is served by the API's web server")
+		}
+		if c.Dashboard.URL == "" {
+			problem("dashboard.url is required when the dashboard is enabled; set it in the configuration file or using HEIST_DASHBOARD_URL")
+		}
+		if len(c.Dashboard.Secret) < minSecretLength {
+			problem("dashboard.secret must be at least " + strconv.Itoa(minSecretLength) + " characters; set it in the configuration file or using HEIST_DASHBOARD_SECRET")
+		}
+	}
+
+	if _, err := logrus.ParseLevel(c.Logging.Level); err != nil {
+		problem("logging.level `" + c.Logging.Level + "` is not a valid log level")
+	}
+	if c.Logging.Format != logging.TEXT && c.Logging.Format != logging.JSON {
+		problem("logging.format must be either `text` or `json`, not `" + c.Logging.Format + "`")
+	}
+	for cog, level := range c.Logging.Cogs {
+		if _, err := logrus.ParseLevel(level); err != nil {
+			problem("logging.cogs." + cog + " `" + level + "` is not a valid log level")
+		}
+	}
+
+	if c.Economy.DefaultBalance < 0 {
+		problem("economy.default_balance must not be negative")
+	}
+
+	if c.Heist.DefaultTheme == "" {
+		problem("heist.default_theme is required; set it in the configuration file or using HEIST_DEFAULT_THEME")
+	}
+	if c.Heist.BailBase < 0 {
+		problem("heist.bail_base must not be negative")
+	}
+	if c.Heist.HeistCost < 0 {
+		problem("heist.heist_cost must not be negative")
+	}
+	if c.Heist.DeathTimer <= 0 || c.Heist.PoliceAlert <= 0 || c.Heist.SentenceBase <= 0 || c.Heist.WaitTime <= 0 {
+		problem("heist.death_timer, heist.police_alert, heist.sentence_base and heist.wait_time must be positive durations, such as `60s`")
+	}
+
+	if c.Payday.Amount < 0 {
+		problem("payday.amount must not be negative")
+	}
+	if c.Payday.Frequency <= 0 {
+		problem("payday.frequency must be a positive duration, such as `23h`")
+	}
+
+	if c.Race.Mode == "" {
+		problem("race.mode is required")
+	}
+	if c.Race.BetAmount < 0 {
+		problem("race.bet_amount must not be negative")
+	}
+	if c.Race.PrizeMin < 0 || c.Race.PrizeMin > c.Race.PrizeMax {
+		problem("race.prize_min must not be negative, and must not be more than race.prize_max")
+	}
+	if c.Race.MinRacers < 1 || c.Race.MinRacers > c.Race.MaxRacers {
+		problem("race.min_racers must be at least 1, and must not be more than race.max_racers")
+	}
+	if c.Race.MaxRacers > MaxRacers {
+		problem("race.max_racers must not be more than " + strconv.Itoa(MaxRacers))
+	}
+	if c.Race.WaitForJoin <= 0 || c.Race.WaitForBetting <= 0 || c.Race.WaitBetweenRaces <= 0 {
+		problem("race.wait_for_join, race.wait_for_betting and race.wait_between_races must be positive durations, such as `30s`")
+	}
+
+	if len(problems) != 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
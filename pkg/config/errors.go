@@ -0,0 +1,54 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrInvalidCogLevels = errors.New("must be a comma separated list of cog=level pairs")
+	ErrInvalidShardIDs  = errors.New("must be a comma separated list of shard IDs")
+)
+
+// ValidationError is returned when the configuration isn't usable by the bot. It lists every problem found.
+type ValidationError struct {
+	Problems []string
+}
+
+// Error returns the problems with the configuration, one per line.
+func (e *ValidationError) Error() string {
+	return "invalid configuration:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// FileError is returned when the configuration file can't be read or parsed.
+type FileError struct {
+	Filename string
+	Err      error
+}
+
+// Error returns the name of the configuration file and the reason it couldn't be used.
+func (e *FileError) Error() string {
+	return "unable to read configuration file " + e.Filename + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *FileError) Unwrap() error {
+	return e.Err
+}
+
+// EnvError is returned when an environment variable has an invalid value.
+type EnvError struct {
+	Name  string
+	Value string
+	Err   error
+}
+
+// Error returns the name of the environment variable and the reason its value is invalid.
+func (e *EnvError) Error() string {
+	return "invalid value `" + e.Value + "` for " + e.Name + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *EnvError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,164 @@
+package dashboard
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	loginTTL   = 15 * time.Minute
+	sessionTTL = 12 * time.Hour
+
+	loginKind   = "login"
+	sessionKind = "session"
+)
+
+var (
+	instanceID string
+	usedNonces = make(map[string]time.Time)
+	nonceMutex sync.Mutex
+)
+
+// claims identify the admin using the dashboard and the server they are managing. They are signed so
+// they can't be changed by the holder.
+type claims struct {
+	Kind     string `json:"k"`           // Either a login link or a session
+	GuildID  string `json:"g"`           // Server being managed
+	MemberID string `json:"m"`           // Admin using the dashboard
+	Name     string `json:"n"`           // Name of the admin
+	Expires  int64  `json:"e"`           // Unix time when the claims expire
+	Nonce    string `json:"x,omitempty"` // Random value that allows a login link to be used only once
+	Instance string `json:"i,omitempty"` // Process that created the login link
+}
+
+// newInstanceID picks a random ID for this process. The used nonces are only kept in memory, so login links
+// are tied to the process that created them; otherwise a link could be used again after a restart.
+func newInstanceID() error {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	instanceID = base64.RawURLEncoding.EncodeToString(id)
+	return nil
+}
+
+// NewLoginLink returns a link that logs the member into the dashboard for the server. The link expires
+// after a short time and may only be used once.
+func NewLoginLink(guildID string, memberID string, name string) (string, error) {
+	log.Trace("--> dashboard.NewLoginLink")
+	defer log.Trace("<-- dashboard.NewLoginLink")
+
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	token, err := sign(&claims{
+		Kind:     loginKind,
+		GuildID:  guildID,
+		MemberID: memberID,
+		Name:     name,
+		Expires:  time.Now().Add(loginTTL).Unix(),
+		Nonce:    base64.RawURLEncoding.EncodeToString(nonce),
+		Instance: instanceID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return baseURL + "/dashboard/login?token=" + url.QueryEscape(token), nil
+}
+
+// newSession returns the signed session for the admin who logged in using the login claims.
+func newSession(login *claims) (string, error) {
+	return sign(&claims{
+		Kind:     sessionKind,
+		GuildID:  login.GuildID,
+		MemberID: login.MemberID,
+		Name:     login.Name,
+		Expires:  time.Now().Add(sessionTTL).Unix(),
+	})
+}
+
+// sign encodes and signs the claims.
+func sign(c *claims) (string, error) {
+	payload, err := json.Marshal(c)
+	if err != nil {
+		return "", err
+	}
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + signature(encoded), nil
+}
+
+// verify checks the signature and expiry of the token, and returns its claims if it is valid.
+func verify(token string, kind string) (*claims, error) {
+	encoded, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(signature(encoded))) {
+		return nil, ErrInvalidToken
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	var c claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, ErrInvalidToken
+	}
+	if c.Kind != kind || time.Now().Unix() > c.Expires {
+		return nil, ErrInvalidToken
+	}
+	return &c, nil
+}
+
+// verifyLogin checks the token of a login link, and returns its claims if it is valid and was created by
+// this process.
+func verifyLogin(token string) (*claims, error) {
+	c, err := verify(token, loginKind)
+	if err != nil {
+		return nil, err
+	}
+	if c.Instance == "" || c.Instance != instanceID {
+		return nil, ErrInvalidToken
+	}
+	return c, nil
+}
+
+// useNonce marks the nonce of a login link as used, so the link can't be used again. Nonces are
+// forgotten once the link they belong to has expired.
+func useNonce(c *claims) error {
+	nonceMutex.Lock()
+	defer nonceMutex.Unlock()
+
+	now := time.Now()
+	for nonce, expires := range usedNonces {
+		if now.After(expires) {
+			delete(usedNonces, nonce)
+		}
+	}
+	if _, ok := usedNonces[c.Nonce]; ok {
+		return ErrTokenUsed
+	}
+	usedNonces[c.Nonce] = time.Unix(c.Expires, 0)
+	return nil
+}
+
+// signature returns the signature of the value, using the dashboard secret.
+func signature(value string) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(value))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+// csrfToken returns the token that must be included in each form posted by the session, which
+// prevents other sites from posting forms using the admin's session.
+func csrfToken(session string) string {
+	return signature("csrf:" + session)
+}
@@ -0,0 +1,231 @@
+package dashboard
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/rbrabson/heist/pkg/api"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
+	"github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/permission"
+	"github.com/rbrabson/heist/pkg/shard"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	sessionCookie = "heist_session"
+)
+
+var (
+	//go:embed templates/*.html
+	templateFS embed.FS
+
+	templates *template.Template
+	secret    []byte
+	baseURL   string
+	secure    bool
+)
+
+// session is an admin who is logged into the dashboard.
+type session struct {
+	*claims
+	csrf string
+}
+
+// page is the data used to render a dashboard page.
+type page struct {
+	Title   string
+	Message string
+	Error   string
+	Session *session
+	Data    interface{}
+}
+
+// Start registers the dashboard with the API's web server, if the dashboard is enabled in the
+// configuration. It must be called before the API is started.
+func Start(cfg config.Dashboard) {
+	log.Trace("--> dashboard.Start")
+	defer log.Trace("<-- dashboard.Start")
+
+	if !cfg.Enabled {
+		return
+	}
+	secret = []byte(cfg.Secret)
+	if err := newInstanceID(); err != nil {
+		log.Error("Unable to start the dashboard, error:", err)
+		return
+	}
+	baseURL = strings.TrimSuffix(cfg.URL, "/")
+	secure = strings.HasPrefix(baseURL, "https://")
+	templates = template.Must(template.New("").Funcs(template.FuncMap{
+		"seconds": func(d time.Duration) int64 { return int64(d / time.Second) },
+	}).ParseFS(templateFS, "templates/*.html"))
+
+	api.Handle("GET /dashboard/login", http.HandlerFunc(confirmLogin))
+	api.Handle("POST /dashboard/login", http.HandlerFunc(login))
+	api.Handle("POST /dashboard/logout", authenticated(logout))
+	api.Handle("GET /dashboard/{$}", authenticated(showSettings))
+	api.Handle("POST /dashboard/settings/heist", authenticated(updateHeistSettings))
+	api.Handle("POST /dashboard/settings/race", authenticated(updateRaceSettings))
+	api.Handle("POST /dashboard/settings/payday", authenticated(updatePaydaySettings))
+	api.Handle("POST /dashboard/settings/economy", authenticated(updateEconomySettings))
+	api.Handle("POST /dashboard/reset/heist", authenticated(resetHeist))
+	api.Handle("POST /dashboard/reset/race", authenticated(resetRace))
+	api.Handle("GET /dashboard/players", authenticated(showPlayers))
+	api.Handle("POST /dashboard/players/{memberID}/clear", authenticated(clearPlayer))
+	api.Handle("GET /dashboard/accounts", authenticated(showAccounts))
+	api.Handle("POST /dashboard/accounts/{memberID}/balance", authenticated(setBalance))
+	log.WithField("URL", baseURL+"/dashboard/").Info("Dashboard enabled")
+}
+
+// confirmLogin asks the admin to confirm they want to log in using the signed link that was sent to them.
+// The link isn't used up until the admin confirms, so fetching the link to show a preview of it doesn't
+// prevent the admin from logging in.
+func confirmLogin(w http.ResponseWriter, r *http.Request) {
+	log.Trace("--> dashboard.confirmLogin")
+	defer log.Trace("<-- dashboard.confirmLogin")
+
+	token := r.URL.Query().Get("token")
+	c, err := verifyLogin(token)
+	if err != nil {
+		render(w, http.StatusUnauthorized, "message", &page{Title: "Login Failed", Error: err.Error() + ". Use `/dashboard` in Discord to get a new link."})
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	render(w, http.StatusOK, "login", &page{Title: "Log In", Data: struct{ Name, Token string }{c.Name, token}})
+}
+
+// login logs the admin into the dashboard using the signed link that was sent to them.
+func login(w http.ResponseWriter, r *http.Request) {
+	log.Trace("--> dashboard.login")
+	defer log.Trace("<-- dashboard.login")
+
+	c, err := verifyLogin(r.PostFormValue("token"))
+	if err == nil {
+		err = useNonce(c)
+	}
+	if err != nil {
+		render(w, http.StatusUnauthorized, "message", &page{Title: "Login Failed", Error: err.Error() + ". Use `/dashboard` in Discord to get a new link."})
+		return
+	}
+	value, err := newSession(c)
+	if err != nil {
+		log.Error("Unable to create a dashboard session, error:", err)
+		render(w, http.StatusInternalServerError, "message", &page{Title: "Login Failed", Error: "Unable to log in."})
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    value,
+		Path:     "/dashboard/",
+		MaxAge:   int(sessionTTL / time.Second),
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	log.WithFields(log.Fields{"Guild": c.GuildID, "Member": c.MemberID}).Info("Logged into the dashboard")
+
+	http.Redirect(w, r, "/dashboard/", http.StatusSeeOther)
+}
+
+// logout logs the admin out of the dashboard.
+func logout(w http.ResponseWriter, r *http.Request, sess *session) {
+	http.SetCookie(w, &http.Cookie{
+		Name:     sessionCookie,
+		Value:    "",
+		Path:     "/dashboard/",
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: http.SameSiteStrictMode,
+	})
+	render(w, http.StatusOK, "message", &page{Title: "Logged Out", Message: "You have been logged out of the dashboard."})
+}
+
+// authenticated returns a handler that only calls the given handler if the request comes from an admin
+// who is logged into the dashboard and still has permission to manage the server. Forms posted to the handler must include the session's CSRF token.
+func authenticated(handler func(w http.ResponseWriter, r *http.Request, sess *session)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie(sessionCookie)
+		if err != nil {
+			render(w, http.StatusUnauthorized, "message", &page{Title: "Not Logged In", Error: "Use `/dashboard` in Discord to get a login link."})
+			return
+		}
+		c, err := verify(cookie.Value, sessionKind)
+		if err != nil {
+			render(w, http.StatusUnauthorized, "message", &page{Title: "Not Logged In", Error: "Your session has expired. Use `/dashboard` in Discord to get a new login link."})
+			return
+		}
+		if !shard.Owns(c.GuildID) {
+			render(w, http.StatusNotFound, "message", &page{Title: "Not Found", Error: "This server isn't handled by this instance of the bot."})
+			return
+		}
+		ok, err := permission.MemberHasPermissions(shard.Session(c.GuildID), c.GuildID, c.MemberID, permission.AdminPermissions)
+		if err != nil {
+			log.WithFields(log.Fields{"Guild": c.GuildID, "Member": c.MemberID}).Warning("Unable to check the permissions of the dashboard user, error:", err)
+		}
+		if !ok {
+			render(w, http.StatusForbidden, "message", &page{Title: "Not Allowed", Error: "You no longer have permission to manage this server."})
+			return
+		}
+		sess := &session{claims: c, csrf: csrfToken(cookie.Value)}
+		if r.Method == http.MethodPost && r.PostFormValue("csrf") != sess.csrf {
+			redirect(w, r, "/dashboard/", "", ErrInvalidForm)
+			return
+		}
+		handler(w, r, sess)
+	})
+}
+
+// CSRF returns the token that must be included in each form posted by the session.
+func (sess *session) CSRF() string {
+	return sess.csrf
+}
+
+// render writes the named page template as the response.
+func render(w http.ResponseWriter, status int, name string, p *page) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("X-Frame-Options", "DENY")
+	w.WriteHeader(status)
+	err := templates.ExecuteTemplate(w, name, p)
+	if err != nil {
+		log.WithField("Page", name).Error("Unable to render the dashboard page, error:", err)
+	}
+}
+
+// redirect sends the admin back to the given page, showing a message about the result of the action
+// they took.
+func redirect(w http.ResponseWriter, r *http.Request, path string, message string, err error) {
+	query := url.Values{}
+	if err != nil {
+		query.Set("error", err.Error())
+	} else if message != "" {
+		query.Set("message", message)
+	}
+	http.Redirect(w, r, path+"?"+query.Encode(), http.StatusSeeOther)
+}
+
+// newPage returns the data for a page, including any message passed from the previous action.
+func newPage(r *http.Request, sess *session, title string, data interface{}) *page {
+	return &page{
+		Title:   title,
+		Message: r.URL.Query().Get("message"),
+		Error:   r.URL.Query().Get("error"),
+		Session: sess,
+		Data:    data,
+	}
+}
+
+// record adds an entry to the server's audit log for a value changed using the dashboard. Values that
+// didn't change aren't recorded.
+func record(sess *session, action string, target string, oldValue interface{}, newValue interface{}) {
+	if newValue != nil && fmt.Sprint(oldValue) == fmt.Sprint(newValue) {
+		return
+	}
+	audit.RecordUser(shard.Session(sess.GuildID), sess.GuildID, sess.MemberID, sess.Name, "dashboard "+action, target, oldValue, newValue)
+}
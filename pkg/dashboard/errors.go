@@ -0,0 +1,10 @@
+package dashboard
+
+import "errors"
+
+var (
+	ErrInvalidToken = errors.New("the link is invalid or has expired")
+	ErrTokenUsed    = errors.New("the link has already been used")
+	ErrInvalidForm  = errors.New("the form is invalid; reload the page and try again")
+	ErrNotNumber    = errors.New("must be a whole number")
+)
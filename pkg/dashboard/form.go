@@ -0,0 +1,46 @@
+package dashboard
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// form reads the values posted from a dashboard form, collecting the problems with any value that
+// can't be read.
+type form struct {
+	r        *http.Request
+	problems []error
+}
+
+// newForm returns the form posted in the request.
+func newForm(r *http.Request) *form {
+	return &form{r: r}
+}
+
+// text returns the value of the field, with leading and trailing spaces removed.
+func (f *form) text(name string) string {
+	return strings.TrimSpace(f.r.PostFormValue(name))
+}
+
+// number returns the value of the field as a whole number.
+func (f *form) number(name string, label string) int64 {
+	value, err := strconv.ParseInt(f.text(name), 10, 64)
+	if err != nil {
+		f.problems = append(f.problems, fmt.Errorf("%s %w", label, ErrNotNumber))
+	}
+	return value
+}
+
+// seconds returns the value of the field, which is a number of seconds, as a duration.
+func (f *form) seconds(name string, label string) time.Duration {
+	return time.Duration(f.number(name, label)) * time.Second
+}
+
+// err returns the problems with the values in the form, or `nil` if all values could be read.
+func (f *form) err() error {
+	return errors.Join(f.problems...)
+}
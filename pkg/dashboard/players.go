@@ -0,0 +1,93 @@
+package dashboard
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/cogs/heist"
+	"github.com/rbrabson/heist/pkg/cogs/race"
+	log "github.com/sirupsen/logrus"
+)
+
+// players is the data shown on the players page.
+type players struct {
+	Heist []*heist.Player
+	Race  []*race.Player
+}
+
+// showPlayers shows the heist and race players on the server.
+func showPlayers(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.showPlayers")
+	defer log.Trace("<-- dashboard.showPlayers")
+
+	data := &players{
+		Heist: heist.GetPlayers(sess.GuildID),
+		Race:  race.GetPlayers(sess.GuildID),
+	}
+	sort.Slice(data.Heist, func(i, j int) bool {
+		return data.Heist[i].Name < data.Heist[j].Name
+	})
+	sort.Slice(data.Race, func(i, j int) bool {
+		return data.Race[i].Name < data.Race[j].Name
+	})
+	render(w, http.StatusOK, "players", newPage(r, sess, "Players", data))
+}
+
+// clearPlayer clears the jail and death settings for a heist player.
+func clearPlayer(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.clearPlayer")
+	defer log.Trace("<-- dashboard.clearPlayer")
+
+	oldPlayer, newPlayer, err := heist.ClearPlayer(sess.GuildID, r.PathValue("memberID"))
+	if err != nil {
+		redirect(w, r, "/dashboard/players", "", err)
+		return
+	}
+	record(sess, "heist player clear", oldPlayer.Name, oldPlayer.Status, newPlayer.Status)
+
+	redirect(w, r, "/dashboard/players", oldPlayer.Name+" has been cleared.", nil)
+}
+
+// showAccounts shows the bank accounts on the server.
+func showAccounts(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.showAccounts")
+	defer log.Trace("<-- dashboard.showAccounts")
+
+	data := economy.GetAccounts(sess.GuildID)
+	render(w, http.StatusOK, "accounts", newPage(r, sess, "Accounts", data))
+}
+
+// setBalance sets the balance of a bank account.
+func setBalance(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.setBalance")
+	defer log.Trace("<-- dashboard.setBalance")
+
+	memberID := r.PathValue("memberID")
+	var account *economy.Account
+	for _, a := range economy.GetAccounts(sess.GuildID) {
+		if a.ID == memberID {
+			account = a
+			break
+		}
+	}
+	if account == nil {
+		redirect(w, r, "/dashboard/accounts", "", economy.ErrNoAccount)
+		return
+	}
+
+	f := newForm(r)
+	amount := f.number("balance", "balance")
+	if err := f.err(); err != nil {
+		redirect(w, r, "/dashboard/accounts", "", err)
+		return
+	}
+	oldBalance, err := economy.SetBalance(sess.GuildID, account.ID, account.Name, int(amount))
+	if err != nil {
+		redirect(w, r, "/dashboard/accounts", "", err)
+		return
+	}
+	record(sess, "bank set", account.Name, oldBalance, int(amount))
+
+	redirect(w, r, "/dashboard/accounts", "The balance for "+account.Name+" has been set.", nil)
+}
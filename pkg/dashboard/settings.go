@@ -0,0 +1,280 @@
+package dashboard
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/cogs/heist"
+	"github.com/rbrabson/heist/pkg/cogs/payday"
+	"github.com/rbrabson/heist/pkg/cogs/race"
+	"github.com/rbrabson/heist/pkg/shard"
+	log "github.com/sirupsen/logrus"
+)
+
+// settings is the data shown on the settings page.
+type settings struct {
+	Heist           *heist.Config
+	Themes          []string
+	VoteModes       []string
+	OutputModes     []string
+	SpreeMilestones string
+	Race            *race.Config
+	Modes           []string
+	PaydayAmount    int64
+	PaydayFrequency time.Duration
+	Bank            *economy.BankConfig
+}
+
+// showSettings shows the settings for the server.
+func showSettings(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.showSettings")
+	defer log.Trace("<-- dashboard.showSettings")
+
+	themes, _ := heist.GetThemeNames()
+	modes, _ := race.GetModeNames()
+	heistConfig := heist.GetServerConfig(sess.GuildID)
+	data := &settings{
+		Heist:           heistConfig,
+		Themes:          themes,
+		VoteModes:       []string{heist.TargetAuto, heist.TargetPlanner, heist.TargetCrew},
+		OutputModes:     []string{heist.CrewOutputFull, heist.CrewOutputCondensed, heist.CrewOutputSummary},
+		SpreeMilestones: heist.FormatMilestones(heistConfig.SpreeMilestones),
+		Race:            race.GetServerConfig(sess.GuildID),
+		Modes:           modes,
+		PaydayAmount:    payday.GetPaydayAmount(sess.GuildID),
+		PaydayFrequency: payday.GetPaydayFrequency(sess.GuildID),
+		Bank:            economy.GetBankConfig(sess.GuildID),
+	}
+	render(w, http.StatusOK, "settings", newPage(r, sess, "Settings", data))
+}
+
+// updateHeistSettings changes the heist settings for the server.
+func updateHeistSettings(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.updateHeistSettings")
+	defer log.Trace("<-- dashboard.updateHeistSettings")
+
+	f := newForm(r)
+	cost := f.number("cost", "cost")
+	bail := f.number("bail", "bail")
+	sentence := f.seconds("sentence", "sentence")
+	patrol := f.seconds("patrol", "patrol time")
+	death := f.seconds("death", "death time")
+	wait := f.seconds("wait", "wait time")
+	levelXP := f.number("level_xp", "XP for each level")
+	heistXP := f.number("heist_xp", "XP for each heist")
+	spreeBonus := f.number("spree_bonus", "spree bonus")
+	spreeCap := f.number("spree_cap", "spree cap")
+	spreePenalty := f.number("spree_penalty", "spree penalty")
+	breakoutChance := f.number("breakout_chance", "breakout chance")
+	breakoutAlert := f.number("breakout_alert", "fugitive police alert")
+	breakoutPenalty := f.number("breakout_penalty", "breakout sentence penalty")
+	breakoutBail := f.number("breakout_bail", "breakout bail penalty")
+	milestones, err := heist.ParseMilestones(f.text("spree_milestones"))
+	if err != nil {
+		f.problems = append(f.problems, err)
+	}
+	theme := f.text("theme")
+	vote := f.text("vote")
+	output := f.text("output")
+	hardcore := f.text("hardcore") == "on"
+	leaveRefund := f.text("leave_refund") == "on"
+	if err := f.err(); err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+
+	var newConfig heist.Config
+	oldConfig, err := heist.UpdateConfig(sess.GuildID, func(cfg *heist.Config) {
+		cfg.HeistCost = cost
+		cfg.BailBase = bail
+		cfg.SentenceBase = sentence
+		cfg.PoliceAlert = patrol
+		cfg.DeathTimer = death
+		cfg.WaitTime = wait
+		cfg.LevelXP = levelXP
+		cfg.HeistXP = heistXP
+		cfg.SpreeBonus = spreeBonus
+		cfg.SpreeCap = spreeCap
+		cfg.SpreePenalty = spreePenalty
+		cfg.SpreeMilestones = milestones
+		cfg.BreakoutChance = breakoutChance
+		cfg.BreakoutAlert = breakoutAlert
+		cfg.BreakoutPenalty = breakoutPenalty
+		cfg.BreakoutBail = breakoutBail
+		cfg.Theme = theme
+		cfg.TargetVote = vote
+		cfg.CrewOutput = output
+		cfg.Hardcore = hardcore
+		cfg.LeaveRefund = leaveRefund
+		newConfig = *cfg
+	})
+	if err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+	record(sess, "heist", "Cost", oldConfig.HeistCost, newConfig.HeistCost)
+	record(sess, "heist", "Bail", oldConfig.BailBase, newConfig.BailBase)
+	record(sess, "heist", "Sentence", oldConfig.SentenceBase, newConfig.SentenceBase)
+	record(sess, "heist", "Police Alert", oldConfig.PoliceAlert, newConfig.PoliceAlert)
+	record(sess, "heist", "Death Timer", oldConfig.DeathTimer, newConfig.DeathTimer)
+	record(sess, "heist", "Wait Time", oldConfig.WaitTime, newConfig.WaitTime)
+	record(sess, "heist", "Level XP", oldConfig.LevelXP, newConfig.LevelXP)
+	record(sess, "heist", "Heist XP", oldConfig.HeistXP, newConfig.HeistXP)
+	record(sess, "heist", "Spree Bonus", oldConfig.SpreeBonus, newConfig.SpreeBonus)
+	record(sess, "heist", "Spree Cap", oldConfig.SpreeCap, newConfig.SpreeCap)
+	record(sess, "heist", "Spree Penalty", oldConfig.SpreePenalty, newConfig.SpreePenalty)
+	record(sess, "heist", "Spree Milestones", heist.FormatMilestones(oldConfig.SpreeMilestones), heist.FormatMilestones(newConfig.SpreeMilestones))
+	record(sess, "heist", "Breakout Chance", oldConfig.BreakoutChance, newConfig.BreakoutChance)
+	record(sess, "heist", "Fugitive Alert", oldConfig.BreakoutAlert, newConfig.BreakoutAlert)
+	record(sess, "heist", "Breakout Penalty", oldConfig.BreakoutPenalty, newConfig.BreakoutPenalty)
+	record(sess, "heist", "Breakout Bail", oldConfig.BreakoutBail, newConfig.BreakoutBail)
+	record(sess, "heist", "Theme", oldConfig.Theme, newConfig.Theme)
+	record(sess, "heist", "Target Vote", oldConfig.TargetVote, newConfig.TargetVote)
+	record(sess, "heist", "Crew Output", oldConfig.CrewOutput, newConfig.CrewOutput)
+	record(sess, "heist", "Hardcore", oldConfig.Hardcore, newConfig.Hardcore)
+	record(sess, "heist", "Leave Refund", oldConfig.LeaveRefund, newConfig.LeaveRefund)
+
+	redirect(w, r, "/dashboard/", "The heist settings have been saved.", nil)
+}
+
+// updateRaceSettings changes the race settings for the server.
+func updateRaceSettings(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.updateRaceSettings")
+	defer log.Trace("<-- dashboard.updateRaceSettings")
+
+	f := newForm(r)
+	mode := f.text("mode")
+	currency := f.text("currency")
+	bet := f.number("bet", "bet amount")
+	prizeMin := f.number("prize_min", "minimum prize")
+	prizeMax := f.number("prize_max", "maximum prize")
+	minRacers := f.number("min_racers", "minimum racers")
+	maxRacers := f.number("max_racers", "maximum racers")
+	waitJoin := f.seconds("wait_join", "time to join")
+	waitBet := f.seconds("wait_bet", "time to bet")
+	waitRaces := f.seconds("wait_races", "time between races")
+	if err := f.err(); err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+
+	var newConfig race.Config
+	oldConfig, err := race.UpdateConfig(sess.GuildID, func(cfg *race.Config) {
+		cfg.Mode = mode
+		cfg.Currency = currency
+		cfg.BetAmount = int(bet)
+		cfg.PrizeMin = int(prizeMin)
+		cfg.PrizeMax = int(prizeMax)
+		cfg.MinRacers = int(minRacers)
+		cfg.MaxRacers = int(maxRacers)
+		cfg.WaitForJoin = waitJoin
+		cfg.WaitForBetting = waitBet
+		cfg.WaitBetweenRaces = waitRaces
+		newConfig = *cfg
+	})
+	if err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+	record(sess, "race", "Mode", oldConfig.Mode, newConfig.Mode)
+	record(sess, "race", "Currency", oldConfig.Currency, newConfig.Currency)
+	record(sess, "race", "Bet Amount", oldConfig.BetAmount, newConfig.BetAmount)
+	record(sess, "race", "Minimum Prize", oldConfig.PrizeMin, newConfig.PrizeMin)
+	record(sess, "race", "Maximum Prize", oldConfig.PrizeMax, newConfig.PrizeMax)
+	record(sess, "race", "Minimum Racers", oldConfig.MinRacers, newConfig.MinRacers)
+	record(sess, "race", "Maximum Racers", oldConfig.MaxRacers, newConfig.MaxRacers)
+	record(sess, "race", "Wait For Join", oldConfig.WaitForJoin, newConfig.WaitForJoin)
+	record(sess, "race", "Wait For Betting", oldConfig.WaitForBetting, newConfig.WaitForBetting)
+	record(sess, "race", "Wait Between Races", oldConfig.WaitBetweenRaces, newConfig.WaitBetweenRaces)
+
+	redirect(w, r, "/dashboard/", "The race settings have been saved.", nil)
+}
+
+// updatePaydaySettings changes the payday settings for the server.
+func updatePaydaySettings(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.updatePaydaySettings")
+	defer log.Trace("<-- dashboard.updatePaydaySettings")
+
+	f := newForm(r)
+	amount := f.number("amount", "amount")
+	frequency := f.seconds("frequency", "frequency")
+	if err := f.err(); err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+
+	oldAmount := payday.GetPaydayAmount(sess.GuildID)
+	oldFrequency := payday.GetPaydayFrequency(sess.GuildID)
+	err := payday.SetPaydayAmount(sess.GuildID, amount)
+	if err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+	record(sess, "payday", "Amount", oldAmount, amount)
+	err = payday.SetPaydayFrequency(sess.GuildID, frequency)
+	if err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+	record(sess, "payday", "Frequency", oldFrequency, frequency)
+
+	redirect(w, r, "/dashboard/", "The payday settings have been saved.", nil)
+}
+
+// updateEconomySettings changes the bank settings for the server.
+func updateEconomySettings(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.updateEconomySettings")
+	defer log.Trace("<-- dashboard.updateEconomySettings")
+
+	f := newForm(r)
+	bankName := f.text("bank_name")
+	currency := f.text("currency")
+	balance := f.number("default_balance", "default balance")
+	if err := f.err(); err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+
+	oldConfig, err := economy.UpdateBankConfig(sess.GuildID, func(cfg *economy.BankConfig) {
+		cfg.BankName = bankName
+		cfg.Currency = currency
+		cfg.DefaultBalance = int(balance)
+	})
+	if err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+	record(sess, "bank", "Bank Name", oldConfig.BankName, bankName)
+	record(sess, "bank", "Currency", oldConfig.Currency, currency)
+	record(sess, "bank", "Default Balance", oldConfig.DefaultBalance, int(balance))
+
+	redirect(w, r, "/dashboard/", "The bank settings have been saved.", nil)
+}
+
+// resetHeist cancels a hung heist on the server.
+func resetHeist(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.resetHeist")
+	defer log.Trace("<-- dashboard.resetHeist")
+
+	crew, err := heist.ResetHeist(shard.Session(sess.GuildID), sess.GuildID)
+	if err != nil {
+		redirect(w, r, "/dashboard/", "", err)
+		return
+	}
+	record(sess, "heist reset", "Heist", strings.Join(crew, ", "), nil)
+
+	redirect(w, r, "/dashboard/", "The heist has been reset.", nil)
+}
+
+// resetRace cancels a hung race on the server.
+func resetRace(w http.ResponseWriter, r *http.Request, sess *session) {
+	log.Trace("--> dashboard.resetRace")
+	defer log.Trace("<-- dashboard.resetRace")
+
+	racers := race.ResetRace(sess.GuildID)
+	record(sess, "race reset", "Race", strings.Join(racers, ", "), nil)
+
+	redirect(w, r, "/dashboard/", "The race has been reset.", nil)
+}
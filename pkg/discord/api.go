@@ -0,0 +1,83 @@
+package discord
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/api"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	apiCommandHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"api": apiCommand,
+	}
+
+	apiCommands = []*discordgo.ApplicationCommand{
+		{
+			Name:                     "api",
+			Description:              "Manages access to the game data for this server using the API.",
+			DefaultMemberPermissions: &permission.AdminPermissions,
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "token",
+					Description: "Creates a new API token for this server, replacing the old one.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+				},
+				{
+					Name:        "revoke",
+					Description: "Revokes the API token for this server.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+				},
+			},
+		},
+	}
+)
+
+// apiCommand routes the API commands to the proper handlers.
+func apiCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> apiCommand")
+	defer log.Trace("<-- apiCommand")
+
+	options := i.ApplicationCommandData().Options
+	switch options[0].Name {
+	case "token":
+		createAPIToken(s, i)
+	case "revoke":
+		revokeAPIToken(s, i)
+	}
+}
+
+// createAPIToken creates a new API token for the server and shows it to the admin who created it.
+func createAPIToken(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> createAPIToken")
+	defer log.Trace("<-- createAPIToken")
+
+	token, err := api.NewToken(i.GuildID, i.Member.User.ID)
+	if err != nil {
+		log.Error("Unable to create an API token, error:", err)
+		msg.SendEphemeralResponse(s, i, "Unable to create an API token.")
+		return
+	}
+	audit.Record(s, i, "/api token", "API token", nil, "created")
+
+	msg.SendEphemeralResponse(s, i, "Your new API token is shown below. It won't be shown again, so keep it somewhere safe. "+
+		"Send it in the `Authorization: Bearer <token>` header of each request. Any token created before no longer works.\n"+
+		"```\n"+token+"\n```")
+}
+
+// revokeAPIToken revokes the API token for the server.
+func revokeAPIToken(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> revokeAPIToken")
+	defer log.Trace("<-- revokeAPIToken")
+
+	err := api.RevokeToken(i.GuildID)
+	if err != nil {
+		msg.SendEphemeralResponse(s, i, "There is no API token for this server.")
+		return
+	}
+	audit.Record(s, i, "/api revoke", "API token", nil, "revoked")
+
+	msg.SendEphemeralResponse(s, i, "The API token for this server has been revoked.")
+}
@@ -1,16 +1,20 @@
 package discord
 
 import (
-	"os"
-
 	"github.com/bwmarrin/discordgo"
-	"github.com/joho/godotenv"
+	"github.com/rbrabson/heist/pkg/api"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
 	"github.com/rbrabson/heist/pkg/cogs/economy"
 	"github.com/rbrabson/heist/pkg/cogs/heist"
 	"github.com/rbrabson/heist/pkg/cogs/payday"
 	"github.com/rbrabson/heist/pkg/cogs/race"
 	"github.com/rbrabson/heist/pkg/cogs/remind"
-	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/config"
+	"github.com/rbrabson/heist/pkg/dashboard"
+	"github.com/rbrabson/heist/pkg/event"
+	"github.com/rbrabson/heist/pkg/logging"
+	"github.com/rbrabson/heist/pkg/scheduler"
+	"github.com/rbrabson/heist/pkg/shard"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -19,13 +23,13 @@ const (
 		discordgo.IntentGuildMessages |
 		discordgo.IntentDirectMessages |
 		discordgo.IntentGuildEmojis
-	botVersion = "1.0.0"
 )
 
 // Bot is a Discord bot which is capable of running multiple sub-bots ("cogs"), which implement various commands.
+// The bot runs one session for each gateway shard it owns, and all sessions share the state of the cogs.
 type Bot struct {
-	Session *discordgo.Session
-	timer   chan int
+	Sessions []*discordgo.Session
+	timer    chan int
 }
 
 // addCommands adds the commands from a given cog to the overall set
@@ -50,26 +54,26 @@ func addCommands(componentHandlers map[string]func(*discordgo.Session, *discordg
 }
 
 // NewBot creates a new Discord bot that can run commands for various services.
-func NewBot() *Bot {
-	godotenv.Load()
-	guildID := os.Getenv("HEIST_GUILD_ID")
-	appID := os.Getenv("APP_ID")
+func NewBot(cfg *config.Config) *Bot {
+	guildID := cfg.Bot.GuildID
+	appID := cfg.Bot.AppID
 
-	token := os.Getenv("BOT_TOKEN")
-	s, err := discordgo.New("Bot " + token)
+	sessions, err := shard.NewSessions(cfg.Bot.Token, cfg.Bot.ShardCount, cfg.Bot.ShardIDs)
 	if err != nil {
 		log.Fatal("Failed to create new bot, error:", err)
 	}
 
 	bot := &Bot{
-		Session: s,
-		timer:   make(chan int),
+		Sessions: sessions,
+		timer:    make(chan int),
 	}
-	bot.Session.Identify.Intents = botIntents
-
-	bot.Session.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
-		log.Info("Game bot is up!")
-	})
+	for _, s := range bot.Sessions {
+		s.Identify.Intents = botIntents
+		s.AddHandler(func(s *discordgo.Session, r *discordgo.Ready) {
+			log.WithFields(log.Fields{"ShardID": s.ShardID, "ShardCount": s.ShardCount, "Guilds": len(r.Guilds)}).Info("Game bot is up!")
+		})
+	}
+	session := bot.Sessions[0]
 
 	componentHandlers := make(map[string]func(*discordgo.Session, *discordgo.InteractionCreate))
 	commandHandlers := make(map[string]func(*discordgo.Session, *discordgo.InteractionCreate))
@@ -79,39 +83,82 @@ func NewBot() *Bot {
 	for key, value := range helpCommandHandler {
 		commandHandlers[key] = value
 	}
+	for key, value := range helpComponentHandler {
+		componentHandlers[key] = value
+	}
+	commands = append(commands, logCommands...)
+	for key, value := range logCommandHandler {
+		commandHandlers[key] = value
+	}
+	commands = append(commands, statusCommands...)
+	for key, value := range statusCommandHandler {
+		commandHandlers[key] = value
+	}
+	commands = append(commands, jobCommands...)
+	for key, value := range jobCommandHandler {
+		commandHandlers[key] = value
+	}
+	commands = append(commands, reloadCommands...)
+	for key, value := range reloadCommandHandler {
+		commandHandlers[key] = value
+	}
+	if cfg.API.Enabled {
+		commands = append(commands, apiCommands...)
+		for key, value := range apiCommandHandler {
+			commandHandlers[key] = value
+		}
+	}
+	if cfg.Dashboard.Enabled {
+		commands = append(commands, dashboardCommands...)
+		for key, value := range dashboardCommandHandler {
+			commandHandlers[key] = value
+		}
+	}
 
-	economy.Start(bot.Session)
+	economy.Start(session)
 	commands = addCommands(componentHandlers, commandHandlers, commands, economy.GetCommands)
 
-	heist.Start(bot.Session)
+	heist.Start(session)
 	commands = addCommands(componentHandlers, commandHandlers, commands, heist.GetCommands)
 
-	payday.Start(bot.Session)
+	payday.Start(session)
 	commands = addCommands(componentHandlers, commandHandlers, commands, payday.GetCommands)
 
-	race.Start(bot.Session)
+	race.Start(session)
 	commands = addCommands(componentHandlers, commandHandlers, commands, race.GetCommands)
 
-	remind.Start(bot.Session)
+	remind.Start(session)
 	commands = addCommands(componentHandlers, commandHandlers, commands, remind.GetCommands)
 
+	audit.Start(session)
+	commands = addCommands(componentHandlers, commandHandlers, commands, audit.GetCommands)
+
+	if cfg.Store.Watch {
+		watchCatalogs()
+	}
+
 	log.Debug("Add bot handlers")
-	bot.Session.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
-		if i.User != nil {
-			msg.SendEphemeralResponse(s, i, "Bot commands are only usable in the server.")
-			return
-		}
-		switch i.Type {
-		case discordgo.InteractionApplicationCommand:
-			if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
-				h(s, i)
+	for _, s := range bot.Sessions {
+		s.AddHandler(func(s *discordgo.Session, i *discordgo.InteractionCreate) {
+			if i.User != nil {
+				handleDM(s, i, commandHandlers)
+				return
 			}
-		case discordgo.InteractionMessageComponent:
-			if h, ok := componentHandlers[i.MessageComponentData().CustomID]; ok {
-				h(s, i)
+			entry := logging.WithInteraction(log.StandardLogger(), i)
+			switch i.Type {
+			case discordgo.InteractionApplicationCommand:
+				if h, ok := commandHandlers[i.ApplicationCommandData().Name]; ok {
+					entry.WithField("command", i.ApplicationCommandData().Name).Debug("Received command")
+					h(s, i)
+				}
+			case discordgo.InteractionMessageComponent:
+				if h, ok := componentHandlers[i.MessageComponentData().CustomID]; ok {
+					entry.WithField("component", i.MessageComponentData().CustomID).Debug("Received component")
+					h(s, i)
+				}
 			}
-		}
-	})
+		})
+	}
 
 	/*
 		// Delete any old slash commands, and then add in my current set
@@ -123,10 +170,37 @@ func NewBot() *Bot {
 	*/
 
 	log.Debug("Add bot commands")
-	_, err = bot.Session.ApplicationCommandBulkOverwrite(appID, guildID, commands)
+	restrictToGuilds(commands)
+	_, err = session.ApplicationCommandBulkOverwrite(appID, guildID, commands)
 	if err != nil {
 		log.Fatal("Failed to load heist commands, error:", err)
 	}
 
 	return bot
 }
+
+// Open opens the gateway connection for each shard run by the bot, and starts the scheduled jobs, the dashboard
+// and the API.
+func (bot *Bot) Open() error {
+	if err := shard.Open(bot.Sessions); err != nil {
+		return err
+	}
+	scheduler.Start()
+	dashboard.Start(config.Get().Dashboard)
+	api.Start(config.Get().API)
+	return nil
+}
+
+// Close stops the API and the scheduled jobs, waits for event handlers to finish, and closes the gateway connection
+// for each shard run by the bot.
+func (bot *Bot) Close() {
+	api.Stop()
+	scheduler.Stop()
+	event.Wait()
+	for _, s := range bot.Sessions {
+		err := s.Close()
+		if err != nil {
+			log.WithField("ShardID", s.ShardID).Error("Unable to close the shard, error:", err)
+		}
+	}
+}
@@ -1,27 +1,49 @@
 package discord
 
 import (
+	"strings"
+
 	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/help"
 	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
 	log "github.com/sirupsen/logrus"
 )
 
 var (
+	helpComponentHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"help_topic":     selectHelpTopic,
+		"help_command":   selectHelpCommand,
+		"help_command_2": selectHelpCommand,
+		"help_command_3": selectHelpCommand,
+		"help_command_4": selectHelpCommand,
+	}
+
 	helpCommandHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
-		"help":      help,
-		"adminhelp": adminHelp,
-		"version":   version,
+		"help":    helpCommand,
+		"version": version,
 	}
 
 	helpCommands = []*discordgo.ApplicationCommand{
 		{
-
 			Name:        "help",
-			Description: "Provides a description of commands for this server.",
-		},
-		{
-			Name:        "adminhelp",
-			Description: "Provides a description of admin commands for this server.",
+			Description: "Provides a description of the commands you may use on this server.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Type:        discordgo.ApplicationCommandOptionString,
+					Name:        "topic",
+					Description: "The topic to get help for.",
+					Required:    false,
+					Choices: []*discordgo.ApplicationCommandOptionChoice{
+						{Name: "Economy", Value: "economy"},
+						{Name: "Heist", Value: "heist"},
+						{Name: "Race", Value: "race"},
+						{Name: "Payday", Value: "payday"},
+						{Name: "Reminder", Value: "reminder"},
+						{Name: "Audit", Value: "audit"},
+					},
+				},
+			},
 		},
 		{
 			Name:        "version",
@@ -30,20 +52,89 @@ var (
 	}
 )
 
-// help sends a help message for player commands.
-func help(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	log.Trace("--> help")
-	log.Trace("<-- help")
+// helpCommand sends help for the topic selected by the member, or a list of topics if none was selected.
+func helpCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> helpCommand")
+	defer log.Trace("<-- helpCommand")
+
+	var topic *help.Topic
+	options := i.ApplicationCommandData().Options
+	if len(options) != 0 {
+		topic = getTopic(options[0].StringValue())
+	}
+
+	embed := getOverviewEmbed(i)
+	if topic != nil {
+		embed = getTopicEmbed(i, topic)
+	}
+
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: getHelpComponents(i, topic),
+			Flags:      discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Error("Unable to send the help message, error:", err)
+	}
+}
+
+// selectHelpTopic updates the help message to show the commands for the selected topic.
+func selectHelpTopic(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> selectHelpTopic")
+	defer log.Trace("<-- selectHelpTopic")
 
-	msg.SendEphemeralResponse(s, i, getMemberHelp())
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+	topic := getTopic(values[0])
+	if topic == nil {
+		msg.SendEphemeralResponse(s, i, "Topic "+values[0]+" does not exist.")
+		return
+	}
+
+	updateHelp(s, i, getTopicEmbed(i, topic), getHelpComponents(i, topic))
 }
 
-// adminHelp sends a help message for administrative commands.
-func adminHelp(s *discordgo.Session, i *discordgo.InteractionCreate) {
-	log.Trace("--> adminHelp")
-	log.Trace("<-- adminHelp")
+// selectHelpCommand updates the help message to show the details of the selected command.
+func selectHelpCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> selectHelpCommand")
+	defer log.Trace("<-- selectHelpCommand")
 
-	msg.SendResponse(s, i, getAdminHelp())
+	values := i.MessageComponentData().Values
+	if len(values) == 0 {
+		return
+	}
+	topicName, path, _ := strings.Cut(values[0], "/")
+	topic := getTopic(topicName)
+	if topic == nil {
+		msg.SendEphemeralResponse(s, i, "Topic "+topicName+" does not exist.")
+		return
+	}
+	cmd := topic.GetCommand(path)
+	if cmd == nil || !permission.HasPermissions(i, cmd.Permissions) {
+		msg.SendEphemeralResponse(s, i, "Command "+path+" does not exist.")
+		return
+	}
+
+	updateHelp(s, i, getCommandEmbed(topic, cmd), getHelpComponents(i, topic))
+}
+
+// updateHelp replaces the help message with the new embed and components.
+func updateHelp(s *discordgo.Session, i *discordgo.InteractionCreate, embed *discordgo.MessageEmbed, components []discordgo.MessageComponent) {
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseUpdateMessage,
+		Data: &discordgo.InteractionResponseData{
+			Embeds:     []*discordgo.MessageEmbed{embed},
+			Components: components,
+		},
+	})
+	if err != nil {
+		log.Error("Unable to update the help message, error:", err)
+	}
 }
 
 // version shows the version of heist you are running.
@@ -51,5 +142,5 @@ func version(s *discordgo.Session, i *discordgo.InteractionCreate) {
 	log.Trace("--> version")
 	defer log.Trace("<-- version")
 
-	msg.SendEphemeralResponse(s, i, "You are running Heist version "+botVersion+".")
+	msg.SendEphemeralResponse(s, i, "You are running Heist version "+Version+" ("+Revision+").")
 }
@@ -0,0 +1,57 @@
+package discord
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/dashboard"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	dashboardCommandHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"dashboard": dashboardCommand,
+	}
+
+	dashboardCommands = []*discordgo.ApplicationCommand{
+		{
+			Name:                     "dashboard",
+			Description:              "Sends you a link to log into the web dashboard for this server.",
+			DefaultMemberPermissions: &permission.AdminPermissions,
+		},
+	}
+)
+
+// dashboardCommand sends the admin a direct message with a link that logs them into the web dashboard
+// for the server.
+func dashboardCommand(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> dashboardCommand")
+	defer log.Trace("<-- dashboardCommand")
+
+	name := i.Member.Nick
+	if name == "" {
+		name = i.Member.User.Username
+	}
+	link, err := dashboard.NewLoginLink(i.GuildID, i.Member.User.ID, name)
+	if err != nil {
+		log.Error("Unable to create a dashboard login link, error:", err)
+		msg.SendEphemeralResponse(s, i, "Unable to create a login link for the dashboard.")
+		return
+	}
+
+	channel, err := s.UserChannelCreate(i.Member.User.ID)
+	if err == nil {
+		// Suppress the link preview, so Discord doesn't fetch the link before the admin uses it
+		_, err = s.ChannelMessageSendComplex(channel.ID, &discordgo.MessageSend{
+			Content: "Use this link to log into the dashboard. It may only be used once and expires in 15 minutes.\n<" + link + ">",
+			Flags:   discordgo.MessageFlagsSuppressEmbeds,
+		})
+	}
+	if err != nil {
+		log.WithField("Member", i.Member.User.ID).Warning("Unable to send the dashboard login link, error:", err)
+		msg.SendEphemeralResponse(s, i, "Unable to send you a direct message. Allow direct messages from server members and try again.")
+		return
+	}
+
+	msg.SendEphemeralResponse(s, i, "A login link for the dashboard has been sent to you in a direct message.")
+}
@@ -0,0 +1,279 @@
+package discord
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/logging"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/shard"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	guildPickerID  = "dm_guild_picker"
+	maxGuildPicker = 25
+	pickerTTL      = 5 * time.Minute
+	lookupTimeout  = 2 * time.Second // Time to find the member's servers before the response is deferred
+)
+
+var (
+	// dmCommands are the commands, or commands and subcommands, that may be used in a direct message.
+	// They only read or change data that belongs to the member, so they don't need to be run in the server.
+	dmCommands = map[string]bool{
+		"balance":       true,
+		"heist history": true,
+		"heist stats":   true,
+		"race stats":    true,
+		"reminder list": true,
+		"reminder del":  true,
+	}
+
+	pendingDMs  = make(map[string]*pendingDM)
+	pendingLock sync.Mutex
+)
+
+// pendingDM is a command sent in a direct message that is waiting for the member to pick the server
+// it applies to.
+type pendingDM struct {
+	interaction *discordgo.InteractionCreate
+	handler     func(*discordgo.Session, *discordgo.InteractionCreate)
+	members     map[string]*discordgo.Member
+	expires     time.Time
+}
+
+// sharedGuild is a server shared by the bot and a member.
+type sharedGuild struct {
+	name   string
+	member *discordgo.Member
+}
+
+// dmUsable returns `true` if the command, or any of its subcommands, may be used in a direct message.
+func dmUsable(name string) bool {
+	for command := range dmCommands {
+		if command == name || strings.HasPrefix(command, name+" ") {
+			return true
+		}
+	}
+	return false
+}
+
+// restrictToGuilds hides the commands that can't be used in a direct message from the command list
+// shown in direct messages.
+func restrictToGuilds(commands []*discordgo.ApplicationCommand) {
+	dmPermission := false
+	for _, command := range commands {
+		if !dmUsable(command.Name) {
+			command.DMPermission = &dmPermission
+		}
+	}
+}
+
+// commandName returns the name of the command and, if there is one, the subcommand that was used.
+func commandName(i *discordgo.InteractionCreate) string {
+	data := i.ApplicationCommandData()
+	if len(data.Options) != 0 && data.Options[0].Type == discordgo.ApplicationCommandOptionSubCommand {
+		return data.Name + " " + data.Options[0].Name
+	}
+	return data.Name
+}
+
+// handleDM handles an interaction sent in a direct message. Commands that may be used in a direct message
+// are run for the server the member shares with the bot. If the member shares more than one server with
+// the bot, they are asked to pick one first.
+func handleDM(s *discordgo.Session, i *discordgo.InteractionCreate, commandHandlers map[string]func(*discordgo.Session, *discordgo.InteractionCreate)) {
+	log.Trace("--> handleDM")
+	defer log.Trace("<-- handleDM")
+
+	switch i.Type {
+	case discordgo.InteractionApplicationCommand:
+		name := commandName(i)
+		handler, ok := commandHandlers[i.ApplicationCommandData().Name]
+		if !ok || !dmCommands[name] && !dmCommands[i.ApplicationCommandData().Name] {
+			msg.SendEphemeralResponse(s, i, "This command is only usable in the server.")
+			return
+		}
+		logging.WithInteraction(log.StandardLogger(), i).WithField("command", name).Debug("Received command in a direct message")
+
+		result := make(chan []*sharedGuild, 1)
+		go func() {
+			result <- getSharedGuilds(i.User.ID)
+		}()
+
+		select {
+		case guilds := <-result:
+			switch len(guilds) {
+			case 0:
+				msg.SendEphemeralResponse(s, i, "You aren't in any server that uses this bot.")
+			case 1:
+				handler(s, inGuild(i, i, guilds[0].member))
+			default:
+				askForGuild(s, i, handler, guilds, false)
+			}
+		case <-time.After(lookupTimeout):
+			// Finding the servers is taking too long to answer the command in time, so defer the response.
+			// The command can then only be run as the answer to the server picker, even for a single server.
+			err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+				Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+				Data: &discordgo.InteractionResponseData{
+					Flags: discordgo.MessageFlagsEphemeral,
+				},
+			})
+			if err != nil {
+				logging.WithInteraction(log.StandardLogger(), i).Error("Unable to defer the response, error:", err)
+				return
+			}
+			guilds := <-result
+			if len(guilds) == 0 {
+				msg.EditResponse(s, i, "You aren't in any server that uses this bot.")
+				return
+			}
+			askForGuild(s, i, handler, guilds, true)
+		}
+	case discordgo.InteractionMessageComponent:
+		if i.MessageComponentData().CustomID == guildPickerID {
+			guildPicked(s, i)
+		}
+	}
+}
+
+// askForGuild asks the member which server the command sent in a direct message applies to. If the response
+// to the command has been deferred, the question is sent by editing the response.
+func askForGuild(s *discordgo.Session, i *discordgo.InteractionCreate, handler func(*discordgo.Session, *discordgo.InteractionCreate), guilds []*sharedGuild, deferred bool) {
+	log.Trace("--> askForGuild")
+	defer log.Trace("<-- askForGuild")
+
+	if len(guilds) > maxGuildPicker {
+		guilds = guilds[:maxGuildPicker]
+	}
+	pending := &pendingDM{
+		interaction: i,
+		handler:     handler,
+		members:     make(map[string]*discordgo.Member, len(guilds)),
+		expires:     time.Now().Add(pickerTTL),
+	}
+	options := make([]discordgo.SelectMenuOption, 0, len(guilds))
+	for _, guild := range guilds {
+		pending.members[guild.member.GuildID] = guild.member
+		options = append(options, discordgo.SelectMenuOption{
+			Label: guild.name,
+			Value: guild.member.GuildID,
+		})
+	}
+
+	pendingLock.Lock()
+	for userID, p := range pendingDMs {
+		if time.Now().After(p.expires) {
+			delete(pendingDMs, userID)
+		}
+	}
+	pendingDMs[i.User.ID] = pending
+	pendingLock.Unlock()
+
+	content := "Which server is this for?"
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					CustomID:    guildPickerID,
+					Placeholder: "Pick a server",
+					Options:     options,
+				},
+			},
+		},
+	}
+	var err error
+	if deferred {
+		_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+			Content:    &content,
+			Components: &components,
+		})
+	} else {
+		err = s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+			Type: discordgo.InteractionResponseChannelMessageWithSource,
+			Data: &discordgo.InteractionResponseData{
+				Content:    content,
+				Flags:      discordgo.MessageFlagsEphemeral,
+				Components: components,
+			},
+		})
+	}
+	if err != nil {
+		logging.WithInteraction(log.StandardLogger(), i).Error("Unable to send the server picker, error:", err)
+	}
+}
+
+// guildPicked runs the command waiting for the member to pick a server.
+func guildPicked(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> guildPicked")
+	defer log.Trace("<-- guildPicked")
+
+	pendingLock.Lock()
+	pending, ok := pendingDMs[i.User.ID]
+	delete(pendingDMs, i.User.ID)
+	pendingLock.Unlock()
+
+	values := i.MessageComponentData().Values
+	if !ok || time.Now().After(pending.expires) || len(values) == 0 {
+		msg.SendEphemeralResponse(s, i, "That request has expired. Use the command again.")
+		return
+	}
+	member, ok := pending.members[values[0]]
+	if !ok {
+		msg.SendEphemeralResponse(s, i, "That server can't be used. Use the command again.")
+		return
+	}
+
+	pending.handler(s, inGuild(pending.interaction, i, member))
+}
+
+// inGuild returns a copy of the command sent in a direct message that appears to have been sent by the
+// member in their server. The response is sent to the interaction that is being answered, which is
+// either the command itself or the server picked for the command.
+func inGuild(command *discordgo.InteractionCreate, answered *discordgo.InteractionCreate, member *discordgo.Member) *discordgo.InteractionCreate {
+	interaction := *command.Interaction
+	interaction.ID = answered.ID
+	interaction.Token = answered.Token
+	interaction.GuildID = member.GuildID
+	interaction.Member = member
+	interaction.User = nil
+
+	return &discordgo.InteractionCreate{Interaction: &interaction}
+}
+
+// getSharedGuilds returns the servers, handled by the shards run by this process, that the user is a
+// member of. They are sorted by the name of the server. Members that aren't cached in the state are looked
+// up from Discord.
+func getSharedGuilds(userID string) []*sharedGuild {
+	log.Trace("--> getSharedGuilds")
+	defer log.Trace("<-- getSharedGuilds")
+
+	var guilds []*sharedGuild
+	for _, s := range shard.Sessions() {
+		s.State.RLock()
+		candidates := make([]*discordgo.Guild, len(s.State.Guilds))
+		copy(candidates, s.State.Guilds)
+		s.State.RUnlock()
+
+		for _, guild := range candidates {
+			member, err := s.State.Member(guild.ID, userID)
+			if err != nil {
+				member, err = s.GuildMember(guild.ID, userID)
+				if err != nil {
+					continue
+				}
+			}
+			m := *member
+			m.GuildID = guild.ID
+			guilds = append(guilds, &sharedGuild{name: guild.Name, member: &m})
+		}
+	}
+
+	sort.Slice(guilds, func(i, j int) bool {
+		return guilds[i].name < guilds[j].name
+	})
+	return guilds
+}
@@ -1,64 +1,209 @@
 package discord
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
 	"github.com/rbrabson/heist/pkg/cogs/economy"
 	"github.com/rbrabson/heist/pkg/cogs/heist"
 	"github.com/rbrabson/heist/pkg/cogs/payday"
 	"github.com/rbrabson/heist/pkg/cogs/race"
 	"github.com/rbrabson/heist/pkg/cogs/remind"
+	"github.com/rbrabson/heist/pkg/help"
+	"github.com/rbrabson/heist/pkg/permission"
 	log "github.com/sirupsen/logrus"
 )
 
-// getHelp gets help about player commands from all bots.
-func getMemberHelp() string {
-	log.Trace("--> getMemberHelp")
-	log.Trace("<-- getMemberHelp")
-
-	var sb strings.Builder
+const (
+	maxSelectOptions = 25
+	maxSelectText    = 100
+	maxCommandMenus  = 4 // A message has up to five rows of components, and the first is used to pick the topic
+)
 
-	for _, str := range economy.GetMemberHelp() {
-		sb.WriteString(str)
+// getTopics returns the help topics for all cogs.
+func getTopics() []*help.Topic {
+	return []*help.Topic{
+		economy.GetHelp(),
+		heist.GetHelp(),
+		race.GetHelp(),
+		payday.GetHelp(),
+		remind.GetHelp(),
+		audit.GetHelp(),
 	}
-	for _, str := range heist.GetMemberHelp() {
-		sb.WriteString(str)
+}
+
+// getTopic returns the help topic with the given name, or `nil` if there is no such topic.
+func getTopic(name string) *help.Topic {
+	for _, topic := range getTopics() {
+		if strings.EqualFold(topic.Name, name) {
+			return topic
+		}
 	}
-	for _, str := range payday.GetMemberHelp() {
-		sb.WriteString(str)
+	return nil
+}
+
+// getCommands returns the commands in the topic that the member is allowed to use.
+func getCommands(i *discordgo.InteractionCreate, topic *help.Topic) []*help.Command {
+	commands := make([]*help.Command, 0, len(topic.Commands))
+	for _, cmd := range topic.GetCommands() {
+		if permission.HasPermissions(i, cmd.Permissions) {
+			commands = append(commands, cmd)
+		}
 	}
-	for _, str := range race.GetMemberHelp() {
-		sb.WriteString(str)
+	return commands
+}
+
+// getVisibleTopics returns the topics that contain at least one command the member is allowed to use.
+func getVisibleTopics(i *discordgo.InteractionCreate) []*help.Topic {
+	topics := make([]*help.Topic, 0, len(getTopics()))
+	for _, topic := range getTopics() {
+		if len(getCommands(i, topic)) != 0 {
+			topics = append(topics, topic)
+		}
 	}
-	for _, str := range remind.GetMemberHelp() {
-		sb.WriteString(str)
+	return topics
+}
+
+// getOverviewEmbed returns the embed listing the help topics available to the member.
+func getOverviewEmbed(i *discordgo.InteractionCreate) *discordgo.MessageEmbed {
+	log.Trace("--> getOverviewEmbed")
+	defer log.Trace("<-- getOverviewEmbed")
+
+	var sb strings.Builder
+	sb.WriteString("Select a topic to see its commands.\n\n")
+	for _, topic := range getVisibleTopics(i) {
+		sb.WriteString("- **" + topic.Name + "**: " + topic.Description + "\n")
 	}
 
-	return sb.String()
+	return &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       "Help",
+		Description: sb.String(),
+	}
 }
 
-// getAdminHelp returns help about administrative commands for all bots.
-func getAdminHelp() string {
-	log.Trace("--> getAdminHelp")
-	log.Trace("<-- getAdminHelp")
+// getTopicEmbed returns the embed listing the commands in a topic that are available to the member.
+func getTopicEmbed(i *discordgo.InteractionCreate, topic *help.Topic) *discordgo.MessageEmbed {
+	log.Trace("--> getTopicEmbed")
+	defer log.Trace("<-- getTopicEmbed")
 
 	var sb strings.Builder
+	sb.WriteString(topic.Description + "\n\n")
+	for _, cmd := range getCommands(i, topic) {
+		sb.WriteString("- **" + cmd.Name() + "**: " + cmd.Description + "\n")
+	}
+
+	return &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       topic.Name + " Help",
+		Description: sb.String(),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: "Select a command to see its options and examples.",
+		},
+	}
+}
+
+// getCommandEmbed returns the embed with the detailed help for a single command.
+func getCommandEmbed(topic *help.Topic, cmd *help.Command) *discordgo.MessageEmbed {
+	log.Trace("--> getCommandEmbed")
+	defer log.Trace("<-- getCommandEmbed")
+
+	embed := &discordgo.MessageEmbed{
+		Type:        discordgo.EmbedTypeRich,
+		Title:       cmd.Name(),
+		Description: cmd.String(),
+		Footer: &discordgo.MessageEmbedFooter{
+			Text: topic.Name,
+		},
+	}
+	if cmd.Permissions != nil {
+		embed.Footer.Text += " - Admin command"
+	}
+
+	return embed
+}
 
-	for _, str := range economy.GetAdminHelp() {
-		sb.WriteString(str)
+// getHelpComponents returns the select menus used to pick a topic and, if a topic is selected,
+// a command within that topic.
+func getHelpComponents(i *discordgo.InteractionCreate, selected *help.Topic) []discordgo.MessageComponent {
+	log.Trace("--> getHelpComponents")
+	defer log.Trace("<-- getHelpComponents")
+
+	topicOptions := make([]discordgo.SelectMenuOption, 0, len(getTopics()))
+	for _, topic := range getVisibleTopics(i) {
+		topicOptions = append(topicOptions, discordgo.SelectMenuOption{
+			Label:       topic.Name,
+			Value:       strings.ToLower(topic.Name),
+			Description: truncate(topic.Description),
+			Default:     selected != nil && topic.Name == selected.Name,
+		})
+	}
+	components := []discordgo.MessageComponent{
+		discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    "help_topic",
+					Placeholder: "Select a topic",
+					Options:     topicOptions,
+				},
+			},
+		},
 	}
-	for _, str := range heist.GetAdminHelp() {
-		sb.WriteString(str)
+	if selected == nil {
+		return components
 	}
-	for _, str := range payday.GetAdminHelp() {
-		sb.WriteString(str)
+
+	// A select menu holds a limited number of options, so the commands are split across several menus
+	commands := getCommands(i, selected)
+	if len(commands) > maxSelectOptions*maxCommandMenus {
+		log.WithField("Topic", selected.Name).Warning("Too many commands to list in the help menus")
+		commands = commands[:maxSelectOptions*maxCommandMenus]
 	}
-	for _, str := range race.GetAdminHelp() {
-		sb.WriteString(str)
+	for start := 0; start < len(commands); start += maxSelectOptions {
+		end := min(start+maxSelectOptions, len(commands))
+		commandOptions := make([]discordgo.SelectMenuOption, 0, end-start)
+		for _, cmd := range commands[start:end] {
+			commandOptions = append(commandOptions, discordgo.SelectMenuOption{
+				Label:       cmd.Name(),
+				Value:       strings.ToLower(selected.Name) + "/" + cmd.Path,
+				Description: truncate(strings.ReplaceAll(cmd.Description, "**", "")),
+			})
+		}
+		placeholder := "Select a command"
+		if len(commands) > maxSelectOptions {
+			placeholder = fmt.Sprintf("Select a command (%d-%d)", start+1, end)
+		}
+		components = append(components, discordgo.ActionsRow{
+			Components: []discordgo.MessageComponent{
+				discordgo.SelectMenu{
+					MenuType:    discordgo.StringSelectMenu,
+					CustomID:    commandMenuID(start / maxSelectOptions),
+					Placeholder: placeholder,
+					Options:     commandOptions,
+				},
+			},
+		})
 	}
-	for _, str := range remind.GetAdminHelp() {
-		sb.WriteString(str)
+
+	return components
+}
+
+// commandMenuID returns the custom ID of the select menu used to pick a command. Each menu in a message
+// needs its own ID.
+func commandMenuID(menu int) string {
+	if menu == 0 {
+		return "help_command"
 	}
+	return fmt.Sprintf("help_command_%d", menu+1)
+}
 
-	return sb.String()
+// truncate shortens the text so it may be used in a select menu.
+func truncate(text string) string {
+	if len(text) <= maxSelectText {
+		return text
+	}
+	return text[:maxSelectText-3] + "..."
 }
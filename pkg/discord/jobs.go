@@ -0,0 +1,66 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/format"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+	"github.com/rbrabson/heist/pkg/scheduler"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxJobListLength = 1800
+)
+
+var (
+	jobCommandHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"jobs": listJobs,
+	}
+
+	jobCommands = []*discordgo.ApplicationCommand{
+		{
+			Name:                     "jobs",
+			Description:              "Lists the jobs scheduled by the bot.",
+			DefaultMemberPermissions: &permission.AdminPermissions,
+		},
+	}
+)
+
+// listJobs lists the jobs run by the bot for all servers, along with the one-time jobs scheduled for this server.
+func listJobs(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> listJobs")
+	defer log.Trace("<-- listJobs")
+
+	var sb strings.Builder
+	for _, job := range scheduler.GetJobs() {
+		if job.GuildID != "" && job.GuildID != i.GuildID {
+			continue
+		}
+		if sb.Len() > maxJobListLength {
+			sb.WriteString("...")
+			break
+		}
+		sb.WriteString(fmt.Sprintf("**%s**: %s\n", job.Name, job.Schedule))
+		if job.Running {
+			sb.WriteString("- Running now\n")
+		} else if !job.NextRun.IsZero() {
+			sb.WriteString("- Next run in " + format.Duration(time.Until(job.NextRun)) + "\n")
+		}
+		if job.Runs != 0 {
+			sb.WriteString(fmt.Sprintf("- Last run %s ago, took %s (%d runs)\n", format.Duration(time.Since(job.LastRun)), job.LastDuration.Round(time.Millisecond), job.Runs))
+		}
+		if job.LastError != "" {
+			sb.WriteString("- Last error: " + job.LastError + "\n")
+		}
+	}
+	if sb.Len() == 0 {
+		sb.WriteString("There are no scheduled jobs.")
+	}
+
+	msg.SendEphemeralResponse(s, i, sb.String())
+}
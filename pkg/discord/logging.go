@@ -0,0 +1,153 @@
+package discord
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/logging"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	logCommandHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"log": logRouter,
+	}
+
+	logLevelChoices = []*discordgo.ApplicationCommandOptionChoice{
+		{Name: "panic", Value: "panic"},
+		{Name: "fatal", Value: "fatal"},
+		{Name: "error", Value: "error"},
+		{Name: "warning", Value: "warning"},
+		{Name: "info", Value: "info"},
+		{Name: "debug", Value: "debug"},
+		{Name: "trace", Value: "trace"},
+		{Name: "default", Value: "default"},
+	}
+
+	logCommands = []*discordgo.ApplicationCommand{
+		{
+			Name:        "log",
+			Description: "Owner commands used to configure logging for the bot.",
+			Options: []*discordgo.ApplicationCommandOption{
+				{
+					Name:        "level",
+					Description: "Sets the log level for the bot or a single cog.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+					Options: []*discordgo.ApplicationCommandOption{
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "level",
+							Description: "The log level. Use `default` to have a cog use the bot's level.",
+							Required:    true,
+							Choices:     logLevelChoices,
+						},
+						{
+							Type:        discordgo.ApplicationCommandOptionString,
+							Name:        "cog",
+							Description: "The cog to set the level for. Defaults to the whole bot.",
+							Required:    false,
+						},
+					},
+				},
+				{
+					Name:        "show",
+					Description: "Shows the log levels for the bot and each cog.",
+					Type:        discordgo.ApplicationCommandOptionSubCommand,
+				},
+			},
+		},
+	}
+)
+
+// logRouter routes the log commands to the proper handlers. The commands may only be used by the bot owners.
+func logRouter(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> logRouter")
+	defer log.Trace("<-- logRouter")
+
+	if !permission.IsOwner(s, i) {
+		msg.SendEphemeralResponse(s, i, "Only the bot owner may use this command.")
+		return
+	}
+
+	options := i.ApplicationCommandData().Options
+	switch options[0].Name {
+	case "level":
+		setLogLevel(s, i)
+	case "show":
+		showLogLevels(s, i)
+	}
+}
+
+// setLogLevel sets the log level for the bot or a single cog.
+func setLogLevel(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> setLogLevel")
+	defer log.Trace("<-- setLogLevel")
+
+	var level, cog string
+	for _, option := range i.ApplicationCommandData().Options[0].Options {
+		switch option.Name {
+		case "level":
+			level = option.StringValue()
+		case "cog":
+			cog = strings.ToLower(strings.TrimSpace(option.StringValue()))
+		}
+	}
+
+	if cog != "" && !contains(logging.GetCogs(), cog) {
+		msg.SendEphemeralResponse(s, i, fmt.Sprintf("Cog `%s` does not exist. Available cogs: %s.", cog, strings.Join(logging.GetCogs(), ", ")))
+		return
+	}
+
+	if level == "default" {
+		if cog == "" {
+			msg.SendEphemeralResponse(s, i, "The `default` level may only be used for a cog.")
+			return
+		}
+		logging.ResetLevel(cog)
+		logging.WithInteraction(log.StandardLogger(), i).WithField("cog", cog).Info("Log level reset")
+		msg.SendEphemeralResponse(s, i, fmt.Sprintf("Cog `%s` now uses the default log level.", cog))
+		return
+	}
+
+	err := logging.SetLevel(cog, level)
+	if err != nil {
+		msg.SendEphemeralResponse(s, i, "Unable to set the log level: "+err.Error())
+		return
+	}
+	logging.WithInteraction(log.StandardLogger(), i).WithFields(log.Fields{"cog": cog, "level": level}).Info("Log level set")
+
+	if cog == "" {
+		msg.SendEphemeralResponse(s, i, fmt.Sprintf("Default log level set to `%s`.", level))
+	} else {
+		msg.SendEphemeralResponse(s, i, fmt.Sprintf("Log level for cog `%s` set to `%s`.", cog, level))
+	}
+}
+
+// showLogLevels shows the log levels for the bot and each cog.
+func showLogLevels(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> showLogLevels")
+	defer log.Trace("<-- showLogLevels")
+
+	defaultLevel, levels := logging.GetLevels()
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("**Default**: %s\n", defaultLevel))
+	for _, cog := range logging.GetCogs() {
+		sb.WriteString(fmt.Sprintf("- **%s**: %s\n", cog, levels[cog]))
+	}
+
+	msg.SendEphemeralResponse(s, i, sb.String())
+}
+
+// contains determines if the element is in the slice.
+func contains(list []string, element string) bool {
+	for _, a := range list {
+		if a == element {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+package discord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/cogs/heist"
+	"github.com/rbrabson/heist/pkg/cogs/race"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+	"github.com/rbrabson/heist/pkg/scheduler"
+	"github.com/rbrabson/heist/pkg/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	watchInterval = 10 * time.Second
+)
+
+var (
+	// catalogCollections are the collections that are reloaded when their documents change.
+	catalogCollections = []string{heist.THEME, heist.TARGET, race.MODE}
+)
+
+var (
+	reloadCommandHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"reload": reload,
+	}
+
+	reloadCommands = []*discordgo.ApplicationCommand{
+		{
+			Name:        "reload",
+			Description: "Owner command that reloads the heist themes and targets, and the race modes.",
+		},
+	}
+)
+
+// reload reloads the heist themes and targets, and the race modes, and reports the result to the bot owner.
+func reload(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> reload")
+	defer log.Trace("<-- reload")
+
+	if !permission.IsOwner(s, i) {
+		msg.SendEphemeralResponse(s, i, "Only the bot owner may use this command.")
+		return
+	}
+
+	result, err := reloadCatalogs()
+	if err != nil {
+		msg.SendEphemeralResponse(s, i, "Unable to reload:\n"+err.Error())
+		return
+	}
+	msg.SendEphemeralResponse(s, i, result)
+}
+
+// reloadCatalogs reloads the heist themes and targets, and the race modes. All of them are validated
+// before any replaces the ones in use, so a problem with one leaves them all unchanged. A summary of
+// what was reloaded is returned.
+func reloadCatalogs() (string, error) {
+	log.Trace("--> reloadCatalogs")
+	defer log.Trace("<-- reloadCatalogs")
+
+	heistCatalog, heistErr := heist.LoadCatalog()
+	raceCatalog, raceErr := race.LoadCatalog()
+	if err := errors.Join(heistErr, raceErr); err != nil {
+		return "", err
+	}
+
+	heistCatalog.Apply()
+	raceCatalog.Apply()
+
+	themeCount, targetCount := heistCatalog.Counts()
+	result := fmt.Sprintf("Reloaded %d heist themes and %d sets of heist targets.\n", themeCount, targetCount)
+	result += fmt.Sprintf("Reloaded %d race modes.\n", raceCatalog.Count())
+	return result, nil
+}
+
+// watchCatalogs adds a job that reloads the heist themes and targets, and the race modes, when their
+// documents in the store change. Only stores that report when a collection last changed can be watched.
+func watchCatalogs() {
+	log.Trace("--> watchCatalogs")
+	defer log.Trace("<-- watchCatalogs")
+
+	watcher, ok := store.Store.(store.Watcher)
+	if !ok {
+		log.WithField("Store", store.Store.Type()).Warning("The store does not support watching for changes")
+		return
+	}
+
+	lastModified := make(map[string]time.Time, len(catalogCollections))
+	for _, collection := range catalogCollections {
+		lastModified[collection], _ = watcher.LastModified(collection)
+	}
+
+	scheduler.Add(scheduler.Job{
+		Name:     "catalog-watch",
+		Schedule: scheduler.Every(watchInterval),
+		Run: func(ctx context.Context) error {
+			changed := false
+			for _, collection := range catalogCollections {
+				modified, err := watcher.LastModified(collection)
+				if err != nil {
+					continue
+				}
+				if modified.After(lastModified[collection]) {
+					lastModified[collection] = modified
+					changed = true
+				}
+			}
+			if !changed {
+				return nil
+			}
+
+			log.Info("Themes, targets or race modes changed, reloading")
+			_, err := reloadCatalogs()
+			return err
+		},
+	})
+}
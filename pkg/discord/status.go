@@ -0,0 +1,186 @@
+package discord
+
+import (
+	"fmt"
+	"runtime"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/cogs/audit"
+	"github.com/rbrabson/heist/pkg/cogs/economy"
+	"github.com/rbrabson/heist/pkg/cogs/heist"
+	"github.com/rbrabson/heist/pkg/cogs/payday"
+	"github.com/rbrabson/heist/pkg/cogs/race"
+	"github.com/rbrabson/heist/pkg/cogs/remind"
+	"github.com/rbrabson/heist/pkg/format"
+	"github.com/rbrabson/heist/pkg/msg"
+	"github.com/rbrabson/heist/pkg/permission"
+	"github.com/rbrabson/heist/pkg/shard"
+	"github.com/rbrabson/heist/pkg/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	maxFieldValue = 1024 // Maximum length of the value of an embed field
+)
+
+var (
+	// Version is the build version of the bot, which is set by the main package.
+	Version = "unknown"
+	// Revision is the build revision of the bot, which is set by the main package.
+	Revision = "unknown"
+
+	startTime = time.Now()
+)
+
+var (
+	statusCommandHandler = map[string]func(s *discordgo.Session, i *discordgo.InteractionCreate){
+		"status": status,
+	}
+
+	statusCommands = []*discordgo.ApplicationCommand{
+		{
+			Name:        "status",
+			Description: "Owner command that reports the status of the bot.",
+		},
+	}
+)
+
+// status reports diagnostic information about the bot to the bot owners.
+func status(s *discordgo.Session, i *discordgo.InteractionCreate) {
+	log.Trace("--> status")
+	defer log.Trace("<-- status")
+
+	if !permission.IsOwner(s, i) {
+		msg.SendEphemeralResponse(s, i, "Only the bot owner may use this command.")
+		return
+	}
+
+	// Checking the store may take longer than Discord waits for a response, so defer the response
+	err := s.InteractionRespond(i.Interaction, &discordgo.InteractionResponse{
+		Type: discordgo.InteractionResponseDeferredChannelMessageWithSource,
+		Data: &discordgo.InteractionResponseData{
+			Flags: discordgo.MessageFlagsEphemeral,
+		},
+	})
+	if err != nil {
+		log.Error("Unable to defer the bot status, error:", err)
+		return
+	}
+
+	storeHealth := "OK"
+	if err := store.Store.Ping(); err != nil {
+		storeHealth = "Error: " + err.Error()
+	}
+
+	embeds := []*discordgo.MessageEmbed{
+		{
+			Type:  discordgo.EmbedTypeRich,
+			Title: "Heist Status",
+			Fields: []*discordgo.MessageEmbedField{
+				{
+					Name:   "Version",
+					Value:  Version + " (" + Revision + ")",
+					Inline: true,
+				},
+				{
+					Name:   "Uptime",
+					Value:  format.Duration(time.Since(startTime)),
+					Inline: true,
+				},
+				{
+					Name:   "Goroutines",
+					Value:  fmt.Sprintf("%d", runtime.NumGoroutine()),
+					Inline: true,
+				},
+				{
+					Name:   "Shards",
+					Value:  getShardStatus(),
+					Inline: false,
+				},
+				{
+					Name:   "Store",
+					Value:  store.Store.Type() + ": " + storeHealth,
+					Inline: false,
+				},
+				{
+					Name:   "Cached Documents",
+					Value:  getCacheStatus(),
+					Inline: false,
+				},
+				{
+					Name:   "Active Heists",
+					Value:  formatActive(heist.GetActiveHeists(), "crew members"),
+					Inline: true,
+				},
+				{
+					Name:   "Active Races",
+					Value:  formatActive(race.GetActiveRaces(), "racers"),
+					Inline: true,
+				},
+			},
+		},
+	}
+
+	_, err = s.InteractionResponseEdit(i.Interaction, &discordgo.WebhookEdit{
+		Embeds: &embeds,
+	})
+	if err != nil {
+		log.Error("Unable to send the bot status, error:", err)
+	}
+}
+
+// getShardStatus returns the gateway latency and number of guilds for each shard run by this process.
+func getShardStatus() string {
+	var sb strings.Builder
+	totalGuilds := 0
+	for _, s := range shard.Sessions() {
+		guilds := 0
+		if s.State != nil {
+			s.State.RLock()
+			guilds = len(s.State.Guilds)
+			s.State.RUnlock()
+		}
+		totalGuilds += guilds
+		sb.WriteString(fmt.Sprintf("Shard %d/%d: %d guilds, %s latency\n", s.ShardID, s.ShardCount, guilds, s.HeartbeatLatency().Round(time.Millisecond)))
+	}
+	sb.WriteString(fmt.Sprintf("**Total guilds**: %d", totalGuilds))
+	return sb.String()
+}
+
+// getCacheStatus returns the number of documents cached by each cog.
+func getCacheStatus() string {
+	return fmt.Sprintf("Audit: %d, Economy: %d, Heist: %d, Payday: %d, Race: %d, Reminder: %d",
+		audit.GetServerCount(), economy.GetBankCount(), heist.GetServerCount(), payday.GetServerCount(), race.GetServerCount(), remind.GetServerCount())
+}
+
+// formatActive formats the number of participants in the active games for each guild, limited to
+// the length of an embed field.
+func formatActive(active map[string]int, participants string) string {
+	if len(active) == 0 {
+		return "None"
+	}
+	guildIDs := make([]string, 0, len(active))
+	for guildID := range active {
+		guildIDs = append(guildIDs, guildID)
+	}
+	sort.Strings(guildIDs)
+
+	var sb strings.Builder
+	for i, guildID := range guildIDs {
+		line := fmt.Sprintf("`%s`: %d %s\n", guildID, active[guildID], participants)
+		// Leave room for the line counting the guilds that don't fit in the field
+		size := sb.Len() + len(line)
+		if remaining := len(guildIDs) - i - 1; remaining > 0 {
+			size += len(fmt.Sprintf("…and %d more\n", remaining))
+		}
+		if size > maxFieldValue {
+			sb.WriteString(fmt.Sprintf("…and %d more\n", len(guildIDs)-i))
+			break
+		}
+		sb.WriteString(line)
+	}
+	return sb.String()
+}
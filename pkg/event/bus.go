@@ -0,0 +1,133 @@
+// Package event lets a cog tell the rest of the bot about something that happened in a game, such as a
+// heist finishing, without knowing who is interested in it. Events are notifications only. A cog that
+// needs an answer, such as whether a withdrawal from a bank account succeeded or the amount of a payday,
+// still calls the cog that owns the data.
+package event
+
+import (
+	"reflect"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	waitTimeout = 30 * time.Second
+)
+
+var (
+	subscribers = make(map[reflect.Type][]*subscriber)
+	nextID      uint64
+	mutex       sync.RWMutex
+	wg          sync.WaitGroup
+)
+
+// subscriber is a handler that is called each time an event of a given type is published.
+type subscriber struct {
+	id      uint64
+	async   bool
+	handler func(e interface{})
+}
+
+// Subscribe registers a handler that is called each time an event of type T is published. The handler
+// is run by the goroutine that publishes the event, so it should return quickly. The returned function
+// removes the subscription.
+func Subscribe[T any](handler func(e T)) func() {
+	return subscribe(false, handler)
+}
+
+// SubscribeAsync registers a handler that is called each time an event of type T is published. The
+// handler is run in its own goroutine, so it may take as long as it needs without delaying the game that
+// published the event. The returned function removes the subscription.
+func SubscribeAsync[T any](handler func(e T)) func() {
+	return subscribe(true, handler)
+}
+
+// subscribe registers the handler for events of type T.
+func subscribe[T any](async bool, handler func(e T)) func() {
+	eventType := reflect.TypeOf((*T)(nil)).Elem()
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	nextID++
+	sub := &subscriber{
+		id:    nextID,
+		async: async,
+		handler: func(e interface{}) {
+			handler(e.(T))
+		},
+	}
+	subscribers[eventType] = append(subscribers[eventType], sub)
+	log.WithFields(log.Fields{"Event": eventType.Name(), "Async": async}).Debug("Added event subscriber")
+
+	return func() {
+		unsubscribe(eventType, sub.id)
+	}
+}
+
+// unsubscribe removes the subscriber for the event type.
+func unsubscribe(eventType reflect.Type, id uint64) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	subs := subscribers[eventType]
+	for i, sub := range subs {
+		if sub.id == id {
+			// Copy so a publish that is in progress keeps using the old list of subscribers
+			newSubs := make([]*subscriber, 0, len(subs)-1)
+			newSubs = append(newSubs, subs[:i]...)
+			subscribers[eventType] = append(newSubs, subs[i+1:]...)
+			return
+		}
+	}
+}
+
+// Publish sends the event to all handlers subscribed to events of its type. Synchronous handlers have
+// finished by the time Publish returns; asynchronous ones may still be running. A handler that panics
+// is logged and doesn't affect the other handlers or the publisher.
+func Publish[T any](e T) {
+	eventType := reflect.TypeOf((*T)(nil)).Elem()
+
+	mutex.RLock()
+	subs := subscribers[eventType]
+	mutex.RUnlock()
+
+	for _, sub := range subs {
+		if sub.async {
+			wg.Add(1)
+			go func(sub *subscriber) {
+				defer wg.Done()
+				deliver(eventType, sub, e)
+			}(sub)
+			continue
+		}
+		deliver(eventType, sub, e)
+	}
+}
+
+// deliver calls the subscriber's handler for the event, recovering from any panic in the handler.
+func deliver(eventType reflect.Type, sub *subscriber, e interface{}) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.WithField("Event", eventType.Name()).Error("Event handler panicked, error:", r)
+		}
+	}()
+	sub.handler(e)
+}
+
+// Wait waits for the asynchronous handlers that are running to finish, so events aren't lost when the
+// bot shuts down.
+func Wait() {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(waitTimeout):
+		log.Warning("Timed out waiting for event handlers to finish")
+	}
+}
@@ -0,0 +1,75 @@
+package event
+
+import (
+	"time"
+)
+
+// HeistCompleted is published when a heist has finished and the loot has been handed out.
+type HeistCompleted struct {
+	GuildID     string         // Guild on which the heist took place
+	Target      string         // Target that was hit by the crew
+	Crew        []*HeistMember // Result for each member of the crew
+	Escaped     int            // Number of crew members who escaped
+	Apprehended int            // Number of crew members who were caught
+	Dead        int            // Number of crew members who died
+	Time        time.Time      // Time the heist ended
+}
+
+// HeistMember is the result of a heist for a single member of the crew.
+type HeistMember struct {
+	MemberID string // ID of the member
+	Name     string // Name of the member
+	Status   string // One of `Free`, `Apprehended` or `Dead`
+	Stolen   int    // Credits stolen by the member
+	Bonus    int    // Bonus credits earned by the member
+}
+
+// PlayerJailed is published when a member of a heist crew is sent to jail.
+type PlayerJailed struct {
+	GuildID  string        // Guild on which the member was jailed
+	MemberID string        // ID of the member
+	Name     string        // Name of the member
+	Sentence time.Duration // Length of the sentence
+	BailCost int64         // Credits needed to be released on bail
+}
+
+// RaceFinished is published when a race has finished and the prizes have been handed out.
+type RaceFinished struct {
+	GuildID string        // Guild on which the race took place
+	Racers  []*RaceResult // Result for each racer, in the order they finished
+	Time    time.Time     // Time the race ended
+}
+
+// RaceResult is the result of a race for a single racer.
+type RaceResult struct {
+	MemberID string // ID of the member
+	Name     string // Name of the member
+	Position int    // Position the racer finished in, starting at 1
+	Prize    int    // Credits won by the racer
+}
+
+// BetSettled is published for each bet on a race once the race has finished.
+type BetSettled struct {
+	GuildID  string // Guild on which the race took place
+	MemberID string // ID of the member who placed the bet
+	Name     string // Name of the member who placed the bet
+	RacerID  string // ID of the racer the bet was placed on
+	Bet      int    // Credits bet on the race
+	Winnings int    // Credits won, or zero if the bet was lost
+}
+
+// PaydayCollected is published when a member collects their payday.
+type PaydayCollected struct {
+	GuildID    string    // Guild on which the payday was collected
+	MemberID   string    // ID of the member
+	Amount     int       // Credits deposited in the member's account
+	NextPayday time.Time // Time the member may collect their next payday
+}
+
+// BalanceChanged is published each time credits are deposited in or withdrawn from a bank account.
+type BalanceChanged struct {
+	GuildID  string // Guild of the bank holding the account
+	MemberID string // ID of the member who owns the account
+	Amount   int    // Credits added to the account, which is negative for a withdrawal
+	Balance  int    // Current balance of the account after the change
+}
@@ -0,0 +1,155 @@
+package help
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bwmarrin/discordgo"
+)
+
+// Topic is a category of commands shown by the help system, such as the commands for the heist game.
+type Topic struct {
+	Name        string                          // Name of the topic
+	Description string                          // Short description of the topic
+	Commands    []*discordgo.ApplicationCommand // Commands that belong to the topic
+	Details     map[string]*Detail              // Help that can't be determined from the commands, keyed by command path
+}
+
+// Detail is help for a single command that can't be determined from the command definition.
+type Detail struct {
+	Defaults map[string]string // Default values for optional options, keyed by the option name
+	Examples []string          // Examples of using the command
+}
+
+// Command is a single command, or subcommand, that may be run by a member.
+type Command struct {
+	Path        string                                // Path to the command, such as `heist bail`
+	Description string                                // Description of the command
+	Type        discordgo.ApplicationCommandType      // Type of the command
+	Options     []*discordgo.ApplicationCommandOption // Options for the command
+	Permissions *int64                                // Permissions required to use the command
+	Detail      *Detail                               // Additional help for the command
+}
+
+// GetCommands returns the commands, and subcommands, for the topic. Commands groups are
+// expanded so that each subcommand is returned as a separate command.
+func (t *Topic) GetCommands() []*Command {
+	commands := make([]*Command, 0, len(t.Commands))
+	for _, cmd := range t.Commands {
+		if cmd.Type == discordgo.UserApplicationCommand {
+			commands = append(commands, &Command{
+				Path:        cmd.Name,
+				Description: "Right-click a member and select **Apps > " + cmd.Name + "**.",
+				Type:        cmd.Type,
+				Permissions: cmd.DefaultMemberPermissions,
+				Detail:      t.Details[cmd.Name],
+			})
+			continue
+		}
+		commands = t.addOptions(commands, cmd, cmd.Name, cmd.Description, cmd.Options)
+	}
+	return commands
+}
+
+// addOptions adds the command, or its subcommands if it has any, to the list of commands.
+func (t *Topic) addOptions(commands []*Command, cmd *discordgo.ApplicationCommand, path string, description string, options []*discordgo.ApplicationCommandOption) []*Command {
+	hasSubcommands := false
+	for _, option := range options {
+		switch option.Type {
+		case discordgo.ApplicationCommandOptionSubCommand, discordgo.ApplicationCommandOptionSubCommandGroup:
+			hasSubcommands = true
+			commands = t.addOptions(commands, cmd, path+" "+option.Name, option.Description, option.Options)
+		}
+	}
+	if !hasSubcommands {
+		commands = append(commands, &Command{
+			Path:        path,
+			Description: description,
+			Type:        cmd.Type,
+			Options:     options,
+			Permissions: cmd.DefaultMemberPermissions,
+			Detail:      t.Details[path],
+		})
+	}
+	return commands
+}
+
+// GetCommand returns the command with the given path, or `nil` if there is no such command.
+func (t *Topic) GetCommand(path string) *Command {
+	for _, cmd := range t.GetCommands() {
+		if cmd.Path == path {
+			return cmd
+		}
+	}
+	return nil
+}
+
+// Name returns the name used when referring to the command.
+func (c *Command) Name() string {
+	if c.Type == discordgo.UserApplicationCommand {
+		return c.Path
+	}
+	return "/" + c.Path
+}
+
+// String returns the detailed help for the command, including its options, defaults and examples.
+func (c *Command) String() string {
+	var sb strings.Builder
+	sb.WriteString(c.Description)
+	sb.WriteString("\n")
+
+	if len(c.Options) != 0 {
+		sb.WriteString("\n**Options**\n")
+		for _, option := range c.Options {
+			required := "optional"
+			if option.Required {
+				required = "required"
+			}
+			sb.WriteString(fmt.Sprintf("- `%s` (%s, %s): %s", option.Name, optionType(option.Type), required, option.Description))
+			if c.Detail != nil {
+				if def, ok := c.Detail.Defaults[option.Name]; ok {
+					sb.WriteString(" Default: " + def + ".")
+				}
+			}
+			sb.WriteString("\n")
+			if len(option.Choices) != 0 {
+				choices := make([]string, 0, len(option.Choices))
+				for _, choice := range option.Choices {
+					choices = append(choices, "`"+choice.Name+"`")
+				}
+				sb.WriteString("  - Choices: " + strings.Join(choices, ", ") + "\n")
+			}
+		}
+	}
+
+	if c.Detail != nil && len(c.Detail.Examples) != 0 {
+		sb.WriteString("\n**Examples**\n")
+		for _, example := range c.Detail.Examples {
+			sb.WriteString("- `" + example + "`\n")
+		}
+	}
+
+	return sb.String()
+}
+
+// optionType returns a readable name for the type of an option.
+func optionType(t discordgo.ApplicationCommandOptionType) string {
+	switch t {
+	case discordgo.ApplicationCommandOptionString:
+		return "text"
+	case discordgo.ApplicationCommandOptionInteger:
+		return "whole number"
+	case discordgo.ApplicationCommandOptionNumber:
+		return "number"
+	case discordgo.ApplicationCommandOptionBoolean:
+		return "true or false"
+	case discordgo.ApplicationCommandOptionUser:
+		return "member"
+	case discordgo.ApplicationCommandOptionChannel:
+		return "channel"
+	case discordgo.ApplicationCommandOptionRole:
+		return "role"
+	default:
+		return t.String()
+	}
+}
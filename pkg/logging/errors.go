@@ -0,0 +1,7 @@
+package logging
+
+import "errors"
+
+var (
+	ErrInvalidFormat = errors.New("log format must be either `text` or `json`")
+)
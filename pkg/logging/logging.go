@@ -0,0 +1,174 @@
+package logging
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	TEXT = "text"
+	JSON = "json"
+)
+
+// Config is the logging configuration, which is part of the bot's configuration.
+type Config struct {
+	Level  string            `json:"level" yaml:"level"`   // Default log level for the bot
+	Format string            `json:"format" yaml:"format"` // Log format, either "text" or "json"
+	Cogs   map[string]string `json:"cogs" yaml:"cogs"`     // Log levels for individual cogs, which override the default level
+}
+
+var (
+	loggers      = make(map[string]*logrus.Logger)
+	cogLevels    = make(map[string]logrus.Level)
+	defaultLevel = logrus.InfoLevel
+	formatter    logrus.Formatter
+	mutex        sync.Mutex
+)
+
+// Apply applies the logging configuration to the standard logger and the loggers for all cogs.
+func Apply(config *Config) error {
+	if err := SetFormat(config.Format); err != nil {
+		return err
+	}
+	if err := SetLevel("", config.Level); err != nil {
+		return err
+	}
+	for cog, level := range config.Cogs {
+		if err := SetLevel(cog, level); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Logger returns the logger for the cog, creating it if necessary. The logger uses the level set
+// for the cog, or the default level if one hasn't been set.
+func Logger(cog string) *logrus.Logger {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	logger, ok := loggers[cog]
+	if !ok {
+		logger = logrus.New()
+		logger.SetOutput(logrus.StandardLogger().Out)
+		if formatter != nil {
+			logger.SetFormatter(formatter)
+		}
+		logger.SetLevel(levelFor(cog))
+		loggers[cog] = logger
+	}
+	return logger
+}
+
+// SetFormat sets the format, "text" or "json", used by all loggers.
+func SetFormat(format string) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	switch strings.ToLower(format) {
+	case "", TEXT:
+		formatter = &logrus.TextFormatter{FullTimestamp: true}
+	case JSON:
+		formatter = &logrus.JSONFormatter{}
+	default:
+		return ErrInvalidFormat
+	}
+	logrus.SetFormatter(formatter)
+	for _, logger := range loggers {
+		logger.SetFormatter(formatter)
+	}
+	return nil
+}
+
+// SetLevel sets the log level for the cog. If the cog is empty, the default level used by the bot
+// and by all cogs that don't have their own level is set instead.
+func SetLevel(cog string, level string) error {
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return err
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if cog == "" {
+		defaultLevel = lvl
+		logrus.SetLevel(lvl)
+	} else {
+		cogLevels[cog] = lvl
+	}
+	for name, logger := range loggers {
+		logger.SetLevel(levelFor(name))
+	}
+	return nil
+}
+
+// ResetLevel removes the log level for the cog, so the cog uses the default level.
+func ResetLevel(cog string) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	delete(cogLevels, cog)
+	if logger, ok := loggers[cog]; ok {
+		logger.SetLevel(defaultLevel)
+	}
+}
+
+// GetLevels returns the default log level and the log level used by each cog.
+func GetLevels() (logrus.Level, map[string]logrus.Level) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	levels := make(map[string]logrus.Level, len(loggers))
+	for name := range loggers {
+		levels[name] = levelFor(name)
+	}
+	return defaultLevel, levels
+}
+
+// GetCogs returns the sorted names of the cogs that have a logger.
+func GetCogs() []string {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	cogs := make([]string, 0, len(loggers))
+	for name := range loggers {
+		cogs = append(cogs, name)
+	}
+	sort.Strings(cogs)
+	return cogs
+}
+
+// levelFor returns the level for the cog. The mutex must be held by the caller.
+func levelFor(cog string) logrus.Level {
+	if level, ok := cogLevels[cog]; ok {
+		return level
+	}
+	return defaultLevel
+}
+
+// InteractionFields returns the log fields that identify an interaction: the guild, the user and
+// the interaction itself.
+func InteractionFields(i *discordgo.InteractionCreate) logrus.Fields {
+	fields := logrus.Fields{
+		"interaction_id": i.ID,
+	}
+	if i.GuildID != "" {
+		fields["guild_id"] = i.GuildID
+	}
+	if i.Member != nil && i.Member.User != nil {
+		fields["user_id"] = i.Member.User.ID
+	} else if i.User != nil {
+		fields["user_id"] = i.User.ID
+	}
+	return fields
+}
+
+// WithInteraction returns a log entry for the logger with the fields that identify the interaction.
+func WithInteraction(logger *logrus.Logger, i *discordgo.InteractionCreate) *logrus.Entry {
+	return logger.WithFields(InteractionFields(i))
+}
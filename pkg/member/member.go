@@ -0,0 +1,35 @@
+package member
+
+import (
+	"github.com/bwmarrin/discordgo"
+)
+
+// GetName returns the member's nickname, if there is one, or the username otherwise.
+func GetName(username string, nickname string) string {
+	if nickname != "" {
+		return nickname
+	}
+	return username
+}
+
+// GetTarget returns the member targeted by a user context menu command. The member returned by
+// Discord doesn't include the user, so the user is copied from the resolved users.
+func GetTarget(i *discordgo.InteractionCreate) *discordgo.Member {
+	data := i.ApplicationCommandData()
+	if data.Resolved == nil {
+		return nil
+	}
+
+	var target discordgo.Member
+	if m, ok := data.Resolved.Members[data.TargetID]; ok && m != nil {
+		target = *m
+	}
+	user, ok := data.Resolved.Users[data.TargetID]
+	if !ok || user == nil {
+		return nil
+	}
+	target.User = user
+	target.GuildID = i.GuildID
+
+	return &target
+}
@@ -2,6 +2,7 @@ package msg
 
 import (
 	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/logging"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -29,7 +30,7 @@ func SendResponse(s *discordgo.Session, i *discordgo.InteractionCreate, msg stri
 		})
 	}
 	if err != nil {
-		log.Error("Unable to send a response, error:", err)
+		logging.WithInteraction(log.StandardLogger(), i).Error("Unable to send a response, error:", err)
 	}
 }
 
@@ -46,7 +47,7 @@ func EditResponse(s *discordgo.Session, i *discordgo.InteractionCreate, msg stri
 	})
 
 	if err != nil {
-		log.Error("Unable to send a response, error:", err)
+		logging.WithInteraction(log.StandardLogger(), i).Error("Unable to edit a response, error:", err)
 	}
 }
 
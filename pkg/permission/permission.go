@@ -0,0 +1,117 @@
+package permission
+
+import (
+	"slices"
+	"sync"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/rbrabson/heist/pkg/config"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// AdminPermissions are the default permissions a member needs to use the admin commands.
+	AdminPermissions int64 = discordgo.PermissionManageServer
+
+	ownerIDs     map[string]bool
+	ownersLoaded bool
+	ownersMutex  sync.Mutex
+)
+
+// loadOwners loads the IDs of the bot owners. The owners are read from the bot's configuration. If
+// none are configured, the owner of the Discord application, or the members of the team that owns the
+// application, are used instead. If the application can't be retrieved, the owners are loaded again
+// the next time they are needed. The owners mutex must be held by the caller.
+func loadOwners(s *discordgo.Session) {
+	ownerIDs = make(map[string]bool)
+	if ids := config.Get().Bot.OwnerIDs; len(ids) != 0 {
+		for _, id := range ids {
+			ownerIDs[id] = true
+		}
+		ownersLoaded = true
+		return
+	}
+
+	app, err := s.Application("@me")
+	if err != nil {
+		log.Error("Unable to get the application to determine the bot owners, error:", err)
+		return
+	}
+	if app.Team != nil {
+		for _, member := range app.Team.Members {
+			ownerIDs[member.User.ID] = true
+		}
+	}
+	if app.Owner != nil {
+		ownerIDs[app.Owner.ID] = true
+	}
+	ownersLoaded = true
+}
+
+// IsOwner returns `true` if the user initiating the interaction is an owner of the bot.
+func IsOwner(s *discordgo.Session, i *discordgo.InteractionCreate) bool {
+	ownersMutex.Lock()
+	defer ownersMutex.Unlock()
+
+	if !ownersLoaded {
+		loadOwners(s)
+	}
+	return ownerIDs[GetUserID(i)]
+}
+
+// GetUserID returns the ID of the user initiating the interaction, whether it was sent from a
+// server or from a direct message.
+func GetUserID(i *discordgo.InteractionCreate) string {
+	if i.Member != nil && i.Member.User != nil {
+		return i.Member.User.ID
+	}
+	if i.User != nil {
+		return i.User.ID
+	}
+	return ""
+}
+
+// HasPermissions returns `true` if the member initiating the interaction has all the given permissions.
+// Administrators have every permission, and a `nil` set of permissions may be used by anyone.
+func HasPermissions(i *discordgo.InteractionCreate, permissions *int64) bool {
+	if permissions == nil {
+		return true
+	}
+	if i.Member == nil {
+		return false
+	}
+	if i.Member.Permissions&discordgo.PermissionAdministrator != 0 {
+		return true
+	}
+	return i.Member.Permissions&*permissions == *permissions
+}
+
+// MemberHasPermissions returns `true` if the member has all the given permissions on the server. Unlike
+// HasPermissions, the member's roles are looked up from Discord, so it may be used outside an interaction.
+func MemberHasPermissions(s *discordgo.Session, guildID string, memberID string, permissions int64) (bool, error) {
+	guild, err := s.State.Guild(guildID)
+	if err != nil {
+		guild, err = s.Guild(guildID)
+		if err != nil {
+			return false, err
+		}
+	}
+	if guild.OwnerID == memberID {
+		return true, nil
+	}
+	member, err := s.GuildMember(guildID, memberID)
+	if err != nil {
+		return false, err
+	}
+
+	var memberPermissions int64
+	for _, role := range guild.Roles {
+		if role.ID == guildID || slices.Contains(member.Roles, role.ID) {
+			memberPermissions |= role.Permissions
+		}
+	}
+	if memberPermissions&discordgo.PermissionAdministrator != 0 {
+		return true, nil
+	}
+	return memberPermissions&permissions == permissions, nil
+}
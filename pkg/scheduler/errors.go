@@ -0,0 +1,11 @@
+package scheduler
+
+import "errors"
+
+var (
+	ErrInvalidCron    = errors.New("invalid cron expression, which must have five fields: minute, hour, day of month, month and day of week")
+	ErrJobExists      = errors.New("a job with that name already exists")
+	ErrJobNotFound    = errors.New("job not found")
+	ErrNoHandler      = errors.New("no handler is registered for the job kind")
+	ErrInvalidPayload = errors.New("unable to encode the job payload")
+)
@@ -0,0 +1,186 @@
+package scheduler
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/rbrabson/heist/pkg/shard"
+	"github.com/rbrabson/heist/pkg/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	SCHEDULER = "scheduler"
+)
+
+var (
+	handlers   = make(map[string]func(ctx context.Context, guildID string, payload []byte) error)
+	guilds     = make(map[string]*guildJobs)
+	guildMutex sync.Mutex
+)
+
+// guildJobs are the one-time jobs that have been scheduled for a guild.
+type guildJobs struct {
+	ID   string     `json:"_id" bson:"_id"`
+	Jobs []*onceJob `json:"jobs" bson:"jobs"`
+}
+
+// onceJob is a job that is run a single time. These are saved in the store so they survive a restart of the bot.
+type onceJob struct {
+	ID      string    `json:"id" bson:"id"`
+	Kind    string    `json:"kind" bson:"kind"`
+	RunAt   time.Time `json:"run_at" bson:"run_at"`
+	Payload string    `json:"payload" bson:"payload"`
+}
+
+// Handle registers the handler that runs the one-time jobs of the given kind. Handlers must be
+// registered before the scheduler is started so that saved jobs can be resumed.
+func Handle(kind string, handler func(ctx context.Context, guildID string, payload []byte) error) {
+	guildMutex.Lock()
+	defer guildMutex.Unlock()
+
+	handlers[kind] = handler
+}
+
+// Once schedules a job of the given kind to run a single time for the guild. The payload is
+// encoded as JSON and passed to the handler for the kind when the job runs. The job is saved in the
+// store so it is run even if the bot is restarted. The ID of the job is returned.
+func Once(guildID string, kind string, runAt time.Time, payload interface{}) (string, error) {
+	log.Trace("--> scheduler.Once")
+	defer log.Trace("<-- scheduler.Once")
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return "", ErrInvalidPayload
+	}
+
+	guildMutex.Lock()
+	if _, ok := handlers[kind]; !ok {
+		guildMutex.Unlock()
+		return "", ErrNoHandler
+	}
+	oj := &onceJob{
+		ID:      strconv.FormatInt(time.Now().UnixNano(), 36),
+		Kind:    kind,
+		RunAt:   runAt,
+		Payload: string(data),
+	}
+	g := getGuildJobs(guildID)
+	g.Jobs = append(g.Jobs, oj)
+	store.Store.Save(SCHEDULER, g.ID, g)
+	guildMutex.Unlock()
+
+	err = addJob(newOnceJob(guildID, oj))
+	if err != nil {
+		return "", err
+	}
+
+	return oj.ID, nil
+}
+
+// Cancel cancels a one-time job that was scheduled for the guild.
+func Cancel(guildID string, id string) error {
+	log.Trace("--> scheduler.Cancel")
+	defer log.Trace("<-- scheduler.Cancel")
+
+	guildMutex.Lock()
+	oj := removeOnceJob(guildID, id)
+	guildMutex.Unlock()
+	if oj == nil {
+		return ErrJobNotFound
+	}
+
+	return Remove(onceJobName(guildID, oj))
+}
+
+// getGuildJobs returns the one-time jobs for the guild, creating them if necessary. The caller must
+// hold the guild mutex.
+func getGuildJobs(guildID string) *guildJobs {
+	g, ok := guilds[guildID]
+	if !ok {
+		g = &guildJobs{
+			ID:   guildID,
+			Jobs: make([]*onceJob, 0, 1),
+		}
+		guilds[guildID] = g
+	}
+	return g
+}
+
+// removeOnceJob removes the one-time job from the guild and saves the guild's jobs. The caller must hold
+// the guild mutex.
+func removeOnceJob(guildID string, id string) *onceJob {
+	g, ok := guilds[guildID]
+	if !ok {
+		return nil
+	}
+	for i, oj := range g.Jobs {
+		if oj.ID == id {
+			g.Jobs = append(g.Jobs[:i], g.Jobs[i+1:]...)
+			store.Store.Save(SCHEDULER, g.ID, g)
+			return oj
+		}
+	}
+	return nil
+}
+
+// onceJobName returns the name of the scheduled job used to run the one-time job.
+func onceJobName(guildID string, oj *onceJob) string {
+	return oj.Kind + "/" + guildID + "/" + oj.ID
+}
+
+// newOnceJob creates the scheduled job used to run a one-time job. Once run, the one-time job is
+// removed from the store.
+func newOnceJob(guildID string, oj *onceJob) *job {
+	return &job{
+		Job: Job{
+			Name:     onceJobName(guildID, oj),
+			Schedule: At(oj.RunAt),
+			Run: func(ctx context.Context) error {
+				guildMutex.Lock()
+				handler, ok := handlers[oj.Kind]
+				removeOnceJob(guildID, oj.ID)
+				guildMutex.Unlock()
+				if !ok {
+					return ErrNoHandler
+				}
+				return handler(ctx, guildID, []byte(oj.Payload))
+			},
+		},
+		guildID: guildID,
+	}
+}
+
+// loadOnceJobs loads the one-time jobs saved in the store and schedules them. Only the jobs for
+// guilds handled by the shards run by this process are loaded. Jobs whose time has already
+// passed are run right away.
+func loadOnceJobs() {
+	log.Trace("--> scheduler.loadOnceJobs")
+	defer log.Trace("<-- scheduler.loadOnceJobs")
+
+	for _, guildID := range store.Store.ListDocuments(SCHEDULER) {
+		if !shard.Owns(guildID) {
+			continue
+		}
+		var g guildJobs
+		store.Store.Load(SCHEDULER, guildID, &g)
+		g.ID = guildID
+
+		guildMutex.Lock()
+		guilds[g.ID] = &g
+		pending := make([]*onceJob, len(g.Jobs))
+		copy(pending, g.Jobs)
+		guildMutex.Unlock()
+
+		for _, oj := range pending {
+			err := addJob(newOnceJob(g.ID, oj))
+			if err != nil && err != ErrJobExists {
+				log.WithFields(log.Fields{"Guild": g.ID, "Job": oj.ID}).Error("Unable to schedule saved job, error:", err)
+			}
+		}
+		log.WithFields(log.Fields{"Guild": g.ID, "Jobs": len(pending)}).Debug("Loaded saved jobs")
+	}
+}
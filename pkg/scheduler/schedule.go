@@ -0,0 +1,206 @@
+package scheduler
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Schedule determines when a job runs next.
+type Schedule interface {
+	// Next returns the next time the job should run after the given time. A zero time means
+	// the job should not run again.
+	Next(t time.Time) time.Time
+	// String returns a readable description of the schedule.
+	String() string
+}
+
+// interval is a schedule that runs a job at a fixed interval.
+type interval struct {
+	every time.Duration
+}
+
+// Every returns a schedule that runs a job at a fixed interval.
+func Every(every time.Duration) Schedule {
+	return &interval{every: every}
+}
+
+// Next returns the time one interval after the given time.
+func (s *interval) Next(t time.Time) time.Time {
+	return t.Add(s.every)
+}
+
+// String returns a readable description of the interval.
+func (s *interval) String() string {
+	return "every " + s.every.String()
+}
+
+// once is a schedule that runs a job a single time.
+type once struct {
+	at time.Time
+}
+
+// At returns a schedule that runs a job once, at the given time. If the time has already passed
+// the job is run right away.
+func At(at time.Time) Schedule {
+	return &once{at: at}
+}
+
+// Next returns the time the job should be run.
+func (s *once) Next(t time.Time) time.Time {
+	return s.at
+}
+
+// String returns a readable description of when the job is run.
+func (s *once) String() string {
+	return "once at " + s.at.UTC().Format(time.RFC3339)
+}
+
+// cron is a schedule that runs a job at times that match a cron expression. The times are in UTC.
+type cron struct {
+	spec   string
+	minute []bool
+	hour   []bool
+	dom    []bool
+	month  []bool
+	dow    []bool
+	anyDom bool
+	anyDow bool
+}
+
+// Cron returns a schedule for a standard five-field cron expression, which is made up of
+// the minute, hour, day of month, month and day of week. Each field may be `*`, a number,
+// a range such as `1-5`, a list such as `1,15`, and may include a step such as `*/15`.
+// The times are in UTC.
+func Cron(spec string) (Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, ErrInvalidCron
+	}
+
+	var err error
+	s := &cron{spec: spec}
+	if s.minute, err = parseField(fields[0], 0, 59); err != nil {
+		return nil, err
+	}
+	if s.hour, err = parseField(fields[1], 0, 23); err != nil {
+		return nil, err
+	}
+	if s.dom, err = parseField(fields[2], 1, 31); err != nil {
+		return nil, err
+	}
+	if s.month, err = parseField(fields[3], 1, 12); err != nil {
+		return nil, err
+	}
+	if s.dow, err = parseField(fields[4], 0, 7); err != nil {
+		return nil, err
+	}
+	// Sunday may be either 0 or 7
+	s.dow[0] = s.dow[0] || s.dow[7]
+	s.anyDom = fields[2] == "*"
+	s.anyDow = fields[4] == "*"
+
+	return s, nil
+}
+
+// MustCron returns a schedule for the cron expression, and panics if the expression is invalid.
+func MustCron(spec string) Schedule {
+	s, err := Cron(spec)
+	if err != nil {
+		panic(err)
+	}
+	return s
+}
+
+// parseField parses a single field of a cron expression.
+func parseField(field string, min int, max int) ([]bool, error) {
+	values := make([]bool, max+1)
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepStr)
+			if err != nil || step <= 0 {
+				return nil, ErrInvalidCron
+			}
+		}
+
+		start, end := min, max
+		if rng != "*" {
+			first, last, isRange := strings.Cut(rng, "-")
+			var err error
+			start, err = strconv.Atoi(first)
+			if err != nil {
+				return nil, ErrInvalidCron
+			}
+			end = start
+			if isRange {
+				end, err = strconv.Atoi(last)
+				if err != nil {
+					return nil, ErrInvalidCron
+				}
+			} else if hasStep {
+				end = max
+			}
+		}
+		if start < min || end > max || start > end {
+			return nil, ErrInvalidCron
+		}
+
+		for v := start; v <= end; v += step {
+			values[v] = true
+		}
+	}
+	return values, nil
+}
+
+// Next returns the first time after the given time that matches the cron expression, or the zero
+// time if no such time exists within the next five years.
+func (s *cron) Next(t time.Time) time.Time {
+	t = t.UTC().Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if !s.month[int(t.Month())] {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.matchDay(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.hour[t.Hour()] {
+			t = t.Truncate(time.Hour).Add(time.Hour)
+			continue
+		}
+		if !s.minute[t.Minute()] {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// matchDay returns `true` if the day matches the cron expression. As with standard cron, if both the
+// day of month and day of week are restricted, a day matching either one is a match.
+func (s *cron) matchDay(t time.Time) bool {
+	dom := s.dom[t.Day()]
+	dow := s.dow[int(t.Weekday())]
+	switch {
+	case s.anyDom && s.anyDow:
+		return true
+	case s.anyDom:
+		return dow
+	case s.anyDow:
+		return dom
+	default:
+		return dom || dow
+	}
+}
+
+// String returns the cron expression.
+func (s *cron) String() string {
+	return "cron `" + s.spec + "`"
+}
@@ -0,0 +1,261 @@
+package scheduler
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int, hour int, minute int) time.Time {
+	return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+}
+
+func TestEvery(t *testing.T) {
+	tests := []struct {
+		name  string
+		every time.Duration
+		from  time.Time
+		want  time.Time
+		str   string
+	}{
+		{"seconds", 15 * time.Second, date(2024, 1, 1, 10, 0), date(2024, 1, 1, 10, 0).Add(15 * time.Second), "every 15s"},
+		{"hours", 2 * time.Hour, date(2024, 1, 1, 23, 0), date(2024, 1, 2, 1, 0), "every 2h0m0s"},
+		{"day", 24 * time.Hour, date(2024, 2, 28, 12, 0), date(2024, 2, 29, 12, 0), "every 24h0m0s"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := Every(tc.every)
+			if got := s.Next(tc.from); !got.Equal(tc.want) {
+				t.Errorf("Next(%v) = %v, want %v", tc.from, got, tc.want)
+			}
+			if got := s.String(); got != tc.str {
+				t.Errorf("String() = %q, want %q", got, tc.str)
+			}
+		})
+	}
+}
+
+func TestAt(t *testing.T) {
+	at := date(2024, 6, 1, 12, 30)
+	tests := []struct {
+		name string
+		from time.Time
+	}{
+		{"before", date(2024, 5, 31, 0, 0)},
+		{"same time", at},
+		{"after", date(2024, 6, 2, 0, 0)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := At(at)
+			if got := s.Next(tc.from); !got.Equal(at) {
+				t.Errorf("Next(%v) = %v, want %v", tc.from, got, at)
+			}
+			if got, want := s.String(), "once at 2024-06-01T12:30:00Z"; got != want {
+				t.Errorf("String() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestParseField(t *testing.T) {
+	tests := []struct {
+		name    string
+		field   string
+		min     int
+		max     int
+		want    []int
+		wantErr bool
+	}{
+		{"any", "*", 0, 5, []int{0, 1, 2, 3, 4, 5}, false},
+		{"single", "3", 0, 5, []int{3}, false},
+		{"range", "1-3", 0, 5, []int{1, 2, 3}, false},
+		{"list", "1,4", 0, 5, []int{1, 4}, false},
+		{"step", "*/15", 0, 59, []int{0, 15, 30, 45}, false},
+		{"range with step", "10-20/5", 0, 59, []int{10, 15, 20}, false},
+		{"start with step", "50/5", 0, 59, []int{50, 55}, false},
+		{"list of ranges", "1-2,5-6", 1, 12, []int{1, 2, 5, 6}, false},
+		{"lowest", "1", 1, 31, []int{1}, false},
+		{"highest", "31", 1, 31, []int{31}, false},
+		{"below minimum", "0", 1, 31, nil, true},
+		{"above maximum", "60", 0, 59, nil, true},
+		{"reversed range", "5-1", 0, 59, nil, true},
+		{"zero step", "*/0", 0, 59, nil, true},
+		{"negative step", "*/-1", 0, 59, nil, true},
+		{"bad step", "*/x", 0, 59, nil, true},
+		{"bad value", "x", 0, 59, nil, true},
+		{"bad range end", "1-x", 0, 59, nil, true},
+		{"empty", "", 0, 59, nil, true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			values, err := parseField(tc.field, tc.min, tc.max)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidCron) {
+					t.Fatalf("parseField(%q) error = %v, want %v", tc.field, err, ErrInvalidCron)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseField(%q) returned error %v", tc.field, err)
+			}
+			var got []int
+			for v, ok := range values {
+				if ok {
+					got = append(got, v)
+				}
+			}
+			if !slices.Equal(got, tc.want) {
+				t.Errorf("parseField(%q) = %v, want %v", tc.field, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+	}{
+		{"every minute", "* * * * *", false},
+		{"monthly", "0 0 1 * *", false},
+		{"weekdays", "30 9 * * 1-5", false},
+		{"sunday as seven", "0 0 * * 7", false},
+		{"extra spaces", " 0  0 * *  * ", false},
+		{"too few fields", "0 0 * *", true},
+		{"too many fields", "0 0 * * * *", true},
+		{"empty", "", true},
+		{"bad minute", "60 * * * *", true},
+		{"bad hour", "0 24 * * *", true},
+		{"bad day of month", "0 0 0 * *", true},
+		{"bad month", "0 0 * 13 *", true},
+		{"bad day of week", "0 0 * * 8", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s, err := Cron(tc.spec)
+			if tc.wantErr {
+				if !errors.Is(err, ErrInvalidCron) {
+					t.Fatalf("Cron(%q) error = %v, want %v", tc.spec, err, ErrInvalidCron)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Cron(%q) returned error %v", tc.spec, err)
+			}
+			if got, want := s.String(), "cron `"+tc.spec+"`"; got != want {
+				t.Errorf("String() = %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestMustCron(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      string
+		wantPanic bool
+	}{
+		{"valid", "0 0 1 * *", false},
+		{"invalid", "0 0 1 *", true},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			defer func() {
+				if r := recover(); (r != nil) != tc.wantPanic {
+					t.Errorf("MustCron(%q) panic = %v, want panic %v", tc.spec, r, tc.wantPanic)
+				}
+			}()
+			if s := MustCron(tc.spec); s == nil {
+				t.Errorf("MustCron(%q) returned nil", tc.spec)
+			}
+		})
+	}
+}
+
+func TestCronNext(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		from time.Time
+		want time.Time
+	}{
+		{"every minute", "* * * * *", date(2024, 1, 1, 10, 7), date(2024, 1, 1, 10, 8)},
+		{"drops seconds", "* * * * *", date(2024, 1, 1, 10, 7).Add(30 * time.Second), date(2024, 1, 1, 10, 8)},
+		{"every quarter hour", "*/15 * * * *", date(2024, 1, 1, 10, 7), date(2024, 1, 1, 10, 15)},
+		{"strictly after a match", "*/15 * * * *", date(2024, 1, 1, 10, 15), date(2024, 1, 1, 10, 30)},
+		{"next hour", "0 * * * *", date(2024, 1, 1, 10, 1), date(2024, 1, 1, 11, 0)},
+		{"next day", "0 0 * * *", date(2024, 1, 1, 23, 59), date(2024, 1, 2, 0, 0)},
+		{"next month", "0 0 1 * *", date(2024, 1, 15, 0, 0), date(2024, 2, 1, 0, 0)},
+		{"next year", "0 0 1 1 *", date(2024, 1, 1, 0, 0), date(2025, 1, 1, 0, 0)},
+		{"day of week", "30 9 * * 1", date(2024, 1, 3, 0, 0), date(2024, 1, 8, 9, 30)},
+		{"sunday as seven", "0 12 * * 7", date(2024, 1, 1, 0, 0), date(2024, 1, 7, 12, 0)},
+		{"sunday as zero", "0 12 * * 0", date(2024, 1, 1, 0, 0), date(2024, 1, 7, 12, 0)},
+		{"leap day", "0 0 29 2 *", date(2023, 3, 1, 0, 0), date(2024, 2, 29, 0, 0)},
+		{"day missing from month", "0 0 31 * *", date(2024, 4, 1, 0, 0), date(2024, 5, 31, 0, 0)},
+		{"never", "0 0 31 2 *", date(2024, 1, 1, 0, 0), time.Time{}},
+		{"local time is converted to UTC", "0 0 * * *", time.Date(2024, 1, 1, 20, 0, 0, 0, time.FixedZone("EST", -5*60*60)), date(2024, 1, 3, 0, 0)},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			got := MustCron(tc.spec).Next(tc.from)
+			if !got.Equal(tc.want) {
+				t.Errorf("Next(%v) = %v, want %v", tc.from, got, tc.want)
+			}
+		})
+	}
+}
+
+func TestCronMatchDay(t *testing.T) {
+	tests := []struct {
+		name string
+		spec string
+		day  time.Time
+		want bool
+	}{
+		{"any day", "0 0 * * *", date(2024, 9, 12, 0, 0), true},
+		{"day of month only", "0 0 13 * *", date(2024, 9, 13, 0, 0), true},
+		{"other day of month", "0 0 13 * *", date(2024, 9, 12, 0, 0), false},
+		{"day of week only", "0 0 * * 5", date(2024, 9, 6, 0, 0), true},
+		{"other day of week", "0 0 * * 5", date(2024, 9, 12, 0, 0), false},
+		{"both, matching both", "0 0 13 * 5", date(2024, 9, 13, 0, 0), true},
+		{"both, matching day of week", "0 0 13 * 5", date(2024, 9, 6, 0, 0), true},
+		{"both, matching day of month", "0 0 13 * 5", date(2024, 10, 13, 0, 0), true},
+		{"both, matching neither", "0 0 13 * 5", date(2024, 9, 12, 0, 0), false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			s := MustCron(tc.spec).(*cron)
+			if got := s.matchDay(tc.day); got != tc.want {
+				t.Errorf("matchDay(%v) = %v, want %v", tc.day.Format("Mon 2006-01-02"), got, tc.want)
+			}
+		})
+	}
+}
+
+func TestAddJitter(t *testing.T) {
+	start := date(2024, 1, 1, 0, 0)
+	tests := []struct {
+		name   string
+		jitter time.Duration
+	}{
+		{"none", 0},
+		{"negative", -time.Minute},
+		{"nanosecond", time.Nanosecond},
+		{"minute", time.Minute},
+		{"hour", time.Hour},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			latest := start.Add(max(tc.jitter-1, 0))
+			for range 100 {
+				got := addJitter(start, tc.jitter)
+				if got.Before(start) || got.After(latest) {
+					t.Fatalf("addJitter(%v, %v) = %v, want between %v and %v", start, tc.jitter, got, start, latest)
+				}
+			}
+		})
+	}
+}
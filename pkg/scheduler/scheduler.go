@@ -0,0 +1,273 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	stopTimeout = 30 * time.Second
+)
+
+var (
+	jobs    = make(map[string]*job)
+	ctx     context.Context
+	cancel  context.CancelFunc
+	started bool
+	wg      sync.WaitGroup
+	mutex   sync.Mutex
+)
+
+// Job is work that is run by the scheduler.
+type Job struct {
+	Name       string                          // Unique name of the job
+	Schedule   Schedule                        // When the job is run
+	Jitter     time.Duration                   // Maximum random delay added to each run, to spread out the load
+	RunOnStart bool                            // Run the job as soon as the scheduler starts, in addition to the schedule
+	Run        func(ctx context.Context) error // Work done by the job
+}
+
+// job is a job being run by the scheduler, along with the bookkeeping for the job.
+type job struct {
+	Job
+	guildID      string
+	cancel       context.CancelFunc
+	nextRun      time.Time
+	lastRun      time.Time
+	lastDuration time.Duration
+	lastErr      error
+	runs         int
+	running      bool
+}
+
+// JobInfo is information about a scheduled job.
+type JobInfo struct {
+	Name         string
+	Schedule     string
+	GuildID      string
+	NextRun      time.Time
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string
+	Runs         int
+	Running      bool
+}
+
+// Add adds a job to the scheduler. If the scheduler has already been started, the job starts right away.
+func Add(j Job) error {
+	log.Trace("--> scheduler.Add")
+	defer log.Trace("<-- scheduler.Add")
+
+	return addJob(&job{Job: j})
+}
+
+// addJob adds a job to the scheduler.
+func addJob(j *job) error {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	if _, ok := jobs[j.Name]; ok {
+		return ErrJobExists
+	}
+	jobs[j.Name] = j
+	if started {
+		startJob(j)
+	}
+	log.WithFields(log.Fields{"Job": j.Name, "Schedule": j.Schedule.String()}).Debug("Added job")
+
+	return nil
+}
+
+// Remove stops a job and removes it from the scheduler. A run of the job that is in progress is cancelled.
+func Remove(name string) error {
+	log.Trace("--> scheduler.Remove")
+	defer log.Trace("<-- scheduler.Remove")
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	j, ok := jobs[name]
+	if !ok {
+		return ErrJobNotFound
+	}
+	delete(jobs, name)
+	if j.cancel != nil {
+		j.cancel()
+	}
+	log.WithField("Job", name).Debug("Removed job")
+
+	return nil
+}
+
+// Start starts running the scheduled jobs, and loads the one-time jobs saved in the store.
+func Start() {
+	log.Trace("--> scheduler.Start")
+	defer log.Trace("<-- scheduler.Start")
+
+	mutex.Lock()
+	if started {
+		mutex.Unlock()
+		return
+	}
+	ctx, cancel = context.WithCancel(context.Background())
+	started = true
+	for _, j := range jobs {
+		startJob(j)
+	}
+	mutex.Unlock()
+
+	loadOnceJobs()
+	log.Info("Scheduler started")
+}
+
+// Stop cancels all jobs and waits for any that are running to finish.
+func Stop() {
+	log.Trace("--> scheduler.Stop")
+	defer log.Trace("<-- scheduler.Stop")
+
+	mutex.Lock()
+	if !started {
+		mutex.Unlock()
+		return
+	}
+	cancel()
+	started = false
+	mutex.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		log.Info("Scheduler stopped")
+	case <-time.After(stopTimeout):
+		log.Warning("Timed out waiting for scheduled jobs to finish")
+	}
+}
+
+// GetJobs returns information about all scheduled jobs, sorted by name.
+func GetJobs() []*JobInfo {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	infos := make([]*JobInfo, 0, len(jobs))
+	for _, j := range jobs {
+		info := &JobInfo{
+			Name:         j.Name,
+			Schedule:     j.Schedule.String(),
+			GuildID:      j.guildID,
+			NextRun:      j.nextRun,
+			LastRun:      j.lastRun,
+			LastDuration: j.lastDuration,
+			Runs:         j.runs,
+			Running:      j.running,
+		}
+		if j.lastErr != nil {
+			info.LastError = j.lastErr.Error()
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Name < infos[j].Name
+	})
+
+	return infos
+}
+
+// startJob starts the goroutine that runs the job. The caller must hold the scheduler mutex.
+func startJob(j *job) {
+	var jobCtx context.Context
+	jobCtx, j.cancel = context.WithCancel(ctx)
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		j.loop(jobCtx)
+	}()
+}
+
+// loop runs the job each time it is scheduled, until the job is cancelled.
+func (j *job) loop(ctx context.Context) {
+	if j.RunOnStart {
+		j.execute(ctx)
+	}
+
+	_, isOnce := j.Schedule.(*once)
+	for {
+		next := j.Schedule.Next(time.Now())
+		if next.IsZero() {
+			log.WithField("Job", j.Name).Debug("Job has no more scheduled runs")
+			Remove(j.Name)
+			return
+		}
+		next = addJitter(next, j.Jitter)
+		mutex.Lock()
+		j.nextRun = next
+		mutex.Unlock()
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		j.execute(ctx)
+		if isOnce {
+			Remove(j.Name)
+			return
+		}
+	}
+}
+
+// addJitter returns the time delayed by a random amount less than the jitter, so jobs that are scheduled
+// at the same time don't all run at once.
+func addJitter(t time.Time, jitter time.Duration) time.Time {
+	if jitter <= 0 {
+		return t
+	}
+	return t.Add(time.Duration(rand.Int63n(int64(jitter))))
+}
+
+// execute runs the job a single time and records the result.
+func (j *job) execute(ctx context.Context) {
+	mutex.Lock()
+	j.running = true
+	mutex.Unlock()
+
+	start := time.Now()
+	err := j.safeRun(ctx)
+	duration := time.Since(start)
+
+	mutex.Lock()
+	j.running = false
+	j.lastRun = start
+	j.lastDuration = duration
+	j.lastErr = err
+	j.runs++
+	mutex.Unlock()
+
+	if err != nil {
+		log.WithFields(log.Fields{"Job": j.Name, "Duration": duration}).Error("Scheduled job failed, error:", err)
+		return
+	}
+	log.WithFields(log.Fields{"Job": j.Name, "Duration": duration}).Debug("Ran scheduled job")
+}
+
+// safeRun runs the job, converting a panic into an error so a failing job doesn't stop the bot.
+func (j *job) safeRun(ctx context.Context) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return j.Run(ctx)
+}
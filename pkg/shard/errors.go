@@ -0,0 +1,9 @@
+package shard
+
+import "errors"
+
+var (
+	ErrInvalidShardCount = errors.New("the shard count must be a positive number or `auto`")
+	ErrInvalidShardID    = errors.New("the shard IDs must be less than the shard count")
+	ErrDuplicateShardID  = errors.New("the shard IDs must not repeat a shard")
+)
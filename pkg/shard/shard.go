@@ -0,0 +1,219 @@
+package shard
+
+import (
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	identifyInterval = 5 * time.Second // Time Discord requires between identifies for the same rate limit bucket
+)
+
+var (
+	sessions       map[int]*discordgo.Session
+	count          = 1
+	maxConcurrency = 1
+	mutex          sync.RWMutex
+)
+
+// NewSessions creates one Discord session for each shard run by this process. The total number of
+// shards may be a number or `auto` to use the number of shards recommended by Discord. If no shard
+// IDs are given, then all shards are run by this process.
+func NewSessions(token string, totalShards string, ids []int) ([]*discordgo.Session, error) {
+	log.Trace("--> NewSessions")
+	defer log.Trace("<-- NewSessions")
+
+	shardCount, err := getShardCount(token, totalShards)
+	if err != nil {
+		return nil, err
+	}
+	shardIDs, err := getShardIDs(shardCount, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	concurrency := 1
+	if len(shardIDs) > 1 {
+		concurrency = getMaxConcurrency(token)
+	}
+
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	count = shardCount
+	maxConcurrency = concurrency
+	sessions = make(map[int]*discordgo.Session, len(shardIDs))
+	list := make([]*discordgo.Session, 0, len(shardIDs))
+	for _, shardID := range shardIDs {
+		s, err := discordgo.New("Bot " + token)
+		if err != nil {
+			return nil, err
+		}
+		s.ShardID = shardID
+		s.ShardCount = shardCount
+		sessions[shardID] = s
+		list = append(list, s)
+		log.WithFields(log.Fields{"ShardID": shardID, "ShardCount": shardCount}).Debug("Created shard session")
+	}
+
+	return list, nil
+}
+
+// Open opens the gateway connection for each session. Discord only lets `max_concurrency` shards identify
+// every five seconds, so the shards are opened in groups of that size, waiting between each group.
+func Open(list []*discordgo.Session) error {
+	log.Trace("--> shard.Open")
+	defer log.Trace("<-- shard.Open")
+
+	mutex.RLock()
+	concurrency := maxConcurrency
+	mutex.RUnlock()
+
+	lastGroup := -1
+	for _, s := range list {
+		group := s.ShardID / concurrency
+		if lastGroup != -1 && group != lastGroup {
+			time.Sleep(identifyInterval)
+		}
+		lastGroup = group
+
+		err := s.Open()
+		if err != nil {
+			return err
+		}
+		log.WithFields(log.Fields{"ShardID": s.ShardID, "ShardCount": s.ShardCount}).Debug("Opened shard")
+	}
+	return nil
+}
+
+// getMaxConcurrency returns the number of shards that Discord lets identify at the same time. If it can't
+// be retrieved, the shards identify one at a time.
+func getMaxConcurrency(token string) int {
+	s, err := discordgo.New("Bot " + token)
+	if err != nil {
+		log.Warning("Unable to get the maximum concurrency for the shards, error:", err)
+		return 1
+	}
+	gateway, err := s.GatewayBot()
+	if err != nil {
+		log.Warning("Unable to get the maximum concurrency for the shards, error:", err)
+		return 1
+	}
+	return max(gateway.SessionStartLimit.MaxConcurrency, 1)
+}
+
+// getShardCount returns the total number of shards used by the bot.
+func getShardCount(token string, totalShards string) (int, error) {
+	value := strings.TrimSpace(totalShards)
+	if value == "" {
+		return 1, nil
+	}
+	if strings.EqualFold(value, "auto") {
+		s, err := discordgo.New("Bot " + token)
+		if err != nil {
+			return 0, err
+		}
+		gateway, err := s.GatewayBot()
+		if err != nil {
+			return 0, err
+		}
+		log.WithField("Shards", gateway.Shards).Info("Using the recommended number of shards")
+		return max(gateway.Shards, 1), nil
+	}
+	shardCount, err := strconv.Atoi(value)
+	if err != nil || shardCount < 1 {
+		return 0, ErrInvalidShardCount
+	}
+	return shardCount, nil
+}
+
+// getShardIDs returns the list of shards run by this process.
+func getShardIDs(shardCount int, ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		shardIDs := make([]int, 0, shardCount)
+		for shardID := 0; shardID < shardCount; shardID++ {
+			shardIDs = append(shardIDs, shardID)
+		}
+		return shardIDs, nil
+	}
+
+	shardIDs := make([]int, 0, len(ids))
+	for _, shardID := range ids {
+		if shardID < 0 || shardID >= shardCount {
+			return nil, ErrInvalidShardID
+		}
+		if slices.Contains(shardIDs, shardID) {
+			return nil, ErrDuplicateShardID
+		}
+		shardIDs = append(shardIDs, shardID)
+	}
+	sort.Ints(shardIDs)
+	return shardIDs, nil
+}
+
+// ForGuild returns the ID of the shard that receives the events for the guild.
+func ForGuild(guildID string) int {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	id, err := strconv.ParseUint(guildID, 10, 64)
+	if err != nil {
+		return 0
+	}
+	return int((id >> 22) % uint64(count))
+}
+
+// Owns returns `true` if the guild is handled by one of the shards run by this process.
+func Owns(guildID string) bool {
+	shardID := ForGuild(guildID)
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	if sessions == nil {
+		return true
+	}
+	_, ok := sessions[shardID]
+	return ok
+}
+
+// Session returns the session for the shard that handles the guild. If that shard is not run by this
+// process, then `nil` is returned.
+func Session(guildID string) *discordgo.Session {
+	shardID := ForGuild(guildID)
+
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	return sessions[shardID]
+}
+
+// Sessions returns the sessions for all shards run by this process.
+func Sessions() []*discordgo.Session {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	list := make([]*discordgo.Session, 0, len(sessions))
+	for _, s := range sessions {
+		list = append(list, s)
+	}
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].ShardID < list[j].ShardID
+	})
+	return list
+}
+
+// Count returns the total number of shards used by the bot.
+func Count() int {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	return count
+}
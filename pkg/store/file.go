@@ -5,8 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
-
-	log "github.com/sirupsen/logrus"
+	"time"
 )
 
 // fileStore is a Store used to load and save a document to a file.
@@ -14,9 +13,8 @@ type fileStore struct {
 	dir string
 }
 
-// newFileStore creates a new file Store.
-func newFileStore() StoreInterface {
-	dir := os.Getenv("HEIST_FILE_STORE_DIR")
+// newFileStore creates a new file Store that saves documents in the given directory.
+func newFileStore(dir string) StoreInterface {
 	f := &fileStore{
 		dir: dir,
 	}
@@ -68,9 +66,55 @@ func (f *fileStore) Save(collection string, documentID string, data interface{})
 		return
 	}
 
+	err = os.MkdirAll(f.dir+collection, 0755)
+	if err != nil {
+		log.Errorf("Unable to create the directory for collection %s, error=%s", collection, err.Error())
+		return
+	}
 	filename := f.dir + collection + "/" + documentID + ".json"
 	err = os.WriteFile(filename, b, 0644)
 	if err != nil {
 		log.Errorf("Unable to save the document, collection=%s, document=%s, error=%s", collection, documentID, err.Error())
 	}
 }
+
+// Type returns the type of the store.
+func (f *fileStore) Type() string {
+	return "file"
+}
+
+// Ping verifies the directory used by the store can be accessed.
+func (f *fileStore) Ping() error {
+	info, err := os.Stat(f.dir)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", f.dir)
+	}
+	return nil
+}
+
+// LastModified returns the time a document in the subdirectory (collection) was last added, changed or removed.
+func (f *fileStore) LastModified(collection string) (time.Time, error) {
+	dirName := f.dir + "/" + collection
+	info, err := os.Stat(dirName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	lastModified := info.ModTime()
+	files, err := os.ReadDir(dirName)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for _, file := range files {
+		info, err := file.Info()
+		if err != nil {
+			continue
+		}
+		if info.ModTime().After(lastModified) {
+			lastModified = info.ModTime()
+		}
+	}
+	return lastModified, nil
+}
@@ -0,0 +1,6 @@
+package store
+
+import "github.com/rbrabson/heist/pkg/logging"
+
+// log is the logger used by the store.
+var log = logging.Logger("store")
@@ -2,15 +2,12 @@ package store
 
 import (
 	"context"
-	"os"
+	"fmt"
 	"time"
 
-	"github.com/joho/godotenv"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
-
-	log "github.com/sirupsen/logrus"
 )
 
 // mongodb is a Store used to load and save documents in a MongoDB database.
@@ -18,7 +15,8 @@ type mongodb struct {
 	//adminDB string
 	//dbName  string
 	//pwd     string
-	uri string
+	uri      string
+	database string
 	//userID  string
 }
 
@@ -27,13 +25,7 @@ var client *mongo.Client = nil
 var err error = nil
 
 // newMongoStore creates a Store to load and save documents in a MongoDB database.
-func newMongoStore() StoreInterface {
-	godotenv.Load()
-
-	uri := os.Getenv("MONGODB_URI")
-	if uri == "" {
-		log.Fatal("You must set your 'MONGODB_URI' environmental variable. See\n\t https://www.mongodb.com/docs/drivers/go/current/usage-examples/#environment-variable")
-	}
+func newMongoStore(uri string, database string) (StoreInterface, error) {
 	//dbName := os.Getenv("MONGODB_DATABASE")
 	//if dbName == "" {
 	//	log.Fatal("You must set your 'MONGODB_DATABASE' environment variable")
@@ -55,7 +47,8 @@ func newMongoStore() StoreInterface {
 		//	adminDB: adminDB,
 		//	dbName:  dbName,
 		//	pwd:     pwd,
-		uri: uri,
+		uri:      uri,
+		database: database,
 		//	userID:  userID,
 	}
 
@@ -74,20 +67,21 @@ func newMongoStore() StoreInterface {
 	}
 
 	if err != nil {
-		log.Fatal("Unable to connect to the MongoDB database, error:", err)
+		connectErr := err
 		err = nil
-		return nil
+		return nil, fmt.Errorf("unable to connect to the MongoDB database: %w", connectErr)
 	}
 	//defer client.Disconnect(ctx)
 	// Check the connection
 	err = client.Ping(ctx, nil)
 
 	if err != nil {
-		log.Fatal("Unable to ping the MongoDB database, error:", err)
+		pingErr := err
 		err = nil
+		return nil, fmt.Errorf("unable to ping the MongoDB database: %w", pingErr)
 	}
 
-	return &m
+	return &m, nil
 }
 
 // ListDocuments returns the ID of each document in a collection in the collection.
@@ -114,7 +108,7 @@ func (m *mongodb) ListDocuments(collectionName string) []string {
 	}
 	//defer client.Disconnect(ctx)
 
-	db := client.Database("Heist")
+	db := client.Database(m.database)
 	collection := db.Collection(collectionName)
 	if collection == nil {
 		log.Errorf("Failed to create %s collection, error=%s", collectionName, err.Error())
@@ -170,7 +164,7 @@ func (m *mongodb) Load(collectionName string, documentID string, data interface{
 	}
 	//defer client.Disconnect(ctx)
 
-	db := client.Database("Heist")
+	db := client.Database(m.database)
 	collection := db.Collection(collectionName)
 	if collection == nil {
 		log.Errorf("Failed to create %s collection, error=%s", collectionName, err.Error())
@@ -217,7 +211,7 @@ func (m *mongodb) Save(collectionName string, documentID string, data interface{
 	//Set the limit of the number of record to find
 	findOptions.SetLimit(5)
 
-	db := client.Database("Heist")
+	db := client.Database(m.database)
 	collection := db.Collection(collectionName)
 	if collection == nil {
 		if err = db.CreateCollection(ctx, collectionName); err != nil {
@@ -237,3 +231,19 @@ func (m *mongodb) Save(collectionName string, documentID string, data interface{
 		}
 	}
 }
+
+// Type returns the type of the store.
+func (m *mongodb) Type() string {
+	return "mongodb"
+}
+
+// Ping verifies the MongoDB database can be reached.
+func (m *mongodb) Ping() error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if client == nil {
+		return mongo.ErrClientDisconnected
+	}
+	return client.Ping(ctx, nil)
+}
@@ -1,10 +1,9 @@
 package store
 
 import (
-	"os"
+	"time"
 
-	"github.com/joho/godotenv"
-	log "github.com/sirupsen/logrus"
+	"github.com/rbrabson/heist/pkg/config"
 )
 
 var (
@@ -12,10 +11,14 @@ var (
 	Store StoreInterface
 )
 
-// Initializes the store used by all bots
-func init() {
-	godotenv.Load()
-	Store = newStore()
+// Init initializes the store used by all bots.
+func Init(cfg config.Store) error {
+	store, err := newStore(cfg)
+	if err != nil {
+		return err
+	}
+	Store = store
+	return nil
 }
 
 // StoreInterface defines the methods required to load and save the heist state.
@@ -23,17 +26,20 @@ type StoreInterface interface {
 	ListDocuments(collection string) []string
 	Load(collection string, docuentID string, data interface{})
 	Save(collection string, documentID string, data interface{})
+	Type() string
+	Ping() error
+}
+
+// Watcher is implemented by stores that can report when the documents in a collection were last changed.
+type Watcher interface {
+	LastModified(collection string) (time.Time, error)
 }
 
 // newStore creates a new store to be used to load and save the heist state.
-func newStore() StoreInterface {
-	storeType := os.Getenv("HEIST_STORE")
-	log.Debug("Storage type:", storeType)
-	var store StoreInterface
-	if storeType == "file" {
-		store = newFileStore()
-	} else {
-		store = newMongoStore()
+func newStore(cfg config.Store) (StoreInterface, error) {
+	log.Debug("Storage type:", cfg.Type)
+	if cfg.Type == config.FILE {
+		return newFileStore(cfg.Dir), nil
 	}
-	return store
+	return newMongoStore(cfg.MongoURI, cfg.Database)
 }